@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Acima deste desgaste a bateria entra na lista de troca
+const BATTERY_WEAR_REPLACE_PERCENT = 40.0
+
+// Win32_Battery.EstimatedRunTime usa este valor quando a máquina está na tomada
+const WIN32_BATTERY_RUNTIME_ON_AC = 71582788
+
+const (
+	BATTERY_STATE_CHARGING    = "charging"
+	BATTERY_STATE_DISCHARGING = "discharging"
+	BATTERY_STATE_FULL        = "full"
+	BATTERY_STATE_IDLE        = "idle"
+	BATTERY_STATE_UNKNOWN     = "unknown"
+)
+
+type BatteryInfo struct {
+	Name                    string   `json:"name"`
+	Manufacturer            string   `json:"manufacturer"`
+	SerialNumber            string   `json:"serial_number"`
+	Chemistry               string   `json:"chemistry"`
+	CapacityUnit            string   `json:"capacity_unit"`
+	DesignCapacity          int64    `json:"design_capacity"`
+	FullChargeCapacity      int64    `json:"full_charge_capacity"`
+	WearPercent             *float64 `json:"wear_percent"`
+	CycleCount              *int64   `json:"cycle_count"`
+	ChargePercent           float64  `json:"charge_percent"`
+	State                   string   `json:"state"`
+	EstimatedRuntimeMinutes *int64   `json:"estimated_runtime_minutes"`
+	NeedsReplacement        bool     `json:"needs_replacement"`
+}
+
+type BatteryReport struct {
+	OnACPower bool          `json:"on_ac_power"`
+	Batteries []BatteryInfo `json:"batteries"`
+}
+
+// --- Classes WMI ---
+
+type Win32_Battery struct {
+	DeviceID                 string
+	Name                     string
+	BatteryStatus            uint16
+	EstimatedChargeRemaining uint16
+	EstimatedRunTime         uint32
+}
+
+// As classes abaixo ficam em root\WMI e se ligam pelo InstanceName
+type BatteryStaticData struct {
+	InstanceName     string
+	DeviceName       string
+	ManufactureName  string
+	SerialNumber     string
+	DesignedCapacity uint32
+	Chemistry        uint32
+}
+
+type BatteryFullChargedCapacity struct {
+	InstanceName        string
+	FullChargedCapacity uint32
+}
+
+type BatteryCycleCount struct {
+	InstanceName string
+	CycleCount   uint32
+}
+
+type BatteryStatus struct {
+	InstanceName string
+	PowerOnline  bool
+	Charging     bool
+	Discharging  bool
+}
+
+// Retorna nil em máquinas sem bateria (desktops)
+func collectBattery(ctx context.Context) (interface{}, error) {
+	var report *BatteryReport
+	var err error
+	if runtime.GOOS == "windows" {
+		report, err = windowsBattery()
+	} else {
+		report = linuxBattery()
+	}
+	if report == nil { return (*BatteryReport)(nil), err }
+
+	for i := range report.Batteries {
+		b := &report.Batteries[i]
+		if b.DesignCapacity > 0 && b.FullChargeCapacity > 0 {
+			wear := math.Round((1-float64(b.FullChargeCapacity)/float64(b.DesignCapacity))*1000) / 10
+			wear = math.Max(wear, 0)
+			b.WearPercent = &wear
+			b.NeedsReplacement = wear >= BATTERY_WEAR_REPLACE_PERCENT
+		}
+	}
+	return report, err
+}
+
+// Falha no Win32_Battery é erro (não dá para dizer que não há bateria); falhas nas classes de
+// root\WMI só deixam os detalhes em branco e voltam como falha parcial
+func windowsBattery() (*BatteryReport, error) {
+	var batteries []Win32_Battery
+	if err := queryWMI(&batteries, ""); err != nil { return nil, fmt.Errorf("Win32_Battery: %w", err) }
+	if len(batteries) == 0 { return nil, nil }
+
+	var errs []error
+	var static []BatteryStaticData
+	if err := queryWMINamespace(&static, "", `root\WMI`); err != nil { errs = append(errs, fmt.Errorf("BatteryStaticData: %w", err)) }
+	var full []BatteryFullChargedCapacity
+	if err := queryWMINamespace(&full, "", `root\WMI`); err != nil { errs = append(errs, fmt.Errorf("BatteryFullChargedCapacity: %w", err)) }
+	var cycles []BatteryCycleCount
+	if err := queryWMINamespace(&cycles, "", `root\WMI`); err != nil { errs = append(errs, fmt.Errorf("BatteryCycleCount: %w", err)) }
+	var statuses []BatteryStatus
+	if err := queryWMINamespace(&statuses, "", `root\WMI`); err != nil { errs = append(errs, fmt.Errorf("BatteryStatus: %w", err)) }
+
+	report := &BatteryReport{}
+	for _, s := range statuses {
+		if s.PowerOnline { report.OnACPower = true }
+	}
+
+	for i, b := range batteries {
+		info := BatteryInfo{
+			Name:          b.Name,
+			CapacityUnit:  "mWh",
+			ChargePercent: float64(b.EstimatedChargeRemaining),
+			State:         win32BatteryState(b.BatteryStatus),
+		}
+		if b.BatteryStatus == 2 || b.BatteryStatus >= 6 && b.BatteryStatus <= 9 { report.OnACPower = true }
+		if b.EstimatedRunTime > 0 && b.EstimatedRunTime != WIN32_BATTERY_RUNTIME_ON_AC {
+			minutes := int64(b.EstimatedRunTime)
+			info.EstimatedRuntimeMinutes = &minutes
+		}
+
+		// Sem nome comum entre as classes; as instâncias de root\WMI vêm na mesma ordem das baterias
+		if i < len(static) {
+			info.Manufacturer = strings.TrimSpace(static[i].ManufactureName)
+			info.SerialNumber = strings.TrimSpace(static[i].SerialNumber)
+			info.Chemistry = batteryChemistry(static[i].Chemistry)
+			info.DesignCapacity = int64(static[i].DesignedCapacity)
+			if name := strings.TrimSpace(static[i].DeviceName); name != "" { info.Name = name }
+			for _, f := range full {
+				if f.InstanceName == static[i].InstanceName { info.FullChargeCapacity = int64(f.FullChargedCapacity) }
+			}
+			for _, c := range cycles {
+				// Zero significa que o firmware não informa o ciclo
+				if c.InstanceName == static[i].InstanceName && c.CycleCount > 0 {
+					count := int64(c.CycleCount)
+					info.CycleCount = &count
+				}
+			}
+		}
+		report.Batteries = append(report.Batteries, info)
+	}
+	return report, partialFailure(errs)
+}
+
+func win32BatteryState(status uint16) string {
+	switch status {
+	case 1, 4, 5:
+		return BATTERY_STATE_DISCHARGING
+	case 2, 11:
+		return BATTERY_STATE_IDLE
+	case 3:
+		return BATTERY_STATE_FULL
+	case 6, 7, 8, 9:
+		return BATTERY_STATE_CHARGING
+	}
+	return BATTERY_STATE_UNKNOWN
+}
+
+// Códigos do BatteryStaticData.Chemistry (texto de 4 caracteres empacotado em little endian)
+func batteryChemistry(code uint32) string {
+	if code == 0 { return "" }
+	raw := []byte{byte(code), byte(code >> 8), byte(code >> 16), byte(code >> 24)}
+	return strings.TrimSpace(strings.Trim(string(raw), "\x00"))
+}
+
+func linuxBattery() *BatteryReport {
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	report := &BatteryReport{}
+	for _, dir := range supplies {
+		switch readSysfsString(filepath.Join(dir, "type")) {
+		case "Mains", "USB":
+			if readSysfsString(filepath.Join(dir, "online")) == "1" { report.OnACPower = true }
+		case "Battery":
+			// Baterias de mouse e teclado sem fio também aparecem aqui, com scope=Device
+			if readSysfsString(filepath.Join(dir, "scope")) == "Device" { continue }
+			report.Batteries = append(report.Batteries, linuxBatteryInfo(dir))
+		}
+	}
+	if len(report.Batteries) == 0 { return nil }
+	return report
+}
+
+func linuxBatteryInfo(dir string) BatteryInfo {
+	info := BatteryInfo{
+		Name:         filepath.Base(dir),
+		Manufacturer: readSysfsString(filepath.Join(dir, "manufacturer")),
+		SerialNumber: readSysfsString(filepath.Join(dir, "serial_number")),
+		Chemistry:    readSysfsString(filepath.Join(dir, "technology")),
+		State:        BATTERY_STATE_UNKNOWN,
+	}
+	if model := readSysfsString(filepath.Join(dir, "model_name")); model != "" { info.Name = model }
+
+	// O driver informa energia (µWh) ou carga (µAh), conforme o modelo
+	prefix, rate := "energy", "power_now"
+	info.CapacityUnit = "mWh"
+	if _, err := os.Stat(filepath.Join(dir, "energy_full")); err != nil {
+		prefix, rate = "charge", "current_now"
+		info.CapacityUnit = "mAh"
+	}
+	info.DesignCapacity = readSysfsInt(filepath.Join(dir, prefix+"_full_design")) / 1000
+	info.FullChargeCapacity = readSysfsInt(filepath.Join(dir, prefix+"_full")) / 1000
+	now := readSysfsInt(filepath.Join(dir, prefix+"_now")) / 1000
+
+	if capacity := readSysfsString(filepath.Join(dir, "capacity")); capacity != "" {
+		info.ChargePercent, _ = strconv.ParseFloat(capacity, 64)
+	} else if info.FullChargeCapacity > 0 {
+		info.ChargePercent = math.Round(float64(now) / float64(info.FullChargeCapacity) * 100)
+	}
+	if cycles := readSysfsInt(filepath.Join(dir, "cycle_count")); cycles > 0 { info.CycleCount = &cycles }
+
+	switch readSysfsString(filepath.Join(dir, "status")) {
+	case "Charging":
+		info.State = BATTERY_STATE_CHARGING
+	case "Discharging":
+		info.State = BATTERY_STATE_DISCHARGING
+		if drain := readSysfsInt(filepath.Join(dir, rate)) / 1000; drain > 0 && now > 0 {
+			minutes := now * 60 / drain
+			info.EstimatedRuntimeMinutes = &minutes
+		}
+	case "Full":
+		info.State = BATTERY_STATE_FULL
+	case "Not charging":
+		info.State = BATTERY_STATE_IDLE
+	}
+	return info
+}
+
+func readSysfsInt(path string) int64 {
+	value, err := strconv.ParseInt(readSysfsString(path), 10, 64)
+	if err != nil { return 0 }
+	// Alguns drivers informam a corrente de descarga com sinal negativo
+	if value < 0 { value = -value }
+	return value
+}
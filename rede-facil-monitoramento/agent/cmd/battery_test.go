@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBatteryChemistry(t *testing.T) {
+	cases := map[uint32]string{
+		0x6E6F494C: "LIon",
+		0x4F50694C: "LiPO",
+		0x0048694E: "NiH", // três letras, completado com zero
+		0:          "",
+	}
+	for code, want := range cases {
+		if got := batteryChemistry(code); got != want { t.Errorf("batteryChemistry(%#x) = %q, esperado %q", code, got, want) }
+	}
+}
+
+func TestWin32BatteryState(t *testing.T) {
+	cases := map[uint16]string{
+		1: BATTERY_STATE_DISCHARGING, 2: BATTERY_STATE_IDLE, 3: BATTERY_STATE_FULL, 4: BATTERY_STATE_DISCHARGING,
+		5: BATTERY_STATE_DISCHARGING, 6: BATTERY_STATE_CHARGING, 9: BATTERY_STATE_CHARGING, 10: BATTERY_STATE_UNKNOWN,
+		11: BATTERY_STATE_IDLE, 0: BATTERY_STATE_UNKNOWN,
+	}
+	for status, want := range cases {
+		if got := win32BatteryState(status); got != want { t.Errorf("win32BatteryState(%d) = %s, esperado %s", status, got, want) }
+	}
+}
+
+func TestWindowsBattery(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"Win32_Battery":              []Win32_Battery{{DeviceID: "1", Name: "Bateria interna", BatteryStatus: 1, EstimatedChargeRemaining: 63, EstimatedRunTime: 142}},
+		"BatteryStaticData":          []BatteryStaticData{{InstanceName: `ACPI\PNP0C0A\1_0`, DeviceName: "DELL 7FHHV", ManufactureName: "SMP ", SerialNumber: " 2331", DesignedCapacity: 54000, Chemistry: 0x6E6F494C}},
+		"BatteryFullChargedCapacity": []BatteryFullChargedCapacity{{InstanceName: `ACPI\PNP0C0A\1_0`, FullChargedCapacity: 30240}},
+		"BatteryCycleCount":          []BatteryCycleCount{{InstanceName: `ACPI\PNP0C0A\1_0`, CycleCount: 0}},
+		"BatteryStatus":              []BatteryStatus{{InstanceName: `ACPI\PNP0C0A\1_0`, Discharging: true}},
+	}})
+
+	remaining := int64(142)
+	want := &BatteryReport{Batteries: []BatteryInfo{{
+		Name: "DELL 7FHHV", Manufacturer: "SMP", SerialNumber: "2331", Chemistry: "LIon", CapacityUnit: "mWh",
+		DesignCapacity: 54000, FullChargeCapacity: 30240, ChargePercent: 63, State: BATTERY_STATE_DISCHARGING,
+		EstimatedRuntimeMinutes: &remaining,
+	}}}
+	if got, err := windowsBattery(); err != nil || !reflect.DeepEqual(got, want) { t.Errorf("windowsBattery = %+v, %v\nesperado %+v", got, err, want) }
+
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"Win32_Battery": []Win32_Battery{{Name: "Bateria interna", BatteryStatus: 2, EstimatedChargeRemaining: 100, EstimatedRunTime: WIN32_BATTERY_RUNTIME_ON_AC}},
+	}})
+	got, _ := windowsBattery()
+	if got == nil || !got.OnACPower || got.Batteries[0].EstimatedRuntimeMinutes != nil || got.Batteries[0].State != BATTERY_STATE_IDLE {
+		t.Errorf("na tomada: %+v", got)
+	}
+
+	useFakeWMI(t, &fakeWMI{})
+	if got, err := windowsBattery(); got != nil || err != nil { t.Errorf("desktop sem bateria = %+v, %v", got, err) }
+
+	// Consulta do Win32_Battery falhou: é erro, não "sem bateria"
+	useFakeWMI(t, &fakeWMI{Errors: map[string]error{"Win32_Battery": errors.New("acesso negado")}})
+	if got, err := windowsBattery(); got != nil || err == nil { t.Errorf("Win32_Battery com erro = %+v, %v", got, err) }
+}
+
+func TestWindowsBatteryPartialFailure(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{
+		Results: map[string]interface{}{
+			"Win32_Battery":     []Win32_Battery{{Name: "Bateria interna", BatteryStatus: 1, EstimatedChargeRemaining: 40}},
+			"BatteryStaticData": []BatteryStaticData{{InstanceName: `ACPI\PNP0C0A\1_0`, DesignedCapacity: 54000}},
+		},
+		Errors: map[string]error{
+			"BatteryFullChargedCapacity": errors.New("classe inexistente"),
+			"BatteryStatus":              errors.New("acesso negado"),
+		},
+	})
+
+	got, err := windowsBattery()
+	if got == nil || len(got.Batteries) != 1 || got.Batteries[0].DesignCapacity != 54000 { t.Fatalf("relatório parcial = %+v", got) }
+	var partial *PartialCollectorError
+	if !errors.As(err, &partial) || len(partial.Errors) != 2 { t.Fatalf("erro = %v, esperado falha parcial com 2 erros", err) }
+	if !strings.Contains(err.Error(), "BatteryFullChargedCapacity") || !strings.Contains(err.Error(), "BatteryStatus") { t.Errorf("erro sem as classes que falharam: %v", err) }
+}
+
+func writeSysfs(t *testing.T, dir string, values map[string]string) {
+	t.Helper()
+	for name, value := range values {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644); err != nil { t.Fatal(err) }
+	}
+}
+
+func TestLinuxBatteryInfo(t *testing.T) {
+	energy := t.TempDir()
+	writeSysfs(t, energy, map[string]string{
+		"model_name": "5B10W13930", "manufacturer": "SMP", "serial_number": "1234", "technology": "Li-poly",
+		"energy_full_design": "57000000", "energy_full": "45600000", "energy_now": "22800000",
+		"power_now": "11400000", "capacity": "50", "cycle_count": "312", "status": "Discharging",
+	})
+	remaining, cycles := int64(120), int64(312)
+	want := BatteryInfo{
+		Name: "5B10W13930", Manufacturer: "SMP", SerialNumber: "1234", Chemistry: "Li-poly", CapacityUnit: "mWh",
+		DesignCapacity: 57000, FullChargeCapacity: 45600, CycleCount: &cycles, ChargePercent: 50,
+		State: BATTERY_STATE_DISCHARGING, EstimatedRuntimeMinutes: &remaining,
+	}
+	if got := linuxBatteryInfo(energy); !reflect.DeepEqual(got, want) { t.Errorf("linuxBatteryInfo(energia) = %+v\nesperado %+v", got, want) }
+
+	// Driver que informa carga (µAh), sem capacity nem cycle_count e com corrente negativa
+	charge := t.TempDir()
+	writeSysfs(t, charge, map[string]string{
+		"charge_full_design": "4000000", "charge_full": "3000000", "charge_now": "1500000",
+		"current_now": "-750000", "status": "Not charging",
+	})
+	got := linuxBatteryInfo(charge)
+	if got.Name != filepath.Base(charge) || got.CapacityUnit != "mAh" || got.DesignCapacity != 4000 || got.FullChargeCapacity != 3000 || got.ChargePercent != 50 || got.CycleCount != nil || got.State != BATTERY_STATE_IDLE || got.EstimatedRuntimeMinutes != nil {
+		t.Errorf("linuxBatteryInfo(carga) = %+v", got)
+	}
+}
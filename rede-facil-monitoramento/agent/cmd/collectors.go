@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// Uma fonte de dados do agente. Cada coletor roda na sua própria cadência e o resultado fica em cache,
+// então o envio de telemetria e o registro só montam o payload com o que já foi coletado.
+type Collector interface {
+	Name() string
+	Interval() time.Duration
+	Timeout() time.Duration
+	Collect(ctx context.Context) (interface{}, error)
+}
+
+// Último resultado de um coletor. Em caso de falha o último valor bom é mantido.
+type CollectorResult struct {
+	Value       interface{}
+	Err         error
+	CollectedAt time.Time
+	SucceededAt time.Time
+	Duration    time.Duration
+}
+
+// Falha reportada ao backend junto com a telemetria e o registro
+type CollectorStatus struct {
+	Name        string `json:"name"`
+	Error       string `json:"error"`
+	DurationMS  int64  `json:"duration_ms"`
+	LastSuccess string `json:"last_success"`
+}
+
+// Falha parcial: o coletor devolveu dados, mas parte das fontes falhou. O valor entra no
+// payload e as falhas aparecem em collector_errors.
+type PartialCollectorError struct {
+	Errors []error
+}
+
+func (e *PartialCollectorError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors { msgs = append(msgs, err.Error()) }
+	return strings.Join(msgs, "; ")
+}
+
+// nil quando nenhuma fonte falhou
+func partialFailure(errs []error) error {
+	if len(errs) == 0 { return nil }
+	return &PartialCollectorError{Errors: errs}
+}
+
+type funcCollector struct {
+	name     string
+	interval time.Duration
+	timeout  time.Duration
+	fn       func(ctx context.Context) (interface{}, error)
+}
+
+func (c funcCollector) Name() string                                     { return c.name }
+func (c funcCollector) Interval() time.Duration                          { return c.interval }
+func (c funcCollector) Timeout() time.Duration                           { return c.timeout }
+func (c funcCollector) Collect(ctx context.Context) (interface{}, error) { return c.fn(ctx) }
+
+func newCollector(name string, interval time.Duration, timeout time.Duration, fn func(ctx context.Context) (interface{}, error)) Collector {
+	return funcCollector{name: name, interval: interval, timeout: timeout, fn: fn}
+}
+
+var collectorRegistry = map[string]Collector{}
+var collectorCache = map[string]CollectorResult{}
+var collectorInFlight = map[string]chan struct{}{}
+var collectorMutex sync.Mutex
+
+// Coletores usados em cada payload
+var telemetryCollectors = []string{"cpu", "memory", "volumes", "disk_health", "temperature", "processes", "battery", "traffic", "wifi", "system"}
+var staticCollectors = []string{"system", "hardware", "volumes", "physical_disks", "network_inventory", "monitors", "usb_devices", "printers", "software", "restore_point"}
+
+// Dados do sistema que mudam pouco (boot, SO e CPU)
+type systemSnapshot struct {
+	Host             *host.InfoStat
+	CPUModel         string
+	CPUSpeedMhz      float64
+	CPUCoresPhysical int
+	CPUCoresLogical  int
+	RAMTotal         uint64
+}
+
+type hardwareSnapshot struct {
+	MachineModel   string
+	SerialNumber   string
+	MachineType    string
+	MBManufacturer string
+	MBModel        string
+	MBVersion      string
+	GPUModel       string
+	GPUVRAMMB      int
+	MemSlotsTotal  int
+	MemSlotsUsed   int
+}
+
+func init() {
+	registerCollector(newCollector("cpu", 20*time.Second, 5*time.Second, func(ctx context.Context) (interface{}, error) {
+		percent, err := cpu.PercentWithContext(ctx, 1*time.Second, false)
+		if err != nil { return nil, err }
+		if len(percent) == 0 { return nil, fmt.Errorf("sem amostra de CPU") }
+		return percent[0], nil
+	}))
+	registerCollector(newCollector("memory", 20*time.Second, 5*time.Second, func(ctx context.Context) (interface{}, error) {
+		return mem.VirtualMemoryWithContext(ctx)
+	}))
+	registerCollector(newCollector("volumes", 1*time.Minute, 20*time.Second, collectVolumes))
+	registerCollector(newCollector("physical_disks", 1*time.Hour, 1*time.Minute, collectPhysicalDisks))
+	registerCollector(newCollector("disk_health", 30*time.Minute, 3*time.Minute, collectDiskHealth))
+	registerCollector(newCollector("temperature", 1*time.Minute, 20*time.Second, collectTemperatures))
+	registerCollector(newCollector("processes", 20*time.Second, 15*time.Second, collectProcesses))
+	registerCollector(newCollector("battery", 1*time.Minute, 20*time.Second, collectBattery))
+	registerCollector(newCollector("traffic", 20*time.Second, 10*time.Second, func(ctx context.Context) (interface{}, error) {
+		return collectInterfaceTraffic(), nil
+	}))
+	registerCollector(newCollector("wifi", 1*time.Minute, 15*time.Second, func(ctx context.Context) (interface{}, error) {
+		return collectWiFiInfo(), nil
+	}))
+	registerCollector(newCollector("system", 1*time.Hour, 30*time.Second, collectSystemSnapshot))
+	registerCollector(newCollector("hardware", 1*time.Hour, 1*time.Minute, func(ctx context.Context) (interface{}, error) {
+		return collectHardwareSnapshot(), nil
+	}))
+	registerCollector(newCollector("network_inventory", NETWORK_FULL_CHECK_INTERVAL, 1*time.Minute, func(ctx context.Context) (interface{}, error) {
+		return collectNetworkInventory(), nil
+	}))
+	registerCollector(newCollector("monitors", 1*time.Hour, 30*time.Second, collectMonitors))
+	registerCollector(newCollector("usb_devices", 1*time.Hour, 30*time.Second, collectUSBDevices))
+	registerCollector(newCollector("printers", 1*time.Hour, 30*time.Second, collectPrinters))
+	registerCollector(newCollector("software", 24*time.Hour, 3*time.Minute, func(ctx context.Context) (interface{}, error) {
+		return collectInstalledSoftware(), nil
+	}))
+	registerCollector(newCollector("patches", 6*time.Hour, PATCH_SCAN_TIMEOUT+2*time.Minute, collectPatches))
+	registerCollector(newCollector("security_posture", 1*time.Hour, 2*time.Minute, collectSecurityPosture))
+	registerCollector(newCollector("restore_point", 1*time.Hour, 10*time.Second, func(ctx context.Context) (interface{}, error) {
+		return getLastRestorePoint(), nil
+	}))
+}
+
+func registerCollector(c Collector) {
+	collectorMutex.Lock()
+	defer collectorMutex.Unlock()
+	collectorRegistry[c.Name()] = c
+}
+
+// Descarta o cache para que o próximo registro colete de novo (ex: após instalar um pacote)
+func invalidateCollector(name string) {
+	collectorMutex.Lock()
+	defer collectorMutex.Unlock()
+	delete(collectorCache, name)
+}
+
+// Uma goroutine por coletor, cada uma na sua cadência
+func startCollectorScheduler() {
+	collectorMutex.Lock()
+	var collectors []Collector
+	for _, c := range collectorRegistry { collectors = append(collectors, c) }
+	collectorMutex.Unlock()
+
+	for _, c := range collectors { go runCollectorLoop(c) }
+}
+
+func runCollectorLoop(c Collector) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado no agendador do coletor %s: %v", c.Name(), r)
+			time.Sleep(c.Interval())
+			go runCollectorLoop(c)
+		}
+	}()
+
+	for {
+		runCollector(c)
+		time.Sleep(c.Interval())
+	}
+}
+
+// Executa o coletor com timeout. Se uma execução anterior ainda não terminou, espera por ela
+// em vez de disparar outra (uma consulta travada não acumula goroutines).
+func runCollector(c Collector) CollectorResult {
+	collectorMutex.Lock()
+	if running, ok := collectorInFlight[c.Name()]; ok {
+		collectorMutex.Unlock()
+		select {
+		case <-running:
+		case <-time.After(c.Timeout()):
+		}
+		return cachedCollectorResult(c.Name())
+	}
+	running := make(chan struct{})
+	collectorInFlight[c.Name()] = running
+	collectorMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout())
+	defer cancel()
+
+	done := make(chan CollectorResult, 1)
+	start := time.Now()
+	go func() {
+		var value interface{}
+		var err error
+		defer func() {
+			if r := recover(); r != nil { err = fmt.Errorf("pânico: %v", r) }
+			// Mesmo depois de um timeout o resultado tardio atualiza o cache
+			done <- storeCollectorResult(c.Name(), value, err, start, true)
+			close(running)
+		}()
+		value, err = c.Collect(ctx)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		return storeCollectorResult(c.Name(), nil, fmt.Errorf("timeout após %s", c.Timeout()), start, false)
+	}
+}
+
+func storeCollectorResult(name string, value interface{}, err error, start time.Time, finished bool) CollectorResult {
+	collectorMutex.Lock()
+	defer collectorMutex.Unlock()
+	if finished { delete(collectorInFlight, name) }
+
+	result := collectorCache[name]
+	result.CollectedAt = time.Now()
+	result.Duration = time.Since(start)
+	result.Err = err
+	var partial *PartialCollectorError
+	if err == nil || errors.As(err, &partial) { result.Value = value }
+	if err == nil {
+		result.SucceededAt = result.CollectedAt
+	} else {
+		log.Printf("⚠️ Coletor %s falhou: %v", name, err)
+	}
+	collectorCache[name] = result
+	return result
+}
+
+func cachedCollectorResult(name string) CollectorResult {
+	collectorMutex.Lock()
+	defer collectorMutex.Unlock()
+	return collectorCache[name]
+}
+
+// Resultados dos coletores pedidos. Usa o cache quando ainda está dentro do intervalo
+// e coleta em paralelo os que estão vencidos ou nunca rodaram.
+func gatherCollectors(names []string) map[string]CollectorResult {
+	results := map[string]CollectorResult{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, name := range names {
+		collectorMutex.Lock()
+		c, ok := collectorRegistry[name]
+		cached, hasCache := collectorCache[name]
+		collectorMutex.Unlock()
+		if !ok { continue }
+
+		if hasCache && time.Since(cached.CollectedAt) < c.Interval()+c.Timeout() {
+			results[name] = cached
+			continue
+		}
+		wg.Add(1)
+		go func(c Collector) {
+			defer wg.Done()
+			result := runCollector(c)
+			mu.Lock()
+			results[c.Name()] = result
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+	return results
+}
+
+// Valor tipado do resultado; falso quando o coletor nunca teve sucesso
+func collectorValue[T any](results map[string]CollectorResult, name string) (T, bool) {
+	value, ok := results[name].Value.(T)
+	return value, ok
+}
+
+func collectorFailures(results map[string]CollectorResult) []CollectorStatus {
+	var failures []CollectorStatus
+	for name, r := range results {
+		if r.Err == nil { continue }
+		status := CollectorStatus{Name: name, Error: r.Err.Error(), DurationMS: r.Duration.Milliseconds()}
+		if !r.SucceededAt.IsZero() { status.LastSuccess = r.SucceededAt.Format(time.RFC3339) }
+		failures = append(failures, status)
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Name < failures[j].Name })
+	return failures
+}
+
+func collectSystemSnapshot(ctx context.Context) (interface{}, error) {
+	hInfo, err := host.InfoWithContext(ctx)
+	if err != nil { return nil, err }
+	snap := systemSnapshot{Host: hInfo, CPUModel: "N/A"}
+
+	if cInfos, err := cpu.InfoWithContext(ctx); err == nil && len(cInfos) > 0 {
+		snap.CPUModel = cInfos[0].ModelName
+		snap.CPUSpeedMhz = cInfos[0].Mhz
+	}
+	snap.CPUCoresPhysical, _ = cpu.CountsWithContext(ctx, false)
+	snap.CPUCoresLogical, _ = cpu.CountsWithContext(ctx, true)
+	if mInfo, err := mem.VirtualMemoryWithContext(ctx); err == nil { snap.RAMTotal = mInfo.Total }
+	return snap, nil
+}
+
+func collectHardwareSnapshot() hardwareSnapshot {
+	var hw hardwareSnapshot
+	hw.MachineModel, hw.SerialNumber, hw.MBManufacturer, hw.MBModel, hw.MBVersion = getHardwareIdentity()
+	hw.GPUModel, hw.GPUVRAMMB = getGPUInfo()
+	hw.MemSlotsTotal, hw.MemSlotsUsed = getMemorySlotsInfo()
+	hw.MachineType = getMachineType()
+	return hw
+}
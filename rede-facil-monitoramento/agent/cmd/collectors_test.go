@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreCollectorResult(t *testing.T) {
+	const name = "teste_parcial"
+	t.Cleanup(func() { invalidateCollector(name) })
+	start := time.Now()
+
+	ok := storeCollectorResult(name, "completo", nil, start, true)
+	if ok.Value != "completo" || ok.Err != nil || ok.SucceededAt.IsZero() { t.Fatalf("sucesso = %+v", ok) }
+
+	// Falha total mantém o último valor bom
+	failed := storeCollectorResult(name, nil, errors.New("timeout"), start, true)
+	if failed.Value != "completo" || failed.Err == nil || !failed.SucceededAt.Equal(ok.SucceededAt) { t.Fatalf("falha = %+v", failed) }
+
+	// Falha parcial usa o valor novo e mantém o erro para collector_errors
+	partial := storeCollectorResult(name, "parcial", partialFailure([]error{errors.New("Win32_Tpm: acesso negado")}), start, true)
+	if partial.Value != "parcial" || partial.Err == nil || partial.Err.Error() != "Win32_Tpm: acesso negado" { t.Fatalf("parcial = %+v", partial) }
+
+	failures := collectorFailures(map[string]CollectorResult{name: partial, "cpu": ok})
+	if len(failures) != 1 || failures[0].Name != name || failures[0].LastSuccess == "" { t.Errorf("falhas = %+v", failures) }
+}
+
+func TestPartialFailureEmpty(t *testing.T) {
+	if err := partialFailure(nil); err != nil { t.Errorf("partialFailure(nil) = %v", err) }
+}
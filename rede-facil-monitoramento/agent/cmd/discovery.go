@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limites da varredura para não saturar a rede da filial
+const DISCOVERY_MAX_HOSTS = 1024
+const DISCOVERY_CONCURRENCY = 16
+const DISCOVERY_MAX_CONCURRENCY = 64
+const DISCOVERY_PROBE_DELAY = 20 * time.Millisecond
+const DISCOVERY_TCP_TIMEOUT = 500 * time.Millisecond
+const DISCOVERY_DNS_TIMEOUT = 2 * time.Second
+const DISCOVERY_DNS_CONCURRENCY = 16
+
+// Portas comuns: SSH, Telnet, HTTP, HTTPS, SMB, RDP, impressoras (LPD, IPP, JetDirect) e painéis web
+var discoveryDefaultPorts = []int{22, 23, 80, 443, 445, 515, 631, 3389, 8080, 9100}
+
+// Payload do comando discover_subnet (todos os campos são opcionais)
+type DiscoveryRequest struct {
+	Ports        []int `json:"ports"`
+	Concurrency  int   `json:"concurrency"`
+	ProbeDelayMS int   `json:"probe_delay_ms"`
+	MaxHosts     int   `json:"max_hosts"`
+}
+
+type DiscoveredDevice struct {
+	IPAddress    string `json:"ip_address"`
+	MACAddress   string `json:"mac_address"`
+	Vendor       string `json:"vendor"`
+	Hostname     string `json:"hostname"`
+	RespondsPing bool   `json:"responds_ping"`
+	OpenPorts    []int  `json:"open_ports"`
+}
+
+type DiscoveryReport struct {
+	MachineUUID  string             `json:"machine_uuid"`
+	Subnet       string             `json:"subnet"`
+	Gateway      string             `json:"gateway"`
+	HostsScanned int                `json:"hosts_scanned"`
+	SubnetHosts  int                `json:"subnet_hosts"`
+	Truncated    bool               `json:"truncated"` // sub-rede maior que max_hosts: só os primeiros endereços foram varridos
+	DurationMS   int64              `json:"duration_ms"`
+	Devices      []DiscoveredDevice `json:"devices"`
+}
+
+func handleDiscoverSubnet(payload string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado na descoberta de rede: %v", r)
+			sendCommandResult("", fmt.Sprintf("Erro interno: %v", r))
+		}
+	}()
+
+	var req DiscoveryRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			sendCommandResult("", fmt.Sprintf("Payload inválido: %v", err))
+			return
+		}
+	}
+	req.applyDefaults()
+	probeDelay := DISCOVERY_PROBE_DELAY
+	if req.ProbeDelayMS > 0 { probeDelay = time.Duration(req.ProbeDelayMS) * time.Millisecond }
+
+	report, err := scanLocalSubnet(req, probeDelay)
+	if err != nil {
+		log.Printf("❌ Descoberta de rede falhou: %v", err)
+		sendCommandResult("", err.Error())
+		return
+	}
+
+	log.Printf("🔎 Descoberta concluída em %s: %d dispositivo(s)", report.Subnet, len(report.Devices))
+	postData("/telemetry/discovery", report)
+	summary := fmt.Sprintf("Descoberta concluída em %s: %d dispositivo(s) encontrados em %d endereços.", report.Subnet, len(report.Devices), report.HostsScanned)
+	if report.Truncated { summary += fmt.Sprintf(" Varredura limitada aos primeiros %d de %d endereços.", report.HostsScanned, report.SubnetHosts) }
+	sendCommandResult(summary, "")
+}
+
+// Campos ausentes recebem o padrão; concorrência e hosts são limitados mesmo quando vêm do payload
+func (req *DiscoveryRequest) applyDefaults() {
+	if len(req.Ports) == 0 { req.Ports = discoveryDefaultPorts }
+	if req.Concurrency <= 0 { req.Concurrency = DISCOVERY_CONCURRENCY }
+	if req.Concurrency > DISCOVERY_MAX_CONCURRENCY { req.Concurrency = DISCOVERY_MAX_CONCURRENCY }
+	if req.MaxHosts <= 0 || req.MaxHosts > DISCOVERY_MAX_HOSTS { req.MaxHosts = DISCOVERY_MAX_HOSTS }
+}
+
+// Sub-rede IPv4 da interface principal (a que alcança o backend)
+func localSubnet() (*net.IPNet, string, error) {
+	nics := collectNetworkInventory()
+	gateway, _ := networkDetailsFrom(nics)
+	primary := primaryInterface(nics)
+	if primary == nil || len(primary.IPv4Addresses) == 0 { return nil, gateway, fmt.Errorf("IP local indisponível") }
+
+	// Se a rota até o backend usa IPv6, varre a primeira rede IPv4 da mesma interface
+	cidr := primary.IPv4Addresses[0]
+	localIP := getLocalIP()
+	for _, c := range primary.IPv4Addresses {
+		if ip, _, err := net.ParseCIDR(c); err == nil && ip.String() == localIP { cidr = c }
+	}
+
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil { return nil, gateway, fmt.Errorf("máscara de rede indisponível: %v", err) }
+	return subnet, gateway, nil
+}
+
+// Endereços de host da sub-rede (sem rede e broadcast), no máximo limit; total é quantos a sub-rede tem
+func subnetHosts(subnet *net.IPNet, limit int) (hosts []net.IP, total int) {
+	start := binary.BigEndian.Uint32(subnet.IP.To4())
+	ones, bits := subnet.Mask.Size()
+	size := uint64(1) << uint32(bits-ones)
+	if size > 2 { total = int(size - 2) }
+
+	for i := uint64(1); i <= uint64(total) && len(hosts) < limit; i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, start+uint32(i))
+		hosts = append(hosts, ip)
+	}
+	return hosts, total
+}
+
+func scanLocalSubnet(req DiscoveryRequest, probeDelay time.Duration) (DiscoveryReport, error) {
+	startTime := time.Now()
+	subnet, gateway, err := localSubnet()
+	if err != nil { return DiscoveryReport{}, err }
+
+	hosts, total := subnetHosts(subnet, req.MaxHosts)
+	log.Printf("🔎 Varrendo %s (%d de %d endereços, %d em paralelo)", subnet.String(), len(hosts), total, req.Concurrency)
+
+	var mu sync.Mutex
+	found := map[string]*DiscoveredDevice{}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, req.Concurrency)
+
+	for _, ip := range hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ip string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			dev := probeHost(ip, req.Ports)
+			if dev == nil { return }
+			mu.Lock()
+			found[ip] = dev
+			mu.Unlock()
+		}(ip.String())
+		time.Sleep(probeDelay)
+	}
+	wg.Wait()
+
+	// A varredura popula a tabela ARP, inclusive de hosts que bloqueiam ICMP e TCP
+	for _, entry := range readArpTable() {
+		ip := net.ParseIP(entry.IPAddress)
+		if ip == nil || !subnet.Contains(ip) { continue }
+		dev, ok := found[entry.IPAddress]
+		if !ok {
+			dev = &DiscoveredDevice{IPAddress: entry.IPAddress}
+			found[entry.IPAddress] = dev
+		}
+		dev.MACAddress = entry.MACAddress
+		dev.Vendor = lookupVendor(entry.MACAddress)
+	}
+
+	resolveHostnames(found, reverseLookup, DISCOVERY_DNS_CONCURRENCY)
+
+	var devices []DiscoveredDevice
+	for _, dev := range found { devices = append(devices, *dev) }
+	sort.Slice(devices, func(i, j int) bool {
+		return binary.BigEndian.Uint32(net.ParseIP(devices[i].IPAddress).To4()) < binary.BigEndian.Uint32(net.ParseIP(devices[j].IPAddress).To4())
+	})
+
+	return DiscoveryReport{
+		MachineUUID:  getMachineUUID(),
+		Subnet:       subnet.String(),
+		Gateway:      gateway,
+		HostsScanned: len(hosts),
+		SubnetHosts:  total,
+		Truncated:    len(hosts) < total,
+		DurationMS:   time.Since(startTime).Milliseconds(),
+		Devices:      devices,
+	}, nil
+}
+
+// Retorna nil quando o host não respondeu a nenhuma sonda
+func probeHost(ip string, ports []int) *DiscoveredDevice {
+	dev := &DiscoveredDevice{IPAddress: ip}
+	_, loss := pingHost(ip)
+	dev.RespondsPing = loss < 100
+
+	for _, port := range ports {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(port)), DISCOVERY_TCP_TIMEOUT)
+		if err != nil { continue }
+		conn.Close()
+		dev.OpenPorts = append(dev.OpenPorts, port)
+	}
+
+	if !dev.RespondsPing && len(dev.OpenPorts) == 0 { return nil }
+	return dev
+}
+
+// PTR em paralelo, limitado a workers consultas simultâneas: em sequência, uma sub-rede cheia
+// de hosts sem registro reverso somava DISCOVERY_DNS_TIMEOUT por dispositivo
+func resolveHostnames(devices map[string]*DiscoveredDevice, lookup func(ip string) string, workers int) {
+	queue := make(chan *DiscoveredDevice)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dev := range queue { dev.Hostname = lookup(dev.IPAddress) }
+		}()
+	}
+	for _, dev := range devices { queue <- dev }
+	close(queue)
+	wg.Wait()
+}
+
+func reverseLookup(ip string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), DISCOVERY_DNS_TIMEOUT)
+	defer cancel()
+	names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+	if err != nil || len(names) == 0 { return "" }
+	return strings.TrimSuffix(names[0], ".")
+}
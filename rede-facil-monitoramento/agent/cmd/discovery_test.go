@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubnetHosts(t *testing.T) {
+	cases := []struct {
+		cidr      string
+		limit     int
+		wantCount int
+		wantTotal int
+		wantFirst string
+		wantLast  string
+	}{
+		{"192.168.0.0/24", DISCOVERY_MAX_HOSTS, 254, 254, "192.168.0.1", "192.168.0.254"},
+		{"10.0.0.0/22", DISCOVERY_MAX_HOSTS, 1022, 1022, "10.0.0.1", "10.0.3.254"},
+		{"10.0.0.0/16", DISCOVERY_MAX_HOSTS, 1024, 65534, "10.0.0.1", "10.0.4.0"},
+		{"192.168.0.0/24", 10, 10, 254, "192.168.0.1", "192.168.0.10"},
+		{"192.168.0.4/30", DISCOVERY_MAX_HOSTS, 2, 2, "192.168.0.5", "192.168.0.6"},
+		{"192.168.0.4/31", DISCOVERY_MAX_HOSTS, 0, 0, "", ""},
+		{"192.168.0.4/32", DISCOVERY_MAX_HOSTS, 0, 0, "", ""},
+	}
+	for _, c := range cases {
+		_, subnet, _ := net.ParseCIDR(c.cidr)
+		hosts, total := subnetHosts(subnet, c.limit)
+		if len(hosts) != c.wantCount || total != c.wantTotal {
+			t.Errorf("subnetHosts(%s, %d) = %d endereços de %d, esperado %d de %d", c.cidr, c.limit, len(hosts), total, c.wantCount, c.wantTotal)
+			continue
+		}
+		if len(hosts) == 0 { continue }
+		if hosts[0].String() != c.wantFirst || hosts[len(hosts)-1].String() != c.wantLast {
+			t.Errorf("subnetHosts(%s) vai de %s a %s, esperado %s a %s", c.cidr, hosts[0], hosts[len(hosts)-1], c.wantFirst, c.wantLast)
+		}
+	}
+}
+
+func TestDiscoveryRequestDefaults(t *testing.T) {
+	cases := []struct {
+		req             DiscoveryRequest
+		wantConcurrency int
+		wantMaxHosts    int
+	}{
+		{DiscoveryRequest{}, DISCOVERY_CONCURRENCY, DISCOVERY_MAX_HOSTS},
+		{DiscoveryRequest{Concurrency: 8, MaxHosts: 100}, 8, 100},
+		{DiscoveryRequest{Concurrency: 100000, MaxHosts: 1 << 20}, DISCOVERY_MAX_CONCURRENCY, DISCOVERY_MAX_HOSTS},
+		{DiscoveryRequest{Concurrency: -1, MaxHosts: -1}, DISCOVERY_CONCURRENCY, DISCOVERY_MAX_HOSTS},
+	}
+	for _, c := range cases {
+		req := c.req
+		req.applyDefaults()
+		if req.Concurrency != c.wantConcurrency || req.MaxHosts != c.wantMaxHosts || len(req.Ports) == 0 {
+			t.Errorf("applyDefaults(%+v) = %+v, esperado concorrência %d e %d hosts", c.req, req, c.wantConcurrency, c.wantMaxHosts)
+		}
+	}
+}
+
+func TestLookupVendor(t *testing.T) {
+	cases := []struct{ mac, want string }{
+		{"00:26:AB:12:34:56", "Seiko Epson"},
+		{"b8:27:eb:aa:bb:cc", "Raspberry Pi"},
+		{"00:1A:3F:00:00:01", "Intelbras"},
+		{"F4:F2:6D:01:02:03", "TP-Link"},
+		{"DA:A1:19:00:00:01", "MAC aleatório/local"},
+		{"00:99:99:00:00:01", "Desconhecido"},
+		{"00:26", ""},
+	}
+	for _, c := range cases {
+		if got := lookupVendor(c.mac); got != c.want { t.Errorf("lookupVendor(%s) = %q, esperado %q", c.mac, got, c.want) }
+	}
+}
+
+func TestOUIPrefixesAreUnique(t *testing.T) {
+	owner := map[string]string{}
+	for vendor, prefixes := range ouiPrefixes {
+		for _, prefix := range prefixes {
+			if len(prefix) != 8 || strings.ToUpper(prefix) != prefix { t.Errorf("%s: prefixo mal formatado %q", vendor, prefix) }
+			if other, dup := owner[prefix]; dup { t.Errorf("prefixo %s em %s e %s", prefix, other, vendor) }
+			owner[prefix] = vendor
+		}
+	}
+}
+
+func TestResolveHostnamesIsBounded(t *testing.T) {
+	devices := map[string]*DiscoveredDevice{}
+	for i := 1; i <= 40; i++ {
+		ip := net.IPv4(192, 168, 0, byte(i)).String()
+		devices[ip] = &DiscoveredDevice{IPAddress: ip}
+	}
+
+	var running, peak int32
+	var mu sync.Mutex
+	lookup := func(ip string) string {
+		now := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		if now > peak { peak = now }
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if ip == "192.168.0.1" { return "gateway.filial.local" }
+		return ""
+	}
+
+	resolveHostnames(devices, lookup, 4)
+	if peak > 4 { t.Errorf("%d consultas simultâneas, limite 4", peak) }
+	if peak < 2 { t.Errorf("consultas não rodaram em paralelo (pico %d)", peak) }
+	if devices["192.168.0.1"].Hostname != "gateway.filial.local" { t.Errorf("hostname = %q", devices["192.168.0.1"].Hostname) }
+}
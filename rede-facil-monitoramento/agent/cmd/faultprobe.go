@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Âncoras públicas e nome usado para testar a resolução DNS
+var publicAnchors = []string{"8.8.8.8", "1.1.1.1"}
+
+const DNS_PROBE_HOSTNAME = "www.google.com"
+const DNS_PROBE_TIMEOUT = 3 * time.Second
+const BACKEND_TCP_TIMEOUT = 3 * time.Second
+const GATEWAY_TCP_TIMEOUT = 1 * time.Second
+
+// Portas tentadas quando o gateway não responde ao ping: DNS, painel web e HTTPS
+var gatewayFallbackPorts = []string{"53", "80", "443"}
+
+// Papéis de cada alvo sondado
+const (
+	PROBE_ROLE_GATEWAY  = "gateway"
+	PROBE_ROLE_BACKEND  = "backend"
+	PROBE_ROLE_DNS      = "dns"
+	PROBE_ROLE_INTERNET = "internet"
+)
+
+// Diagnósticos possíveis por ciclo
+const (
+	DIAG_OK                  = "ok"
+	DIAG_LAN_DOWN            = "lan_down"
+	DIAG_WAN_DOWN            = "wan_down"
+	DIAG_DNS_FAILING         = "dns_failing"
+	DIAG_BACKEND_UNREACHABLE = "backend_unreachable"
+)
+
+type TargetProbe struct {
+	Role      string
+	Target    string
+	Reachable bool
+	Latency   LatencyProbe
+	Detail    string
+}
+
+// Todas as sondas de um ciclo em um único POST
+type NetworkProbeBatch struct {
+	MachineUUID string         `json:"machine_uuid"`
+	Diagnosis   string         `json:"diagnosis"`
+	CollectedAt string         `json:"collected_at"`
+	Probes      []NetworkStats `json:"probes"`
+}
+
+var probeBatchMutex sync.Mutex
+var probeBatchSending bool
+
+// Sonda gateway, backend, servidores DNS e âncoras públicas em paralelo e devolve o diagnóstico do ciclo
+func runFaultIsolationProbes() ([]TargetProbe, string) {
+	var probes []TargetProbe
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	add := func(p TargetProbe) {
+		mu.Lock()
+		probes = append(probes, p)
+		mu.Unlock()
+	}
+
+	if gateway := getDefaultGateway(); gateway != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			add(probeGateway(gateway))
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		add(probeBackend())
+	}()
+
+	for _, server := range getDNSServers() {
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+			add(probeDNSServer(server))
+		}(server)
+	}
+
+	for _, anchor := range publicAnchors {
+		wg.Add(1)
+		go func(anchor string) {
+			defer wg.Done()
+			add(probeICMPTarget(PROBE_ROLE_INTERNET, anchor))
+		}(anchor)
+	}
+
+	wg.Wait()
+	return probes, diagnoseProbes(probes)
+}
+
+func probeICMPTarget(role string, target string) TargetProbe {
+	latency := measureLatency(target, NETWORK_PROBE_COUNT)
+	return TargetProbe{Role: role, Target: target, Reachable: latency.Received > 0, Latency: latency}
+}
+
+// Roteador de operadora costuma descartar ICMP; sem isso a falta de ping vira lan_down. Tenta TCP
+// e, por fim, o ARP: uma conexão recusada ou descartada ainda obriga o gateway a responder ao ARP.
+func probeGateway(gateway string) TargetProbe {
+	probe := probeICMPTarget(PROBE_ROLE_GATEWAY, gateway)
+	if probe.Reachable {
+		gatewayARP.reset(gateway)
+		return probe
+	}
+
+	trustARP := gatewayARP.prepare(gateway, deleteArpEntry)
+	for _, port := range gatewayFallbackPorts {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(gateway, port), GATEWAY_TCP_TIMEOUT)
+		if err != nil { continue }
+		conn.Close()
+		gatewayARP.reset(gateway)
+		probe.Reachable = true
+		probe.Detail = fmt.Sprintf("sem resposta ao ping; tcp %s aberta", port)
+		return probe
+	}
+
+	// Sem ter apagado a entrada ela pode ser antiga e não prova nada
+	if trustARP {
+		for _, entry := range readArpTable() {
+			if entry.IPAddress != gateway { continue }
+			probe.Reachable = true
+			probe.Detail = fmt.Sprintf("sem resposta ao ping; ARP %s", entry.MACAddress)
+			return probe
+		}
+	}
+	probe.Detail = "sem resposta a ping, TCP ou ARP"
+	return probe
+}
+
+// Gateways cuja entrada ARP já foi apagada enquanto não respondem a ping nem TCP. Apagar o cache ARP
+// a cada ciclo em todo PC da filial é invasivo: a entrada é apagada uma vez e depois a tabela só é
+// lida, porque o sistema descarta sozinho a entrada de um gateway que para de responder ao ARP.
+type gatewayARPState struct {
+	mu      sync.Mutex
+	flushed map[string]bool
+}
+
+var gatewayARP = &gatewayARPState{flushed: map[string]bool{}}
+
+// Apaga a entrada na primeira vez; diz se a tabela ARP serve como prova de que o gateway responde
+func (s *gatewayARPState) prepare(gateway string, flush func(ip string) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.flushed[gateway] { return true }
+	s.flushed[gateway] = flush(gateway)
+	return s.flushed[gateway]
+}
+
+// Gateway respondeu a ping ou TCP: uma próxima falha volta a apagar a entrada
+func (s *gatewayARPState) reset(gateway string) {
+	s.mu.Lock()
+	delete(s.flushed, gateway)
+	s.mu.Unlock()
+}
+
+// O backend pode bloquear ICMP, então o que vale é a conexão TCP na porta da API
+func probeBackend() TargetProbe {
+	host, port := backendHostPort()
+	probe := probeICMPTarget(PROBE_ROLE_BACKEND, host)
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), BACKEND_TCP_TIMEOUT)
+	if err != nil {
+		probe.Reachable = false
+		probe.Detail = fmt.Sprintf("tcp %s: %v", port, err)
+		return probe
+	}
+	conn.Close()
+	probe.Reachable = true
+	probe.Detail = fmt.Sprintf("tcp %s: %dms", port, time.Since(start).Milliseconds())
+	return probe
+}
+
+func probeDNSServer(server string) TargetProbe {
+	probe := TargetProbe{Role: PROBE_ROLE_DNS, Target: server}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: DNS_PROBE_TIMEOUT}
+			return d.DialContext(ctx, "udp", net.JoinHostPort(server, "53"))
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DNS_PROBE_TIMEOUT)
+	defer cancel()
+	start := time.Now()
+	addrs, err := resolver.LookupHost(ctx, DNS_PROBE_HOSTNAME)
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+	probe.Latency = LatencyProbe{Sent: 1, LossPct: 100}
+	if err != nil || len(addrs) == 0 {
+		probe.Detail = fmt.Sprintf("falha ao resolver %s: %v", DNS_PROBE_HOSTNAME, err)
+		return probe
+	}
+	probe.Reachable = true
+	probe.Latency.RTTs = []float64{elapsed}
+	probe.Latency.summarize()
+	probe.Detail = fmt.Sprintf("%s -> %s", DNS_PROBE_HOSTNAME, addrs[0])
+	return probe
+}
+
+func diagnoseProbes(probes []TargetProbe) string {
+	reachable := map[string]bool{}
+	present := map[string]bool{}
+	for _, p := range probes {
+		present[p.Role] = true
+		if p.Reachable { reachable[p.Role] = true }
+	}
+
+	if !reachable[PROBE_ROLE_GATEWAY] && !reachable[PROBE_ROLE_BACKEND] && !reachable[PROBE_ROLE_INTERNET] { return DIAG_LAN_DOWN }
+	if !reachable[PROBE_ROLE_INTERNET] && !reachable[PROBE_ROLE_BACKEND] { return DIAG_WAN_DOWN }
+	if present[PROBE_ROLE_DNS] && !reachable[PROBE_ROLE_DNS] { return DIAG_DNS_FAILING }
+	if !reachable[PROBE_ROLE_BACKEND] { return DIAG_BACKEND_UNREACHABLE }
+	return DIAG_OK
+}
+
+func buildProbeBatch(probes []TargetProbe, diagnosis string, wifi *WiFiInfo) NetworkProbeBatch {
+	batch := NetworkProbeBatch{
+		MachineUUID: getMachineUUID(),
+		Diagnosis:   diagnosis,
+		CollectedAt: time.Now().Format(time.RFC3339),
+	}
+	for _, p := range probes {
+		stats := buildNetworkStats(p.Target, p.Latency)
+		stats.Role = p.Role
+		stats.Reachable = p.Reachable
+		stats.Diagnosis = diagnosis
+		stats.Detail = p.Detail
+		stats.WiFi = wifi
+		batch.Probes = append(batch.Probes, stats)
+	}
+	return batch
+}
+
+// Envio em segundo plano e sem retentativa: com o backend fora do ar o ciclo de sondas não pode
+// parar esperando, e o próximo ciclo já traz dados novos. Se o envio anterior ainda estiver
+// pendurado, este é descartado.
+func sendProbeBatch(batch NetworkProbeBatch) {
+	probeBatchMutex.Lock()
+	if probeBatchSending {
+		probeBatchMutex.Unlock()
+		return
+	}
+	probeBatchSending = true
+	probeBatchMutex.Unlock()
+
+	go func() {
+		defer func() {
+			probeBatchMutex.Lock()
+			probeBatchSending = false
+			probeBatchMutex.Unlock()
+		}()
+		jsonValue, err := json.Marshal(batch)
+		if err != nil { return }
+		postJSON("/telemetry/network", jsonValue)
+	}()
+}
+
+func backendHostPort() (string, string) {
+	u, err := url.Parse(API_BASE_URL)
+	if err != nil { return "", "" }
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" { port = "443" }
+	}
+	return u.Hostname(), port
+}
+
+func getDefaultGateway() string {
+	gateway, _ := getNetworkDetails()
+	if net.ParseIP(gateway) == nil { return "" }
+	return gateway
+}
+
+func getDNSServers() []string {
+	var servers []string
+	if runtime.GOOS == "windows" {
+		output, err := runCommandHidden("powershell", "-NoProfile", "-Command", "Get-DnsClientServerAddress -AddressFamily IPv4 | Select-Object -ExpandProperty ServerAddresses")
+		if err != nil { return nil }
+		for _, line := range strings.Split(output, "\n") {
+			servers = appendUniqueIP(servers, strings.TrimSpace(line))
+		}
+		return servers
+	}
+
+	data, err := os.ReadFile("/etc/resolv.conf")
+	if err != nil { return nil }
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" { servers = appendUniqueIP(servers, fields[1]) }
+	}
+	return servers
+}
+
+func appendUniqueIP(list []string, ip string) []string {
+	if net.ParseIP(ip) == nil { return list }
+	for _, existing := range list {
+		if existing == ip { return list }
+	}
+	return append(list, ip)
+}
@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestDiagnoseProbes(t *testing.T) {
+	probe := func(role string, reachable bool) TargetProbe { return TargetProbe{Role: role, Reachable: reachable} }
+	cases := []struct {
+		name   string
+		probes []TargetProbe
+		want   string
+	}{
+		{"tudo respondendo", []TargetProbe{probe(PROBE_ROLE_GATEWAY, true), probe(PROBE_ROLE_BACKEND, true), probe(PROBE_ROLE_DNS, true), probe(PROBE_ROLE_INTERNET, true)}, DIAG_OK},
+		{"nada responde", []TargetProbe{probe(PROBE_ROLE_GATEWAY, false), probe(PROBE_ROLE_BACKEND, false), probe(PROBE_ROLE_DNS, false), probe(PROBE_ROLE_INTERNET, false)}, DIAG_LAN_DOWN},
+		{"só o gateway responde", []TargetProbe{probe(PROBE_ROLE_GATEWAY, true), probe(PROBE_ROLE_BACKEND, false), probe(PROBE_ROLE_DNS, false), probe(PROBE_ROLE_INTERNET, false)}, DIAG_WAN_DOWN},
+		{"gateway sem ping mas internet ok", []TargetProbe{probe(PROBE_ROLE_GATEWAY, false), probe(PROBE_ROLE_BACKEND, true), probe(PROBE_ROLE_DNS, true), probe(PROBE_ROLE_INTERNET, true)}, DIAG_OK},
+		{"DNS falhando", []TargetProbe{probe(PROBE_ROLE_GATEWAY, true), probe(PROBE_ROLE_BACKEND, true), probe(PROBE_ROLE_DNS, false), probe(PROBE_ROLE_INTERNET, true)}, DIAG_DNS_FAILING},
+		{"um DNS de dois responde", []TargetProbe{probe(PROBE_ROLE_GATEWAY, true), probe(PROBE_ROLE_BACKEND, true), probe(PROBE_ROLE_DNS, false), probe(PROBE_ROLE_DNS, true), probe(PROBE_ROLE_INTERNET, true)}, DIAG_OK},
+		{"sem DNS configurado", []TargetProbe{probe(PROBE_ROLE_GATEWAY, true), probe(PROBE_ROLE_BACKEND, true), probe(PROBE_ROLE_INTERNET, true)}, DIAG_OK},
+		{"backend fora", []TargetProbe{probe(PROBE_ROLE_GATEWAY, true), probe(PROBE_ROLE_BACKEND, false), probe(PROBE_ROLE_DNS, true), probe(PROBE_ROLE_INTERNET, true)}, DIAG_BACKEND_UNREACHABLE},
+		{"sem gateway padrão", []TargetProbe{probe(PROBE_ROLE_BACKEND, false), probe(PROBE_ROLE_INTERNET, false)}, DIAG_LAN_DOWN},
+	}
+	for _, c := range cases {
+		if got := diagnoseProbes(c.probes); got != c.want { t.Errorf("%s: diagnoseProbes = %s, esperado %s", c.name, got, c.want) }
+	}
+}
+
+func TestBuildProbeBatch(t *testing.T) {
+	wifi := &WiFiInfo{SSID: "Filial-01"}
+	probes := []TargetProbe{
+		{Role: PROBE_ROLE_GATEWAY, Target: "192.168.0.1", Reachable: true, Detail: "sem resposta ao ping; tcp 80 aberta", Latency: LatencyProbe{Sent: 4, LossPct: 100}},
+		{Role: PROBE_ROLE_INTERNET, Target: "8.8.8.8", Reachable: true, Latency: LatencyProbe{Sent: 4, Received: 4, AvgMS: 12.4}},
+	}
+
+	batch := buildProbeBatch(probes, DIAG_OK, wifi)
+	if batch.Diagnosis != DIAG_OK || batch.CollectedAt == "" || len(batch.Probes) != 2 { t.Fatalf("lote = %+v", batch) }
+
+	gateway, internet := batch.Probes[0], batch.Probes[1]
+	if gateway.Target != "192.168.0.1" || gateway.Role != PROBE_ROLE_GATEWAY || !gateway.Reachable || gateway.PacketLoss != 100 || gateway.Detail == "" {
+		t.Errorf("gateway = %+v", gateway)
+	}
+	if internet.LatencyMS != 12 || internet.Diagnosis != DIAG_OK || internet.WiFi != wifi || internet.MachineUUID != batch.MachineUUID {
+		t.Errorf("internet = %+v", internet)
+	}
+}
+
+func TestGatewayARPFlushesOncePerOutage(t *testing.T) {
+	state := &gatewayARPState{flushed: map[string]bool{}}
+	flushes := 0
+	flush := func(ip string) bool {
+		flushes++
+		return true
+	}
+
+	for cycle := 0; cycle < 5; cycle++ {
+		if !state.prepare("192.168.0.1", flush) { t.Fatalf("ciclo %d: tabela ARP deveria ser confiável", cycle) }
+	}
+	if flushes != 1 { t.Errorf("entrada ARP apagada %d vezes em 5 ciclos, esperado 1", flushes) }
+
+	// Outro gateway (troca de rede) tem a própria entrada
+	state.prepare("10.0.0.1", flush)
+	if flushes != 2 { t.Errorf("gateway novo: %d exclusões, esperado 2", flushes) }
+
+	// Gateway voltou a responder: a próxima queda apaga de novo
+	state.reset("192.168.0.1")
+	state.prepare("192.168.0.1", flush)
+	if flushes != 3 { t.Errorf("após nova queda: %d exclusões, esperado 3", flushes) }
+}
+
+func TestGatewayARPWithoutFlushIsNotTrusted(t *testing.T) {
+	state := &gatewayARPState{flushed: map[string]bool{}}
+	attempts := 0
+	denied := func(ip string) bool {
+		attempts++
+		return false
+	}
+
+	if state.prepare("192.168.0.1", denied) || state.prepare("192.168.0.1", denied) { t.Error("sem apagar a entrada a tabela ARP não prova nada") }
+	if attempts != 2 { t.Errorf("%d tentativas de apagar, esperado tentar de novo a cada ciclo", attempts) }
+}
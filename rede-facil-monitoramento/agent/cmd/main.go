@@ -17,16 +17,9 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
-	"syscall"
 	"time"
-	"unsafe"
 
-	"github.com/getlantern/systray"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/disk"
-	"github.com/shirou/gopsutil/v3/host"
 	"github.com/shirou/gopsutil/v3/mem"
 	gonet "github.com/shirou/gopsutil/v3/net"
 )
@@ -56,24 +49,6 @@ const (
 	MB_TOPMOST           = 0x00040000
 )
 
-const (
-	ES_CONTINUOUS       = 0x80000000
-	ES_SYSTEM_REQUIRED  = 0x00000001
-	ES_DISPLAY_REQUIRED = 0x00000002
-)
-
-var (
-	kernel32           = syscall.NewLazyDLL("kernel32.dll")
-	user32             = syscall.NewLazyDLL("user32.dll")
-	setThreadExecState = kernel32.NewProc("SetThreadExecutionState")
-	getLastInputInfo   = user32.NewProc("GetLastInputInfo")
-	messageBox         = user32.NewProc("MessageBoxW") 
-)
-
-func preventSystemSleep() {
-	setThreadExecState.Call(uintptr(ES_CONTINUOUS | ES_SYSTEM_REQUIRED))
-}
-
 var AgentSecret string = "REDE_FACIL_AGENTE_SECRETO_2026"
 var httpClient *http.Client
 
@@ -106,16 +81,19 @@ var GlobalMachineIP string
 var ShutdownCancelled bool = false
 var AutoShutdownEnabled bool = true
 
-type LASTINPUTINFO struct {
-	cbSize uint32
-	dwTime uint32
-}
-
 type NetworkInterface struct {
-	InterfaceName string `json:"interface_name"`
-	MACAddress    string `json:"mac_address"`
-	IsUp          bool   `json:"is_up"`
-	SpeedMbps     int    `json:"speed_mbps"`
+	InterfaceName     string   `json:"interface_name"`
+	MACAddress        string   `json:"mac_address"`
+	IsUp              bool     `json:"is_up"`
+	SpeedMbps         int      `json:"speed_mbps"`
+	IPv4Addresses     []string `json:"ipv4_addresses"`
+	IPv6Addresses     []string `json:"ipv6_addresses"`
+	Gateways          []string `json:"gateways"`
+	DNSServers        []string `json:"dns_servers"`
+	DHCPEnabled       bool     `json:"dhcp_enabled"`
+	DHCPServer        string   `json:"dhcp_server"`
+	DHCPLeaseObtained string   `json:"dhcp_lease_obtained"`
+	DHCPLeaseExpires  string   `json:"dhcp_lease_expires"`
 }
 
 type Software struct {
@@ -136,7 +114,10 @@ type MachineInfo struct {
 	CPUCoresLogical         int                `json:"cpu_cores_logical"`
 	RAMTotalGB              float64            `json:"ram_total_gb"`
 	DiskTotalGB             float64            `json:"disk_total_gb"`
+	Volumes                 []VolumeUsage      `json:"volumes"`
+	PhysicalDisks           []PhysicalDisk     `json:"physical_disks"`
 	MACAddress              string             `json:"mac_address"`
+	IPAddresses             []IPAddressInfo    `json:"ip_addresses"`
 	MachineModel            string             `json:"machine_model"`
 	SerialNumber            string             `json:"serial_number"`
 	MachineType             string             `json:"machine_type"`
@@ -150,7 +131,11 @@ type MachineInfo struct {
 	MemSlotsTotal           int                `json:"mem_slots_total"`
 	MemSlotsUsed            int                `json:"mem_slots_used"`
 	NetworkInterfaces       []NetworkInterface `json:"network_interfaces"`
+	Monitors                []MonitorInfo      `json:"monitors"`
+	USBDevices              []USBDevice        `json:"usb_devices"`
+	Printers                []PrinterInfo      `json:"printers"`
 	InstalledSoftware       []Software         `json:"installed_software"`
+	CollectorErrors         []CollectorStatus  `json:"collector_errors,omitempty"`
 }
 
 type TelemetryData struct {
@@ -159,17 +144,38 @@ type TelemetryData struct {
 	RamUsagePercent    float64 `json:"ram_usage_percent"`
 	DiskTotalGB        float64 `json:"disk_total_gb"`
 	DiskFreePercent    float64 `json:"disk_free_percent"`
+	Volumes            []VolumeUsage `json:"volumes"`
 	DiskSmartStatus    string  `json:"disk_smart_status"`
-	TemperatureCelsius float64 `json:"temperature_celsius"`
+	DiskHealth         []DiskHealth `json:"disk_health"`
+	TemperatureCelsius *float64 `json:"temperature_celsius"`
+	TemperatureStatus  string   `json:"temperature_status"`
+	Temperatures       []TemperatureSensor `json:"temperatures"`
 	UptimeSeconds      uint64  `json:"uptime_seconds"`
 	IdleSeconds        uint32  `json:"idle_seconds"`
+	TopProcesses       *TopProcesses      `json:"top_processes,omitempty"`
+	Battery            *BatteryReport     `json:"battery,omitempty"`
+	NetworkTraffic     []InterfaceTraffic `json:"network_traffic"`
+	WiFi               *WiFiInfo          `json:"wifi,omitempty"`
+	CollectorErrors    []CollectorStatus  `json:"collector_errors,omitempty"`
 }
 
 type NetworkStats struct {
-	MachineUUID string `json:"machine_uuid"`
-	Target      string `json:"target"`
-	LatencyMS   int    `json:"latency_ms"`
-	PacketLoss  int    `json:"packet_loss"`
+	MachineUUID     string  `json:"machine_uuid"`
+	Target          string  `json:"target"`
+	LatencyMS       int     `json:"latency_ms"`
+	PacketLoss      int     `json:"packet_loss"`
+	PacketsSent     int     `json:"packets_sent"`
+	PacketsReceived int     `json:"packets_received"`
+	MinRTTMS        float64 `json:"min_rtt_ms"`
+	AvgRTTMS        float64 `json:"avg_rtt_ms"`
+	MaxRTTMS        float64 `json:"max_rtt_ms"`
+	JitterMS        float64 `json:"jitter_ms"`
+	LossPercent     float64 `json:"loss_percent"`
+	Role            string  `json:"role"`
+	Reachable       bool    `json:"reachable"`
+	Diagnosis       string  `json:"diagnosis"`
+	Detail          string  `json:"detail"`
+	WiFi            *WiFiInfo `json:"wifi,omitempty"`
 }
 
 type RegistrationResponse struct {
@@ -178,64 +184,17 @@ type RegistrationResponse struct {
 }
 
 type ServerResponse struct {
-	Message string `json:"message"`
-	Command string `json:"command"`
-	Payload string `json:"payload"`
+	Message   string             `json:"message"`
+	Command   string             `json:"command"`
+	Payload   string             `json:"payload"`
+	CommandID string             `json:"command_id"`
+	Condition ExecutionCondition `json:"condition"`
 }
 
 type CommandResult struct {
-	Output string `json:"output"`
-	Error  string `json:"error"`
-}
-
-// Função para exibir mensagem nativa no Windows
-func showNativeMessage(title, text string, iconType uintptr) {
-	if runtime.GOOS == "windows" {
-		titlePtr, _ := syscall.UTF16PtrFromString(title)
-		textPtr, _ := syscall.UTF16PtrFromString(text)
-		messageBox.Call(
-			0, 
-			uintptr(unsafe.Pointer(textPtr)), 
-			uintptr(unsafe.Pointer(titlePtr)), 
-			iconType|MB_TOPMOST, 
-		)
-	}
-}
-
-// --- SYSTEM TRAY ---
-
-func onReady() {
-	// Verifica se o ícone foi carregado pelo embed
-	if len(iconData) > 0 {
-		systray.SetIcon(iconData)
-		log.Println("✅ Ícone definido na bandeja com sucesso.")
-	} else {
-		log.Println("❌ ERRO: Ícone não encontrado ou vazio!")
-	}
-	
-	systray.SetTitle("Rede Fácil Monitoramento")
-	systray.SetTooltip("Agente Ativo - Monitoramento e Suporte")
-
-	mRequestHelp := systray.AddMenuItem("🆘 Solicitar Suporte TI", "Chamar técnico imediatamente")
-	systray.AddSeparator()
-	mInfo := systray.AddMenuItem("✅ Monitoramento Ativo", "Sistema protegido e monitorado")
-	mInfo.Disable()
-	
-	go func() {
-		for {
-			select {
-			case <-mRequestHelp.ClickedCh:
-				log.Println("🆘 Usuário clicou em Solicitar Suporte")
-				// Roda em goroutine para não travar a interface
-				go showNativeMessage("Aguarde", "Enviando solicitação para a central de TI...", MB_ICONASTERISK)
-				sendHelpRequest()
-			}
-		}
-	}()
-}
-
-func onExit() {
-	// Limpeza
+	Output string      `json:"output"`
+	Error  string      `json:"error"`
+	Data   interface{} `json:"data,omitempty"`
 }
 
 func sendHelpRequest() {
@@ -261,9 +220,11 @@ func sendHelpRequest() {
 // --- FUNÇÕES DE SISTEMA ---
 
 func shutdownPC() {
-	log.Println("🌙 Inatividade detectada. Desligando PC...")
-	cmd := exec.Command("shutdown", "/s", "/t", "60", "/f", "/c", "Desligamento automático por inatividade.")
-	cmd.Run()
+	log.Println("🌙 Inatividade detectada. Iniciando contagem para desligar o PC...")
+	startPowerAction("shutdown", PowerRequest{
+		CountdownSeconds: 60,
+		Message:          "Desligamento automático por inatividade.",
+	}, "Inatividade após o horário limite")
 }
 
 func checkAutoShutdown() {
@@ -286,28 +247,12 @@ func checkAutoShutdown() {
 	}
 }
 
-func getIdleTime() uint32 {
-	var lii LASTINPUTINFO
-	lii.cbSize = uint32(unsafe.Sizeof(lii))
-	getLastInputInfo.Call(uintptr(unsafe.Pointer(&lii)))
-
-	kernel32 := syscall.NewLazyDLL("kernel32.dll")
-	getTickCount := kernel32.NewProc("GetTickCount")
-	t, _, _ := getTickCount.Call()
-
-	if t == 0 { return 0 }
-	return (uint32(t) - lii.dwTime) / 1000
-}
-
 func runCommandHidden(command string, args ...string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, command, args...)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: 0x08000000,
-	}
+	hideConsoleWindow(cmd)
 
 	output, err := cmd.Output()
 
@@ -319,6 +264,34 @@ func runCommandHidden(command string, args ...string) (string, error) {
 	return string(output), err
 }
 
+// Igual ao runCommandHidden, mas com timeout configurável, saída combinada e exit code
+func runCommandWithExitCode(timeout time.Duration, command string, args ...string) (string, int, error) {
+	return runCommandWithExitCodeEnv(timeout, nil, command, args...)
+}
+
+// env são variáveis extras só para o processo filho (o ambiente do agente não muda)
+func runCommandWithExitCodeEnv(timeout time.Duration, env []string, command string, args ...string) (string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	if len(env) > 0 { cmd.Env = append(os.Environ(), env...) }
+	hideConsoleWindow(cmd)
+
+	output, err := cmd.CombinedOutput()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		log.Printf("⚠️ Comando '%s' cancelado (Timeout)", command)
+		return string(output), -1, fmt.Errorf("timeout excedido")
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(output), exitErr.ExitCode(), nil
+	}
+	if err != nil { return string(output), -1, err }
+	return string(output), 0, nil
+}
+
 func ensureAutoStart() {
 	if runtime.GOOS != "windows" { return }
 	exePath, err := os.Executable()
@@ -326,7 +299,7 @@ func ensureAutoStart() {
 
 	taskName := "AgenteRedeFacil"
 	cmdTask := exec.Command("schtasks", "/create", "/tn", taskName, "/tr", exePath, "/sc", "onlogon", "/rl", "highest", "/f")
-	cmdTask.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	hideWindow(cmdTask)
 	errTask := cmdTask.Run()
 
 	if errTask == nil {
@@ -346,11 +319,26 @@ func ensureAutoStart() {
 	runCommandHidden("powershell", "-NoProfile", "-Command", psCommand)
 }
 
+// Sonda rápida de um único pacote: retorna latência (ms) e perda (%)
 func pingHost(target string) (int, int) {
-	outputStr, err := runCommandHidden("ping", "-n", "1", "-w", "1000", target)
-	if err != nil { return 0, 100 }
-	if strings.Contains(outputStr, "TTL=") { return 20, 0 }
-	return 0, 100
+	probe := measureLatency(target, 1)
+	return int(math.Round(probe.AvgMS)), int(math.Round(probe.LossPct))
+}
+
+func buildNetworkStats(target string, probe LatencyProbe) NetworkStats {
+	return NetworkStats{
+		MachineUUID:     getMachineUUID(),
+		Target:          target,
+		LatencyMS:       int(math.Round(probe.AvgMS)),
+		PacketLoss:      int(math.Round(probe.LossPct)),
+		PacketsSent:     probe.Sent,
+		PacketsReceived: probe.Received,
+		MinRTTMS:        probe.MinMS,
+		AvgRTTMS:        probe.AvgMS,
+		MaxRTTMS:        probe.MaxMS,
+		JitterMS:        probe.JitterMS,
+		LossPercent:     probe.LossPct,
+	}
 }
 
 func startNetworkMonitor() {
@@ -361,10 +349,9 @@ func startNetworkMonitor() {
 		}
 	}()
 	for {
-		lat, loss := pingHost("8.8.8.8")
-		postData("/telemetry/network", NetworkStats{
-			MachineUUID: getMachineUUID(), Target: "8.8.8.8", LatencyMS: lat, PacketLoss: loss,
-		})
+		probes, diagnosis := runFaultIsolationProbes()
+		if diagnosis != DIAG_OK { log.Printf("🌐 Diagnóstico de rede: %s", diagnosis) }
+		sendProbeBatch(buildProbeBatch(probes, diagnosis, cachedWiFiInfo()))
 		time.Sleep(30 * time.Second)
 	}
 }
@@ -426,7 +413,7 @@ func doUpdate(client *http.Client) {
 	if err != nil { return }
 
 	cmd := exec.Command("cmd", "/C", "start", "", exePath)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	hideWindow(cmd)
 	cmd.Start()
 	os.Exit(0)
 }
@@ -437,61 +424,64 @@ func getBackupFolderPath() string {
 	return filepath.Join(homeDir, "Documents", "backup_agente")
 }
 
-func execWmic(args ...string) string {
-	if runtime.GOOS != "windows" { return "N/A" }
-	var cmdArgs []string
-	if len(args) == 1 {
-		cmdArgs = strings.Fields(args[0])
-	} else {
-		cmdArgs = args
-	}
+// Identificação do hardware via WMI (BIOS, placa-mãe e produto)
+func getHardwareIdentity() (model string, serial string, mbManufacturer string, mbModel string, mbVersion string) {
+	model, serial, mbManufacturer, mbModel, mbVersion = "N/A", "N/A", "N/A", "N/A", "N/A"
 
-	output, err := runCommandHidden("wmic", cmdArgs...)
-	if err != nil { return "N/A" }
+	var products []Win32_ComputerSystemProduct
+	if err := queryWMI(&products, ""); err == nil && len(products) > 0 { model = valueOrNA(products[0].Name) }
 
-	result := strings.TrimSpace(output)
-	lines := strings.Split(result, "\n")
-	if len(lines) > 1 {
-		val := strings.TrimSpace(lines[1])
-		if val != "" { return val }
+	var bios []Win32_BIOS
+	if err := queryWMI(&bios, ""); err == nil && len(bios) > 0 { serial = valueOrNA(bios[0].SerialNumber) }
+
+	var boards []Win32_BaseBoard
+	if err := queryWMI(&boards, ""); err == nil && len(boards) > 0 {
+		mbManufacturer = valueOrNA(boards[0].Manufacturer)
+		mbModel = valueOrNA(boards[0].Product)
+		mbVersion = valueOrNA(boards[0].Version)
 	}
-	return "N/A"
+	return
+}
+
+func valueOrNA(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" { return "N/A" }
+	return value
 }
 
 func getMemorySlotsInfo() (total int, used int) {
-	if runtime.GOOS != "windows" { return 0, 0 }
-	totalVal, _ := strconv.Atoi(strings.TrimSpace(execWmic("memphysical get MemoryDevices")))
-	if totalVal > 0 { total = totalVal }
-	outStr, err := runCommandHidden("wmic", "memorychip", "get", "banklabel")
-	if err == nil {
-		lines := strings.Split(outStr, "\n")
-		for i, line := range lines {
-			if i == 0 || strings.TrimSpace(line) == "" { continue }
-			used++
-		}
+	var arrays []Win32_PhysicalMemoryArray
+	if err := queryWMI(&arrays, ""); err == nil {
+		for _, a := range arrays { total += int(a.MemoryDevices) }
 	}
+	var modules []Win32_PhysicalMemory
+	if err := queryWMI(&modules, ""); err == nil { used = len(modules) }
 	return total, used
 }
 
+// Placa de vídeo com mais memória (ignora adaptadores básicos sem VRAM quando há outra)
 func getGPUInfo() (model string, vramMB int) {
-	if runtime.GOOS != "windows" { return "N/A", 0 }
-	model = execWmic("path Win32_VideoController get Name")
-	vramStr := execWmic("path Win32_VideoController get AdapterRAM")
-	vramBytes, _ := strconv.ParseInt(vramStr, 10, 64)
-	vramMB = int(vramBytes / (1024 * 1024))
-	if vramMB < 0 { vramMB *= -1 }
-	if model == "" { model = "N/A" }
-	return model, vramMB
+	var controllers []Win32_VideoController
+	if err := queryWMI(&controllers, ""); err != nil || len(controllers) == 0 { return "N/A", 0 }
+	best := controllers[0]
+	for _, c := range controllers[1:] {
+		if c.AdapterRAM > best.AdapterRAM { best = c }
+	}
+	return valueOrNA(best.Name), int(best.AdapterRAM / (1024 * 1024))
 }
 
 func getMachineType() string {
-	if runtime.GOOS != "windows" { return "Indefinido" }
-	chassis := strings.TrimSpace(execWmic("csenclosure get chassistypes"))
-	switch chassis {
-	case "8", "9", "10", "14": return "Notebook/Laptop"
-	case "1", "2", "3", "4", "5", "6", "7": return "Desktop"
-	default: return "Desktop/Genérico"
+	var enclosures []Win32_SystemEnclosure
+	if err := queryWMI(&enclosures, ""); err != nil { return "Indefinido" }
+	for _, e := range enclosures {
+		for _, chassis := range e.ChassisTypes {
+			switch chassis {
+			case 8, 9, 10, 14, 30, 31, 32: return "Notebook/Laptop"
+			case 3, 4, 5, 6, 7, 13, 15, 35: return "Desktop"
+			}
+		}
 	}
+	return "Desktop/Genérico"
 }
 
 func collectInstalledSoftware() []Software {
@@ -515,24 +505,55 @@ func collectInstalledSoftware() []Software {
 func collectNetworkInterfaces() []NetworkInterface {
 	interfaces, err := gonet.Interfaces()
 	if err != nil { return nil }
+	speeds := getLinkSpeeds()
 	var nics []NetworkInterface
 	for _, iface := range interfaces {
 		if strings.Contains(strings.Join(iface.Flags, ","), "loopback") || iface.HardwareAddr == "" { continue }
-		nics = append(nics, NetworkInterface{
+		nic := NetworkInterface{
 			InterfaceName: iface.Name,
 			MACAddress:    iface.HardwareAddr,
 			IsUp:          strings.Contains(strings.Join(iface.Flags, ","), "up"),
-			SpeedMbps:     0,
-		})
+			SpeedMbps:     speeds[iface.Name],
+		}
+		for _, addr := range iface.Addrs {
+			ip, _, err := net.ParseCIDR(addr.Addr)
+			if err != nil { continue }
+			if ip.To4() != nil {
+				nic.IPv4Addresses = append(nic.IPv4Addresses, addr.Addr)
+			} else {
+				nic.IPv6Addresses = append(nic.IPv6Addresses, addr.Addr)
+			}
+		}
+		nics = append(nics, nic)
 	}
 	return nics
 }
 
+// IP de origem que o sistema usa na rota até o backend (IPv4 ou IPv6).
+// O "dial" UDP só consulta a tabela de rotas, nenhum pacote é enviado.
 func getLocalIP() string {
-	conn, err := net.Dial("udp", "8.8.8.8:80")
-	if err != nil { return "127.0.0.1" }
-	defer conn.Close()
-	return conn.LocalAddr().(*net.UDPAddr).IP.String()
+	host, port := backendHostPort()
+	if host != "" {
+		conn, err := net.Dial("udp", net.JoinHostPort(host, port))
+		if err == nil {
+			defer conn.Close()
+			if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsLoopback() { return addr.IP.String() }
+		}
+	}
+
+	// Sem rota até o backend: primeiro endereço global de uma interface ativa, preferindo IPv4
+	var fallbackV6 string
+	for _, nic := range collectNetworkInterfaces() {
+		if !nic.IsUp { continue }
+		for _, cidr := range append(append([]string{}, nic.IPv4Addresses...), nic.IPv6Addresses...) {
+			ip, _, err := net.ParseCIDR(cidr)
+			if err != nil || !ip.IsGlobalUnicast() { continue }
+			if ip.To4() != nil { return ip.String() }
+			if fallbackV6 == "" { fallbackV6 = ip.String() }
+		}
+	}
+	if fallbackV6 != "" { return fallbackV6 }
+	return "127.0.0.1"
 }
 
 func getMachineUUID() string {
@@ -555,100 +576,139 @@ func getLastRestorePoint() string {
 	return strings.TrimSpace(string(content))
 }
 
+// Gateway e máscara da interface principal (a que tem o IP local)
 func getNetworkDetails() (gateway string, mask string) {
-	if runtime.GOOS != "windows" { return "N/A", "N/A" }
-	outStr, err := runCommandHidden("wmic", "nicconfig", "where", "IPEnabled=true and DefaultIPGateway is not null", "get", "DefaultIPGateway,IPSubnet")
-	if err != nil { return "N/A", "N/A" }
-	lines := strings.Split(strings.TrimSpace(outStr), "\n")
-	if len(lines) > 1 {
-		vals := strings.Fields(lines[1])
-		if len(vals) >= 2 {
-			return strings.Trim(vals[0], "{\""), strings.Trim(vals[1], "{\"")
+	return networkDetailsFrom(collectNetworkInventory())
+}
+
+func networkDetailsFrom(nics []NetworkInterface) (gateway string, mask string) {
+	gateway, mask = "N/A", "N/A"
+	primary := primaryInterface(nics)
+	if primary == nil { return gateway, mask }
+
+	for _, gw := range primary.Gateways {
+		if net.ParseIP(gw).To4() != nil {
+			gateway = gw
+			break
 		}
 	}
-	return "N/A", "N/A"
+	if gateway == "N/A" && len(primary.Gateways) > 0 { gateway = primary.Gateways[0] }
+
+	localIP := getLocalIP()
+	for _, cidr := range primary.IPv4Addresses {
+		if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.String() == localIP { mask = subnetMaskFromCIDR(cidr) }
+	}
+	return gateway, mask
 }
 
+// Monta o registro a partir dos coletores (cache ou coleta paralela dos vencidos)
 func collectStaticInfo() MachineInfo {
 	defer func() {
 		if r := recover(); r != nil { log.Printf("⚠️ Erro static info: %v", r) }
 	}()
-	hInfo, _ := host.Info()
-	mInfo, _ := mem.VirtualMemory()
-	cInfos, _ := cpu.Info()
-	
-	cpuModel := "N/A"
-	var cpuSpeed float64
-	if len(cInfos) > 0 {
-		cpuModel = cInfos[0].ModelName
-		cpuSpeed = cInfos[0].Mhz
-	}
-	
-	cpuCoresPhysical, _ := cpu.Counts(false)
-	cpuCoresLogical, _ := cpu.Counts(true)
-	
-	diskTotalGB := 0.0
-	dUsage, err := disk.Usage("C:")
-	if err == nil {
-		diskTotalGB = float64(dUsage.Total) / (1024*1024*1024)
-	}
+	results := gatherCollectors(staticCollectors)
 
-	return MachineInfo{
+	info := MachineInfo{
 		UUID:                    getMachineUUID(),
-		Hostname:                hInfo.Hostname,
 		IPAddress:               getLocalIP(),
 		DefaultGateway:          "N/A",
 		SubnetMask:              "N/A",
-		OSName:                  fmt.Sprintf("%s %s", hInfo.OS, hInfo.Platform),
-		CPUModel:                cpuModel,
-		CPUSpeedMhz:             cpuSpeed,
-		CPUCoresPhysical:        cpuCoresPhysical,
-		CPUCoresLogical:         cpuCoresLogical,
-		RAMTotalGB:              float64(mInfo.Total) / (1024 * 1024 * 1024),
-		DiskTotalGB:             diskTotalGB,
+		CPUModel:                "N/A",
 		MACAddress:              "00:00:00:00:00:00",
-		MachineModel:            execWmic("csproduct get name"),
-		SerialNumber:            execWmic("bios get serialnumber"),
-		MachineType:             getMachineType(),
-		MotherboardManufacturer: execWmic("baseboard get manufacturer"),
-		MotherboardModel:        execWmic("baseboard get product"),
-		MotherboardVersion:      execWmic("baseboard get version"),
-		GPUModel:                "N/A", 
-		GPUVRAMMB:               0,
-		LastBootTime:            time.Unix(int64(hInfo.BootTime), 0).Format("2006-01-02 15:04:05"),
-		LastRestorePoint:        getLastRestorePoint(),
-		MemSlotsTotal:           0,
-		MemSlotsUsed:            0,
-		NetworkInterfaces:       collectNetworkInterfaces(),
-		InstalledSoftware:       collectInstalledSoftware(),
-	}
+		MachineModel:            "N/A",
+		SerialNumber:            "N/A",
+		MachineType:             "Indefinido",
+		MotherboardManufacturer: "N/A",
+		MotherboardModel:        "N/A",
+		MotherboardVersion:      "N/A",
+		GPUModel:                "N/A",
+		LastRestorePoint:        "N/A",
+		InstalledSoftware:       []Software{},
+		CollectorErrors:         collectorFailures(results),
+	}
+
+	if sys, ok := collectorValue[systemSnapshot](results, "system"); ok {
+		info.Hostname = sys.Host.Hostname
+		info.OSName = fmt.Sprintf("%s %s", sys.Host.OS, sys.Host.Platform)
+		info.LastBootTime = time.Unix(int64(sys.Host.BootTime), 0).Format("2006-01-02 15:04:05")
+		info.CPUModel = sys.CPUModel
+		info.CPUSpeedMhz = sys.CPUSpeedMhz
+		info.CPUCoresPhysical = sys.CPUCoresPhysical
+		info.CPUCoresLogical = sys.CPUCoresLogical
+		info.RAMTotalGB = float64(sys.RAMTotal) / (1024 * 1024 * 1024)
+	}
+
+	if volumes, ok := collectorValue[[]VolumeUsage](results, "volumes"); ok {
+		info.Volumes = volumes
+		if system := systemVolume(volumes); system != nil { info.DiskTotalGB = system.TotalGB }
+	}
+	if disks, ok := collectorValue[[]PhysicalDisk](results, "physical_disks"); ok { info.PhysicalDisks = disks }
+
+	if hw, ok := collectorValue[hardwareSnapshot](results, "hardware"); ok {
+		info.MachineModel = hw.MachineModel
+		info.SerialNumber = hw.SerialNumber
+		info.MachineType = hw.MachineType
+		info.MotherboardManufacturer = hw.MBManufacturer
+		info.MotherboardModel = hw.MBModel
+		info.MotherboardVersion = hw.MBVersion
+		info.GPUModel = hw.GPUModel
+		info.GPUVRAMMB = hw.GPUVRAMMB
+		info.MemSlotsTotal = hw.MemSlotsTotal
+		info.MemSlotsUsed = hw.MemSlotsUsed
+	}
+
+	if nics, ok := collectorValue[[]NetworkInterface](results, "network_inventory"); ok {
+		info.DefaultGateway, info.SubnetMask = networkDetailsFrom(nics)
+		if primary := primaryInterface(nics); primary != nil { info.MACAddress = primary.MACAddress }
+		info.IPAddresses = collectIPAddresses(nics)
+		info.NetworkInterfaces = nics
+	}
+
+	if monitors, ok := collectorValue[[]MonitorInfo](results, "monitors"); ok { info.Monitors = monitors }
+	if usbDevices, ok := collectorValue[[]USBDevice](results, "usb_devices"); ok { info.USBDevices = usbDevices }
+	if printers, ok := collectorValue[[]PrinterInfo](results, "printers"); ok { info.Printers = printers }
+	if software, ok := collectorValue[[]Software](results, "software"); ok { info.InstalledSoftware = software }
+	if restorePoint, ok := collectorValue[string](results, "restore_point"); ok { info.LastRestorePoint = restorePoint }
+	return info
 }
 
 func collectTelemetry() TelemetryData {
 	defer func() {
 		if r := recover(); r != nil { log.Printf("⚠️ Erro telemetria: %v", r) }
 	}()
+	results := gatherCollectors(telemetryCollectors)
 
-	cpuPercent, _ := cpu.Percent(1*time.Second, false)
-	cpuValue := 0.0
-	if len(cpuPercent) > 0 { cpuValue = cpuPercent[0] }
+	cpuValue, _ := collectorValue[float64](results, "cpu")
 
-	v, _ := mem.VirtualMemory()
 	ramValue := 0.0
-	if v != nil { ramValue = v.UsedPercent }
+	if v, ok := collectorValue[*mem.VirtualMemoryStat](results, "memory"); ok && v != nil { ramValue = v.UsedPercent }
 
-	d, err := disk.Usage("C:")
 	diskFreePct := 0.0
 	diskTotal := 0.0
-	if err == nil && d != nil && d.Total > 0 {
-		diskFreePct = (float64(d.Free) / float64(d.Total)) * 100.0
-		diskTotal = float64(d.Total) / (1024*1024*1024)
+	volumes, ok := collectorValue[[]VolumeUsage](results, "volumes")
+	if !ok { volumes = []VolumeUsage{} }
+	if system := systemVolume(volumes); system != nil {
+		diskFreePct = system.FreePercent
+		diskTotal = system.TotalGB
 	}
 
-	tempValue := 40.0 + (cpuValue * 0.3)
+	temps, ok := collectorValue[TemperatureReport](results, "temperature")
+	if !ok { temps = TemperatureReport{Status: TEMPERATURE_STATUS_UNAVAILABLE, Sensors: []TemperatureSensor{}} }
+
 	uptime := uint64(0)
-	hInfo, _ := host.Info()
-	if hInfo != nil { uptime = hInfo.Uptime }
+	if sys, ok := collectorValue[systemSnapshot](results, "system"); ok && uint64(time.Now().Unix()) > sys.Host.BootTime {
+		uptime = uint64(time.Now().Unix()) - sys.Host.BootTime
+	}
+
+	diskHealth, ok := collectorValue[[]DiskHealth](results, "disk_health")
+	if !ok { diskHealth = []DiskHealth{} }
+
+	var topProcesses *TopProcesses
+	if top, ok := collectorValue[TopProcesses](results, "processes"); ok { topProcesses = topProcessesForTelemetry(top, cpuValue, ramValue) }
+
+	battery, _ := collectorValue[*BatteryReport](results, "battery")
+	traffic, _ := collectorValue[[]InterfaceTraffic](results, "traffic")
+	wifi, _ := collectorValue[*WiFiInfo](results, "wifi")
 
 	return TelemetryData{
 		MachineUUID:        getMachineUUID(),
@@ -656,16 +716,30 @@ func collectTelemetry() TelemetryData {
 		RamUsagePercent:    math.Round(ramValue*10) / 10,
 		DiskTotalGB:        math.Round(diskTotal),
 		DiskFreePercent:    math.Round(diskFreePct*10) / 10,
-		DiskSmartStatus:    "OK",
-		TemperatureCelsius: math.Round(tempValue*10) / 10,
+		Volumes:            volumes,
+		DiskSmartStatus:    overallDiskHealth(diskHealth),
+		DiskHealth:         diskHealth,
+		TemperatureCelsius: temps.CPUCelsius,
+		TemperatureStatus:  temps.Status,
+		Temperatures:       temps.Sensors,
 		UptimeSeconds:      uptime,
 		IdleSeconds:        getIdleTime(),
+		TopProcesses:       topProcesses,
+		Battery:            battery,
+		NetworkTraffic:     traffic,
+		WiFi:               wifi,
+		CollectorErrors:    collectorFailures(results),
 	}
 }
 
 func sendCommandResult(output string, errorMsg string) {
+	sendCommandResultData(output, errorMsg, nil)
+}
+
+// Igual ao sendCommandResult, com dados estruturados para o dashboard
+func sendCommandResultData(output string, errorMsg string, data interface{}) {
 	url := fmt.Sprintf("%s/machines/%s/command-result", API_BASE_URL, getMachineUUID())
-	payload := CommandResult{ Output: output, Error:  errorMsg }
+	payload := CommandResult{ Output: output, Error: errorMsg, Data: data }
 	jsonValue, _ := json.Marshal(payload)
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
@@ -701,14 +775,10 @@ func handleRemoteCommand(command string, payload string) {
 	log.Printf("⚠️ COMANDO RECEBIDO: %s", command)
 
 	switch command {
-	case "shutdown":
-		if runtime.GOOS == "windows" {
-			runCommandHidden("shutdown", "/s", "/t", "0", "/f")
-		}
-	case "restart":
-		if runtime.GOOS == "windows" {
-			runCommandHidden("shutdown", "/r", "/t", "0", "/f")
-		}
+	case "shutdown", "restart":
+		handlePowerCommand(command, payload)
+	case "cancel_power_action":
+		cancelPowerAction()
 	case "clean_temp":
 		if runtime.GOOS == "windows" {
 			runCommandHidden("cmd", "/C", "del /q /f /s %TEMP%\\*")
@@ -753,10 +823,7 @@ func handleRemoteCommand(command string, payload string) {
 
 		go func(script string) {
 			cmd := exec.Command("powershell", "-NoProfile", "-WindowStyle", "Hidden", "-Command", script)
-			cmd.SysProcAttr = &syscall.SysProcAttr{
-				HideWindow:    true,
-				CreationFlags: 0x08000000,
-			}
+			hideConsoleWindow(cmd)
 			err := cmd.Run()
 			if err != nil {
 				log.Printf("❌ Erro ao executar script de wallpaper: %v", err)
@@ -770,6 +837,24 @@ func handleRemoteCommand(command string, payload string) {
 	case "custom_script":
 		go runPowerShellScript(payload)
 
+	case "install_package", "uninstall_package":
+		go handlePackageCommand(command, payload)
+
+	case "wake_peers":
+		go handleWakePeers(payload)
+
+	case "discover_subnet":
+		go handleDiscoverSubnet(payload)
+
+	case "set_service_checks":
+		handleSetServiceChecks(payload)
+
+	case "set_process_policy":
+		handleSetProcessPolicy(payload)
+
+	case "net_diag":
+		go handleNetDiag(payload)
+
 	default:
 		log.Printf("❓ Comando desconhecido: %s", command)
 	}
@@ -778,31 +863,48 @@ func postData(endpoint string, data interface{}) {
 	jsonValue, err := json.Marshal(data)
 	if err != nil { return }
 
+	for i := 0; i < MAX_RETRIES; i++ {
+		if postJSON(endpoint, jsonValue) == nil { return }
+		if i < MAX_RETRIES-1 { time.Sleep(RETRY_DELAY) }
+	}
+}
+
+// Uma única tentativa de envio; executa o comando que vier na resposta do servidor
+func postJSON(endpoint string, jsonValue []byte) error {
 	url := fmt.Sprintf("%s%s", API_BASE_URL, endpoint)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
+	if err != nil { return err }
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agent-secret", AgentSecret)
+	resp, err := httpClient.Do(req)
+	recordBackendResult(resp, err)
+	if err != nil { return err }
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 { return fmt.Errorf("status %d", resp.StatusCode) }
 
-	for i := 0; i < MAX_RETRIES; i++ {
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonValue))
-		if err != nil { return }
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("x-agent-secret", AgentSecret)
-		resp, err := httpClient.Do(req)
-		if err != nil {
-			if i < MAX_RETRIES-1 { time.Sleep(RETRY_DELAY) }
-			continue
+	body, _ := io.ReadAll(resp.Body)
+	var serverResp ServerResponse
+	if err := json.Unmarshal(body, &serverResp); err == nil {
+		if serverResp.Command != "" {
+			dispatchServerCommand(serverResp)
 		}
-		defer resp.Body.Close()
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			body, _ := io.ReadAll(resp.Body)
-			var serverResp ServerResponse
-			if err := json.Unmarshal(body, &serverResp); err == nil {
-				if serverResp.Command != "" {
-					handleRemoteCommand(serverResp.Command, serverResp.Payload)
-				}
-			}
-			return
-		}
-		if i < MAX_RETRIES-1 { time.Sleep(RETRY_DELAY) }
 	}
+	return nil
+}
+
+var registrationRequests = make(chan struct{}, 1)
+
+// Pede um novo registro. Pedidos feitos enquanto outro ainda está pendente viram um só,
+// então nunca há mais de um laço de registro tentando ao mesmo tempo.
+func requestRegistration() {
+	select {
+	case registrationRequests <- struct{}{}:
+	default:
+	}
+}
+
+func startRegistrationLoop() {
+	for range registrationRequests { registerMachine() }
 }
 
 func registerMachine() {
@@ -817,6 +919,7 @@ func registerMachine() {
 			req.Header.Set("x-agent-secret", AgentSecret)
 			
 			resp, err := httpClient.Do(req)
+			recordBackendResult(resp, err)
 			if err == nil {
 				defer resp.Body.Close()
 				if resp.StatusCode >= 200 && resp.StatusCode < 300 {
@@ -846,9 +949,14 @@ func main() {
 	ensureAutoStart()
 	preventSystemSleep()
 
-	go registerMachine()
+	startCollectorScheduler()
+	requestRegistration()
+	go startRegistrationLoop()
 	go checkForUpdates()
 	go startNetworkMonitor()
+	go startCommandScheduler()
+	go startServiceChecks()
+	go startNetworkWatcher()
 
 	go func() {
 		for {
@@ -864,5 +972,5 @@ func main() {
 		}
 	}()
 
-	systray.Run(onReady, onExit)
+	runTray()
 }
\ No newline at end of file
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package main
+
+import (
+	"log"
+	"os/exec"
+)
+
+// Fora do Windows não há janelas nativas nem controle de suspensão: o agente roda sem interface
+
+func preventSystemSleep() {}
+
+func showNativeMessage(title, text string, iconType uintptr) {
+	log.Printf("💬 %s: %s", title, text)
+}
+
+// Sem usuário para responder, nada é confirmado
+func askNativeYesNo(title, text string, iconType uintptr) bool {
+	return false
+}
+
+// Sem API de última entrada: a máquina nunca é considerada ociosa
+func getIdleTime() uint32 {
+	return 0
+}
+
+// Consoles fora do Windows já escrevem em UTF-8
+func oemCodePage() uint32 {
+	return 65001
+}
+
+func hideWindow(cmd *exec.Cmd) {}
+
+func hideConsoleWindow(cmd *exec.Cmd) {}
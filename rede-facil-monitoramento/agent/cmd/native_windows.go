@@ -0,0 +1,95 @@
+//go:build windows
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// Chamadas nativas do Windows (user32/kernel32). Fora do Windows ficam em native_other.go.
+
+const (
+	MB_YESNO = 0x00000004
+	IDYES    = 6
+)
+
+const (
+	ES_CONTINUOUS       = 0x80000000
+	ES_SYSTEM_REQUIRED  = 0x00000001
+	ES_DISPLAY_REQUIRED = 0x00000002
+)
+
+const CREATE_NO_WINDOW = 0x08000000
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	user32             = syscall.NewLazyDLL("user32.dll")
+	setThreadExecState = kernel32.NewProc("SetThreadExecutionState")
+	getLastInputInfo   = user32.NewProc("GetLastInputInfo")
+	getTickCount       = kernel32.NewProc("GetTickCount")
+	getOEMCP           = kernel32.NewProc("GetOEMCP")
+	messageBox         = user32.NewProc("MessageBoxW")
+)
+
+type LASTINPUTINFO struct {
+	cbSize uint32
+	dwTime uint32
+}
+
+func preventSystemSleep() {
+	setThreadExecState.Call(uintptr(ES_CONTINUOUS | ES_SYSTEM_REQUIRED))
+}
+
+// Função para exibir mensagem nativa no Windows
+func showNativeMessage(title, text string, iconType uintptr) {
+	callMessageBox(title, text, iconType|MB_TOPMOST)
+}
+
+// Janela Sim/Não; true quando o usuário escolhe "Sim"
+func askNativeYesNo(title, text string, iconType uintptr) bool {
+	return callMessageBox(title, text, MB_YESNO|iconType|MB_TOPMOST) == IDYES
+}
+
+func callMessageBox(title, text string, flags uintptr) uintptr {
+	titlePtr, _ := syscall.UTF16PtrFromString(title)
+	textPtr, _ := syscall.UTF16PtrFromString(text)
+	ret, _, _ := messageBox.Call(
+		0,
+		uintptr(unsafe.Pointer(textPtr)),
+		uintptr(unsafe.Pointer(titlePtr)),
+		flags,
+	)
+	return ret
+}
+
+func getIdleTime() uint32 {
+	var lii LASTINPUTINFO
+	lii.cbSize = uint32(unsafe.Sizeof(lii))
+	getLastInputInfo.Call(uintptr(unsafe.Pointer(&lii)))
+
+	t, _, _ := getTickCount.Call()
+
+	if t == 0 { return 0 }
+	return (uint32(t) - lii.dwTime) / 1000
+}
+
+// Code page OEM do console (usado para decodificar a saída do netsh)
+func oemCodePage() uint32 {
+	cp, _, _ := getOEMCP.Call()
+	return uint32(cp)
+}
+
+// Processo filho sem janela visível
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
+
+// Processo filho de console (powershell, netsh...) sem janela e sem console próprio
+func hideConsoleWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: CREATE_NO_WINDOW,
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/hex"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type IPAddressInfo struct {
+	Address        string `json:"address"`
+	PrefixLength   int    `json:"prefix_length"`
+	Family         string `json:"family"`
+	InterfaceName  string `json:"interface_name"`
+	ReachesBackend bool   `json:"reaches_backend"`
+}
+
+// Todos os endereços das interfaces, marcando o que o sistema usa para alcançar o backend
+func collectIPAddresses(nics []NetworkInterface) []IPAddressInfo {
+	routeIP := getLocalIP()
+	var list []IPAddressInfo
+	for _, nic := range nics {
+		for _, cidr := range append(append([]string{}, nic.IPv4Addresses...), nic.IPv6Addresses...) {
+			ip, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil { continue }
+			prefix, _ := ipNet.Mask.Size()
+			family := "ipv6"
+			if ip.To4() != nil { family = "ipv4" }
+			list = append(list, IPAddressInfo{
+				Address:        ip.String(),
+				PrefixLength:   prefix,
+				Family:         family,
+				InterfaceName:  nic.InterfaceName,
+				ReachesBackend: ip.String() == routeIP,
+			})
+		}
+	}
+	return list
+}
+
+// Interfaces com gateways, DNS e dados de DHCP preenchidos (mais lento que collectNetworkInterfaces)
+func collectNetworkInventory() []NetworkInterface {
+	nics := collectNetworkInterfaces()
+	if runtime.GOOS == "windows" {
+		enrichWindowsNetworkConfig(nics)
+	} else {
+		enrichLinuxNetworkConfig(nics)
+	}
+	return nics
+}
+
+// Interface que possui o IP usado para falar com o backend
+func primaryInterface(nics []NetworkInterface) *NetworkInterface {
+	localIP := getLocalIP()
+	for i := range nics {
+		for _, cidr := range append(append([]string{}, nics[i].IPv4Addresses...), nics[i].IPv6Addresses...) {
+			ip, _, err := net.ParseCIDR(cidr)
+			if err == nil && ip.String() == localIP { return &nics[i] }
+		}
+	}
+	return nil
+}
+
+// Máscara em notação decimal do primeiro IPv4 (ex: 192.168.0.10/24 -> 255.255.255.0)
+func subnetMaskFromCIDR(cidr string) string {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil || len(ipNet.Mask) != net.IPv4len { return "N/A" }
+	return net.IP(ipNet.Mask).String()
+}
+
+func enrichWindowsNetworkConfig(nics []NetworkInterface) {
+	var configs []Win32_NetworkAdapterConfiguration
+	if err := queryWMI(&configs, "IPEnabled = TRUE"); err != nil { return }
+
+	byMAC := map[string]Win32_NetworkAdapterConfiguration{}
+	for _, c := range configs { byMAC[normalizeMAC(c.MACAddress)] = c }
+	for i := range nics {
+		c, ok := byMAC[normalizeMAC(nics[i].MACAddress)]
+		if !ok { continue }
+		nics[i].Gateways = c.DefaultIPGateway
+		nics[i].DNSServers = c.DNSServerSearchOrder
+		nics[i].DHCPEnabled = c.DHCPEnabled
+		if c.DHCPEnabled {
+			nics[i].DHCPServer = c.DHCPServer
+			if !c.DHCPLeaseObtained.IsZero() { nics[i].DHCPLeaseObtained = c.DHCPLeaseObtained.Format("2006-01-02T15:04:05") }
+			if !c.DHCPLeaseExpires.IsZero() { nics[i].DHCPLeaseExpires = c.DHCPLeaseExpires.Format("2006-01-02T15:04:05") }
+		}
+	}
+}
+
+func enrichLinuxNetworkConfig(nics []NetworkInterface) {
+	gateways := linuxGatewaysByInterface()
+	dns := getDNSServers()
+
+	for i := range nics {
+		name := nics[i].InterfaceName
+		nics[i].Gateways = gateways[name]
+		nics[i].DNSServers = dns
+
+		// "ip addr" marca endereços obtidos por DHCP como "dynamic" e informa o tempo restante da concessão
+		output, err := runCommandHidden("ip", "-o", "-4", "addr", "show", "dev", name)
+		if err != nil { continue }
+		fields := strings.Fields(output)
+		for j, f := range fields {
+			if f == "dynamic" { nics[i].DHCPEnabled = true }
+			if f == "valid_lft" && j+1 < len(fields) {
+				secs, err := strconv.Atoi(strings.TrimSuffix(fields[j+1], "sec"))
+				if err == nil { nics[i].DHCPLeaseExpires = time.Now().Add(time.Duration(secs) * time.Second).Format("2006-01-02T15:04:05") }
+			}
+		}
+		if !nics[i].DHCPEnabled {
+			nics[i].DHCPLeaseExpires = ""
+			continue
+		}
+
+		if _, err := exec.LookPath("nmcli"); err != nil { continue }
+		nmOut, err := runCommandHidden("nmcli", "-t", "-f", "DHCP4", "device", "show", name)
+		if err != nil { continue }
+		for _, line := range strings.Split(nmOut, "\n") {
+			if idx := strings.Index(line, "dhcp_server_identifier = "); idx >= 0 {
+				nics[i].DHCPServer = strings.TrimSpace(line[idx+len("dhcp_server_identifier = "):])
+			}
+		}
+	}
+}
+
+// Gateways IPv4 e IPv6 por interface a partir da tabela de rotas do kernel
+func linuxGatewaysByInterface() map[string][]string {
+	result := map[string][]string{}
+
+	if data, err := os.ReadFile("/proc/net/route"); err == nil {
+		for _, line := range strings.Split(string(data), "\n")[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 3 || fields[1] != "00000000" { continue }
+			raw, err := hex.DecodeString(fields[2])
+			if err != nil || len(raw) != 4 { continue }
+			gw := net.IPv4(raw[3], raw[2], raw[1], raw[0]).String()
+			result[fields[0]] = appendUniqueIP(result[fields[0]], gw)
+		}
+	}
+
+	// /proc/net/ipv6_route: destino ::/0 com next hop diferente de zero
+	if data, err := os.ReadFile("/proc/net/ipv6_route"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 10 || fields[0] != strings.Repeat("0", 32) || fields[1] != "00" { continue }
+			raw, err := hex.DecodeString(fields[4])
+			if err != nil || len(raw) != net.IPv6len || net.IP(raw).IsUnspecified() { continue }
+			result[fields[9]] = appendUniqueIP(result[fields[9]], net.IP(raw).String())
+		}
+	}
+	return result
+}
+
+func normalizeMAC(mac string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(mac), "-", ":"))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSubnetMaskFromCIDR(t *testing.T) {
+	cases := []struct{ cidr, want string }{
+		{"192.168.0.10/24", "255.255.255.0"},
+		{"10.1.2.3/22", "255.255.252.0"},
+		{"172.16.0.1/32", "255.255.255.255"},
+		{"fe80::1/64", "N/A"},
+		{"192.168.0.10", "N/A"},
+	}
+	for _, c := range cases {
+		if got := subnetMaskFromCIDR(c.cidr); got != c.want { t.Errorf("subnetMaskFromCIDR(%s) = %s, esperado %s", c.cidr, got, c.want) }
+	}
+}
+
+func TestNormalizeMAC(t *testing.T) {
+	cases := []struct{ mac, want string }{
+		{"00-1A-2b-3C-4d-5E", "00:1A:2B:3C:4D:5E"},
+		{" aa:bb:cc:dd:ee:ff\r\n", "AA:BB:CC:DD:EE:FF"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := normalizeMAC(c.mac); got != c.want { t.Errorf("normalizeMAC(%q) = %q, esperado %q", c.mac, got, c.want) }
+	}
+}
+
+func TestCollectIPAddresses(t *testing.T) {
+	nics := []NetworkInterface{
+		{InterfaceName: "Ethernet", IPv4Addresses: []string{"192.0.2.10/24"}, IPv6Addresses: []string{"fe80::1/64", "inválido"}},
+		{InterfaceName: "Wi-Fi", IPv4Addresses: []string{"198.51.100.7/22"}},
+	}
+	got := collectIPAddresses(nics)
+	want := []IPAddressInfo{
+		{Address: "192.0.2.10", PrefixLength: 24, Family: "ipv4", InterfaceName: "Ethernet"},
+		{Address: "fe80::1", PrefixLength: 64, Family: "ipv6", InterfaceName: "Ethernet"},
+		{Address: "198.51.100.7", PrefixLength: 22, Family: "ipv4", InterfaceName: "Wi-Fi"},
+	}
+	if len(got) != len(want) { t.Fatalf("collectIPAddresses = %+v", got) }
+	for i := range want {
+		if got[i] != want[i] { t.Errorf("endereço %d = %+v, esperado %+v", i, got[i], want[i]) }
+	}
+}
+
+func TestPrimaryInterface(t *testing.T) {
+	local := net.ParseIP(getLocalIP())
+	if local == nil || local.To4() == nil { t.Skip("sem IPv4 local para comparar") }
+
+	nics := []NetworkInterface{
+		{InterfaceName: "VPN", IPv4Addresses: []string{"192.0.2.10/24"}},
+		{InterfaceName: "Ethernet", IPv4Addresses: []string{local.String() + "/24"}},
+	}
+	if primary := primaryInterface(nics); primary == nil || primary.InterfaceName != "Ethernet" { t.Errorf("primaryInterface = %+v", primary) }
+	if primary := primaryInterface(nics[:1]); primary != nil { t.Errorf("primaryInterface sem o IP local = %+v", primary) }
+}
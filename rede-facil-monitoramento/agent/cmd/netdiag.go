@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TRACEROUTE_MAX_HOPS = 30
+const TRACEROUTE_PROBES = 3
+const TRACEROUTE_TIMEOUT = 3 * time.Minute
+
+// Faixa de payload ICMP testada na descoberta de MTU (payload + 28 bytes de cabeçalho = MTU)
+const MTU_MIN_PAYLOAD = 548
+const MTU_MAX_PAYLOAD = 1472
+const ICMP_IPV4_HEADERS = 28
+
+var hopRTTPattern = regexp.MustCompile(`(<?)(\d+(?:[.,]\d+)?)\s*ms`)
+
+// Payload do comando net_diag. Também aceita uma lista simples de alvos separados por vírgula.
+type NetDiagRequest struct {
+	Targets   []string `json:"targets"`
+	Protocol  string   `json:"protocol"` // "icmp" (padrão) ou "udp" (apenas Linux)
+	MaxHops   int      `json:"max_hops"`
+	SkipMTU   bool     `json:"skip_mtu"`
+	DNSServer string   `json:"dns_server"`
+}
+
+type TraceHop struct {
+	Hop      int       `json:"hop"`
+	IP       string    `json:"ip"`
+	RTTs     []float64 `json:"rtts_ms"`
+	AvgRTTMS float64   `json:"avg_rtt_ms"`
+	LossPct  float64   `json:"loss_percent"`
+}
+
+type DNSLookupResult struct {
+	Server    string   `json:"server"`
+	Addresses []string `json:"addresses"`
+	CNAME     string   `json:"cname"`
+	TimeMS    float64  `json:"time_ms"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type NetDiagTarget struct {
+	Target     string          `json:"target"`
+	Protocol   string          `json:"protocol"`
+	Hops       []TraceHop      `json:"hops"`
+	Reached    bool            `json:"reached"`
+	PathMTU    int             `json:"path_mtu"`
+	DNS        DNSLookupResult `json:"dns"`
+	TraceError string          `json:"trace_error,omitempty"`
+}
+
+type NetDiagResult struct {
+	Targets []NetDiagTarget `json:"targets"`
+}
+
+func handleNetDiag(payload string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado no net_diag: %v", r)
+			sendCommandResult("", fmt.Sprintf("Erro interno: %v", r))
+		}
+	}()
+
+	var req NetDiagRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		req = NetDiagRequest{}
+		for _, t := range strings.Split(payload, ",") {
+			if t = strings.TrimSpace(t); t != "" { req.Targets = append(req.Targets, t) }
+		}
+	}
+	if len(req.Targets) == 0 {
+		sendCommandResult("", "Nenhum alvo informado")
+		return
+	}
+	if req.MaxHops <= 0 || req.MaxHops > 64 { req.MaxHops = TRACEROUTE_MAX_HOPS }
+	req.Protocol = strings.ToLower(req.Protocol)
+	if req.Protocol != "udp" || runtime.GOOS == "windows" { req.Protocol = "icmp" }
+
+	var result NetDiagResult
+	var summary []string
+	for _, target := range req.Targets {
+		log.Printf("🧭 net_diag para %s (%s)", target, req.Protocol)
+		diag := NetDiagTarget{Target: target, Protocol: req.Protocol}
+		diag.DNS = lookupTarget(target, req.DNSServer)
+
+		hops, err := traceroute(target, req.Protocol, req.MaxHops)
+		diag.Hops = hops
+		if err != nil { diag.TraceError = err.Error() }
+		if len(hops) > 0 {
+			last := hops[len(hops)-1]
+			for _, addr := range diag.DNS.Addresses {
+				if addr == last.IP { diag.Reached = true }
+			}
+			if last.IP == target { diag.Reached = true }
+		}
+
+		if !req.SkipMTU { diag.PathMTU = discoverPathMTU(target) }
+		result.Targets = append(result.Targets, diag)
+		summary = append(summary, fmt.Sprintf("%s: %d salto(s), MTU %d, alcançado=%v", target, len(diag.Hops), diag.PathMTU, diag.Reached))
+	}
+
+	sendCommandResultData(strings.Join(summary, "\n"), "", result)
+}
+
+func traceroute(target string, protocol string, maxHops int) ([]TraceHop, error) {
+	var output string
+	var err error
+	if runtime.GOOS == "windows" {
+		output, _, err = runCommandWithExitCode(TRACEROUTE_TIMEOUT, "tracert", "-d", "-w", "1000", "-h", strconv.Itoa(maxHops), target)
+	} else {
+		args := []string{"-n", "-q", strconv.Itoa(TRACEROUTE_PROBES), "-w", "1", "-m", strconv.Itoa(maxHops)}
+		if protocol == "icmp" { args = append(args, "-I") }
+		output, _, err = runCommandWithExitCode(TRACEROUTE_TIMEOUT, "traceroute", append(args, target)...)
+	}
+	if err != nil { return nil, err }
+	return parseTraceroute(output), nil
+}
+
+// Interpreta a saída do tracert (Windows) e do traceroute -n (Linux) em qualquer idioma:
+// cada linha de salto começa com o número do salto, seguido de tempos "N ms" ou "*" e do IP.
+func parseTraceroute(output string) []TraceHop {
+	var hops []TraceHop
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 { continue }
+		hopNum, err := strconv.Atoi(fields[0])
+		if err != nil { continue }
+
+		hop := TraceHop{Hop: hopNum}
+		stars := 0
+		for _, f := range fields[1:] {
+			if f == "*" { stars++ }
+		}
+		if ip := ipv4Pattern.FindString(strings.Join(fields[1:], " ")); ip != "" { hop.IP = ip }
+		for _, match := range hopRTTPattern.FindAllStringSubmatch(line, -1) {
+			rtt, err := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", "."), 64)
+			if err != nil { continue }
+			// Como no ping, "<1 ms" do tracert é abaixo da resolução e conta como 0
+			if match[1] == "<" { rtt = 0 }
+			hop.RTTs = append(hop.RTTs, rtt)
+		}
+
+		probes := len(hop.RTTs) + stars
+		if probes == 0 { probes = TRACEROUTE_PROBES }
+		hop.LossPct = float64(int(float64(probes-len(hop.RTTs))/float64(probes)*1000)) / 10
+		if len(hop.RTTs) > 0 {
+			sum := 0.0
+			for _, rtt := range hop.RTTs { sum += rtt }
+			hop.AvgRTTMS = float64(int(sum/float64(len(hop.RTTs))*10)) / 10
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// Busca binária pelo maior payload que passa com o bit "não fragmentar"
+func discoverPathMTU(target string) int {
+	if !mtuProbe(target, MTU_MIN_PAYLOAD) { return 0 }
+	low, high := MTU_MIN_PAYLOAD, MTU_MAX_PAYLOAD
+	for low < high {
+		mid := (low + high + 1) / 2
+		if mtuProbe(target, mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low + ICMP_IPV4_HEADERS
+}
+
+func mtuProbe(target string, payloadSize int) bool {
+	var args []string
+	if runtime.GOOS == "windows" {
+		args = []string{"-f", "-l", strconv.Itoa(payloadSize), "-n", "1", "-w", "1000", target}
+	} else {
+		args = []string{"-M", "do", "-s", strconv.Itoa(payloadSize), "-c", "1", "-W", "1", target}
+	}
+	output, _, err := runCommandWithExitCode(10*time.Second, "ping", args...)
+	return err == nil && len(parsePingReplies(output)) > 0
+}
+
+func lookupTarget(target string, dnsServer string) DNSLookupResult {
+	result := DNSLookupResult{Server: "sistema"}
+	if net.ParseIP(target) != nil {
+		result.Addresses = []string{target}
+		return result
+	}
+
+	resolver := net.DefaultResolver
+	if dnsServer != "" {
+		result.Server = dnsServer
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "udp", net.JoinHostPort(dnsServer, "53"))
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DNS_PROBE_TIMEOUT)
+	defer cancel()
+	start := time.Now()
+	addrs, err := resolver.LookupHost(ctx, target)
+	result.TimeMS = msSince(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Addresses = addrs
+	if cname, err := resolver.LookupCNAME(ctx, target); err == nil { result.CNAME = strings.TrimSuffix(cname, ".") }
+	return result
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTraceroute(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   []TraceHop
+	}{
+		{
+			"tracert pt-BR",
+			"\r\nRastreando a rota para 8.8.8.8 com no máximo 30 saltos\r\n\r\n  1    <1 ms    <1 ms    <1 ms  192.168.0.1\r\n  2     8 ms     7 ms     9 ms  100.64.0.1\r\n  3     *        *        *     Esgotado o tempo limite do pedido.\r\n  4    12 ms     *       14 ms  8.8.8.8\r\n\r\nRastreamento concluído.\r\n",
+			[]TraceHop{
+				{Hop: 1, IP: "192.168.0.1", RTTs: []float64{0, 0, 0}, AvgRTTMS: 0, LossPct: 0},
+				{Hop: 2, IP: "100.64.0.1", RTTs: []float64{8, 7, 9}, AvgRTTMS: 8, LossPct: 0},
+				{Hop: 3, LossPct: 100},
+				{Hop: 4, IP: "8.8.8.8", RTTs: []float64{12, 14}, AvgRTTMS: 13, LossPct: 33.3},
+			},
+		},
+		{
+			"traceroute -n do Linux com balanceamento",
+			"traceroute to 1.1.1.1 (1.1.1.1), 30 hops max, 60 byte packets\n 1  192.168.0.1  0.512 ms  0.433 ms  0.401 ms\n 2  * * *\n 3  10.10.0.1  5.123 ms 10.10.0.2  6.456 ms  5.789 ms\n",
+			[]TraceHop{
+				{Hop: 1, IP: "192.168.0.1", RTTs: []float64{0.512, 0.433, 0.401}, AvgRTTMS: 0.4, LossPct: 0},
+				{Hop: 2, LossPct: 100},
+				{Hop: 3, IP: "10.10.0.1", RTTs: []float64{5.123, 6.456, 5.789}, AvgRTTMS: 5.7, LossPct: 0},
+			},
+		},
+		{"sem saltos", "traceroute: unknown host servidor.invalido\n", nil},
+	}
+	for _, c := range cases {
+		if got := parseTraceroute(c.output); !reflect.DeepEqual(got, c.want) { t.Errorf("%s: parseTraceroute = %+v\nesperado %+v", c.name, got, c.want) }
+	}
+}
+
+func TestLookupTargetIP(t *testing.T) {
+	result := lookupTarget("10.0.0.1", "")
+	if result.Server != "sistema" || !reflect.DeepEqual(result.Addresses, []string{"10.0.0.1"}) || result.Error != "" { t.Errorf("lookupTarget(IP) = %+v", result) }
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const NETWORK_PROBE_COUNT = 5
+const NETWORK_PROBE_TIMEOUT_MS = 1000
+
+// Linhas de resposta do ping são identificadas pelo tempo ("time=9.84 ms", "tempo<1ms", "Zeit=3ms"...),
+// não pelo TTL: as respostas IPv6 do ping do Windows não trazem "TTL=".
+var pingReplyRTT = regexp.MustCompile(`(?i)\b(?:time|tempo|tiempo|zeit|temps)\s*([=<])\s*(\d+(?:[.,]\d+)?)\s*ms`)
+
+type LatencyProbe struct {
+	Sent     int       `json:"packets_sent"`
+	Received int       `json:"packets_received"`
+	RTTs     []float64 `json:"-"`
+	MinMS    float64   `json:"min_rtt_ms"`
+	AvgMS    float64   `json:"avg_rtt_ms"`
+	MaxMS    float64   `json:"max_rtt_ms"`
+	JitterMS float64   `json:"jitter_ms"`
+	LossPct  float64   `json:"loss_percent"`
+}
+
+// Envia count pacotes ICMP via ping do sistema e calcula RTT mínimo/médio/máximo, jitter e perda
+func measureLatency(target string, count int) LatencyProbe {
+	if count <= 0 { count = 1 }
+
+	var args []string
+	if runtime.GOOS == "windows" {
+		args = []string{"-n", strconv.Itoa(count), "-w", strconv.Itoa(NETWORK_PROBE_TIMEOUT_MS), target}
+	} else {
+		args = []string{"-c", strconv.Itoa(count), "-i", "0.2", "-W", strconv.Itoa(int(math.Ceil(NETWORK_PROBE_TIMEOUT_MS / 1000.0))), target}
+	}
+
+	timeout := time.Duration(count)*(NETWORK_PROBE_TIMEOUT_MS*time.Millisecond+time.Second) + 5*time.Second
+	output, _, err := runCommandWithExitCode(timeout, "ping", args...)
+	probe := LatencyProbe{Sent: count}
+	if err == nil { probe.RTTs = parsePingReplies(output) }
+	probe.summarize()
+	return probe
+}
+
+func parsePingReplies(output string) []float64 {
+	var rtts []float64
+	for _, line := range strings.Split(output, "\n") {
+		match := pingReplyRTT.FindStringSubmatch(line)
+		if match == nil { continue }
+		rtt, err := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", "."), 64)
+		if err != nil { continue }
+		// O ping do Windows não tem resolução abaixo de 1 ms: "<1ms" conta como 0, não como 1
+		if match[1] == "<" { rtt = 0 }
+		rtts = append(rtts, rtt)
+	}
+	return rtts
+}
+
+func (p *LatencyProbe) summarize() {
+	p.Received = len(p.RTTs)
+	if p.Received > p.Sent { p.Received = p.Sent }
+	if p.Sent > 0 { p.LossPct = math.Round(float64(p.Sent-p.Received)/float64(p.Sent)*1000) / 10 }
+	if len(p.RTTs) == 0 {
+		p.LossPct = 100
+		return
+	}
+
+	p.MinMS, p.MaxMS = p.RTTs[0], p.RTTs[0]
+	sum := 0.0
+	for _, rtt := range p.RTTs {
+		sum += rtt
+		p.MinMS = math.Min(p.MinMS, rtt)
+		p.MaxMS = math.Max(p.MaxMS, rtt)
+	}
+	p.AvgMS = math.Round(sum/float64(len(p.RTTs))*10) / 10
+
+	// Jitter: média da variação entre respostas consecutivas
+	if len(p.RTTs) > 1 {
+		diffs := 0.0
+		for i := 1; i < len(p.RTTs); i++ { diffs += math.Abs(p.RTTs[i] - p.RTTs[i-1]) }
+		p.JitterMS = math.Round(diffs/float64(len(p.RTTs)-1)*10) / 10
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePingReplies(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   []float64
+	}{
+		{
+			"Windows pt-BR na LAN",
+			"\r\nDisparando 192.168.0.1 com 32 bytes de dados:\r\nResposta de 192.168.0.1: bytes=32 tempo<1ms TTL=64\r\nResposta de 192.168.0.1: bytes=32 tempo=2ms TTL=64\r\nResposta de 192.168.0.1: bytes=32 tempo<1ms TTL=64\r\n\r\nEstatísticas do Ping para 192.168.0.1:\r\n    Pacotes: Enviados = 3, Recebidos = 3, Perdidos = 0 (0% de perda),\r\nAproximar um número redondo de vezes em milissegundos:\r\n    Mínimo = 0ms, Máximo = 2ms, Média = 0ms\r\n",
+			[]float64{0, 2, 0},
+		},
+		{
+			"Windows en-US com timeout",
+			"Pinging 8.8.8.8 with 32 bytes of data:\r\nReply from 8.8.8.8: bytes=32 time=14ms TTL=117\r\nRequest timed out.\r\nReply from 8.8.8.8: bytes=32 time=15ms TTL=117\r\n",
+			[]float64{14, 15},
+		},
+		{
+			"Windows IPv6 sem TTL",
+			"Disparando 2001:4860:4860::8888 com 32 bytes de dados:\r\nResposta de 2001:4860:4860::8888: tempo=12ms\r\nResposta de 2001:4860:4860::8888: tempo<1ms\r\nEsgotado o tempo limite do pedido.\r\n",
+			[]float64{12, 0},
+		},
+		{
+			"Windows en-US IPv6",
+			"Pinging fe80::1%12 with 32 bytes of data:\r\nReply from fe80::1%12: time<1ms\r\nReply from fe80::1%12: time=3ms\r\n\r\nApproximate round trip times in milli-seconds:\r\n    Minimum = 0ms, Maximum = 3ms, Average = 1ms\r\n",
+			[]float64{0, 3},
+		},
+		{
+			"Windows: destino inacessível não é resposta",
+			"Resposta de 192.168.0.10: Host de destino inacessível.\r\n",
+			nil,
+		},
+		{
+			"Linux iputils",
+			"PING 1.1.1.1 (1.1.1.1) 56(84) bytes of data.\n64 bytes from 1.1.1.1: icmp_seq=1 ttl=57 time=9.84 ms\n64 bytes from 1.1.1.1: icmp_seq=2 ttl=57 time=0.412 ms\n\n--- 1.1.1.1 ping statistics ---\nrtt min/avg/max/mdev = 0.412/5.126/9.840/4.714 ms\n",
+			[]float64{9.84, 0.412},
+		},
+		{
+			"separador decimal com vírgula",
+			"64 bytes de 10.0.0.1: icmp_seq=1 ttl=64 tempo=0,731 ms\n",
+			[]float64{0.731},
+		},
+	}
+	for _, c := range cases {
+		if got := parsePingReplies(c.output); !reflect.DeepEqual(got, c.want) { t.Errorf("%s: parsePingReplies = %v, esperado %v", c.name, got, c.want) }
+	}
+}
+
+func TestLatencySummarize(t *testing.T) {
+	probe := LatencyProbe{Sent: 4, RTTs: []float64{0, 2, 0}}
+	probe.summarize()
+	want := LatencyProbe{Sent: 4, Received: 3, RTTs: []float64{0, 2, 0}, MinMS: 0, AvgMS: 0.7, MaxMS: 2, JitterMS: 2, LossPct: 25}
+	if !reflect.DeepEqual(probe, want) { t.Errorf("summarize = %+v\nesperado %+v", probe, want) }
+
+	lost := LatencyProbe{Sent: 4}
+	lost.summarize()
+	if lost.Received != 0 || lost.LossPct != 100 || lost.AvgMS != 0 { t.Errorf("sem respostas = %+v", lost) }
+}
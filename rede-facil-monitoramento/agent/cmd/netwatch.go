@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+const NETWORK_WATCH_INTERVAL = 15 * time.Second
+const NETWORK_FULL_CHECK_INTERVAL = 2 * time.Minute
+const NETWORK_SETTLE_DELAY = 10 * time.Second
+
+// Estado da rede comparado entre verificações (sem tempos de concessão, que mudam a cada renovação)
+type NetworkSnapshot struct {
+	IPAddress      string             `json:"ip_address"`
+	DefaultGateway string             `json:"default_gateway"`
+	SubnetMask     string             `json:"subnet_mask"`
+	Interfaces     []NetworkInterface `json:"interfaces"`
+}
+
+type NetworkChangeEvent struct {
+	MachineUUID string          `json:"machine_uuid"`
+	DetectedAt  string          `json:"detected_at"`
+	Changes     []string        `json:"changes"`
+	Before      NetworkSnapshot `json:"before"`
+	After       NetworkSnapshot `json:"after"`
+}
+
+func startNetworkWatcher() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado no monitor de mudanças de rede: %v", r)
+			time.Sleep(NETWORK_WATCH_INTERVAL)
+			go startNetworkWatcher()
+		}
+	}()
+
+	current := takeNetworkSnapshot(true)
+	lastFull := time.Now()
+	for {
+		time.Sleep(NETWORK_WATCH_INTERVAL)
+
+		// Verificação rápida (IPs e interfaces) a cada ciclo; gateway e DNS só periodicamente
+		quick := takeNetworkSnapshot(false)
+		quickChanged := quickNetworkKey(quick) != quickNetworkKey(current)
+		if !quickChanged && time.Since(lastFull) < NETWORK_FULL_CHECK_INTERVAL { continue }
+
+		if quickChanged { time.Sleep(NETWORK_SETTLE_DELAY) }
+		next := takeNetworkSnapshot(true)
+		lastFull = time.Now()
+
+		changes := diffNetworkSnapshots(current, next)
+		if len(changes) == 0 { continue }
+
+		log.Printf("🔀 Mudança de rede detectada: %s", strings.Join(changes, "; "))
+		postData("/telemetry/network-change", NetworkChangeEvent{
+			MachineUUID: getMachineUUID(),
+			DetectedAt:  time.Now().Format(time.RFC3339),
+			Changes:     changes,
+			Before:      current,
+			After:       next,
+		})
+		current = next
+		invalidateCollector("network_inventory")
+		requestRegistration()
+	}
+}
+
+func takeNetworkSnapshot(full bool) NetworkSnapshot {
+	snap := NetworkSnapshot{IPAddress: getLocalIP()}
+	if !full {
+		snap.Interfaces = collectNetworkInterfaces()
+		return snap
+	}
+	snap.Interfaces = collectNetworkInventory()
+	snap.DefaultGateway, snap.SubnetMask = networkDetailsFrom(snap.Interfaces)
+	return snap
+}
+
+func quickNetworkKey(snap NetworkSnapshot) string {
+	var parts []string
+	for _, nic := range snap.Interfaces {
+		parts = append(parts, fmt.Sprintf("%s|%v|%s|%s", nic.InterfaceName, nic.IsUp, sortedJoin(nic.IPv4Addresses), sortedJoin(stableIPv6Prefixes(nic.IPv6Addresses))))
+	}
+	sort.Strings(parts)
+	return stableAddress(snap.IPAddress) + "#" + strings.Join(parts, "#")
+}
+
+func diffNetworkSnapshots(before, after NetworkSnapshot) []string {
+	var changes []string
+	if stableAddress(before.IPAddress) != stableAddress(after.IPAddress) { changes = append(changes, fmt.Sprintf("IP: %s -> %s", before.IPAddress, after.IPAddress)) }
+	if before.DefaultGateway != after.DefaultGateway { changes = append(changes, fmt.Sprintf("Gateway: %s -> %s", before.DefaultGateway, after.DefaultGateway)) }
+	if before.SubnetMask != after.SubnetMask { changes = append(changes, fmt.Sprintf("Máscara: %s -> %s", before.SubnetMask, after.SubnetMask)) }
+
+	oldNics := map[string]NetworkInterface{}
+	for _, nic := range before.Interfaces { oldNics[nic.InterfaceName] = nic }
+	newNics := map[string]NetworkInterface{}
+	for _, nic := range after.Interfaces { newNics[nic.InterfaceName] = nic }
+
+	for name, nic := range newNics {
+		old, ok := oldNics[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("Interface adicionada: %s", name))
+			continue
+		}
+		if old.IsUp != nic.IsUp { changes = append(changes, fmt.Sprintf("%s: ativa %v -> %v", name, old.IsUp, nic.IsUp)) }
+		if sortedJoin(old.IPv4Addresses) != sortedJoin(nic.IPv4Addresses) || sortedJoin(stableIPv6Prefixes(old.IPv6Addresses)) != sortedJoin(stableIPv6Prefixes(nic.IPv6Addresses)) {
+			changes = append(changes, fmt.Sprintf("%s: endereços alterados", name))
+		}
+		if sortedJoin(old.Gateways) != sortedJoin(nic.Gateways) { changes = append(changes, fmt.Sprintf("%s: gateways alterados", name)) }
+		if sortedJoin(old.DNSServers) != sortedJoin(nic.DNSServers) { changes = append(changes, fmt.Sprintf("%s: DNS alterado", name)) }
+		if old.DHCPServer != nic.DHCPServer { changes = append(changes, fmt.Sprintf("%s: servidor DHCP %s -> %s", name, old.DHCPServer, nic.DHCPServer)) }
+	}
+	for name := range oldNics {
+		if _, ok := newNics[name]; !ok { changes = append(changes, fmt.Sprintf("Interface removida: %s", name)) }
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// IPv6 entra na comparação só pelo prefixo da rede (até /64). Endereços temporários de privacidade
+// trocam o identificador de interface sozinhos ao longo do dia e link-local não muda com a rede;
+// comparados inteiros, geravam eventos de mudança e novos registros sem nada ter mudado.
+func stableIPv6Prefixes(cidrs []string) []string {
+	seen := map[string]bool{}
+	var prefixes []string
+	for _, cidr := range cidrs {
+		ip, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil { continue }
+		if prefix := ipv6NetworkPrefix(ip, ipNet.Mask); prefix != "" && !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// IP usado para falar com o backend: IPv4 como está, IPv6 pelo prefixo da rede
+func stableAddress(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() != nil { return address }
+	return ipv6NetworkPrefix(ip, net.CIDRMask(64, 128))
+}
+
+// Vazio para IPv4, link-local, loopback e multicast
+func ipv6NetworkPrefix(ip net.IP, mask net.IPMask) string {
+	if ip.To4() != nil || ip.IsLinkLocalUnicast() || ip.IsLoopback() || ip.IsMulticast() { return "" }
+	ones, _ := mask.Size()
+	if ones > 64 || ones == 0 { ones = 64 }
+	network := &net.IPNet{IP: ip.Mask(net.CIDRMask(ones, 128)), Mask: net.CIDRMask(ones, 128)}
+	return network.String()
+}
+
+func sortedJoin(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffNetworkSnapshots(t *testing.T) {
+	base := func() NetworkSnapshot {
+		return NetworkSnapshot{
+			IPAddress:      "192.168.0.10",
+			DefaultGateway: "192.168.0.1",
+			SubnetMask:     "255.255.255.0",
+			Interfaces: []NetworkInterface{
+				{InterfaceName: "Ethernet", IsUp: true, IPv4Addresses: []string{"192.168.0.10/24"}, IPv6Addresses: []string{"2804:14c:65a1:8000::1a2b/64", "2804:14c:65a1:8000:5c4f:2e1a:b3d0:91f7/64", "fe80::1c2d:3e4f:5a6b:7c8d/64"}, Gateways: []string{"192.168.0.1"}, DNSServers: []string{"8.8.8.8", "1.1.1.1"}, DHCPServer: "192.168.0.1"},
+				{InterfaceName: "Wi-Fi", IsUp: false},
+			},
+		}
+	}
+
+	cases := []struct {
+		name   string
+		change func(s *NetworkSnapshot)
+		want   []string
+	}{
+		{"sem mudança", func(s *NetworkSnapshot) {}, nil},
+		{"só a ordem do DNS mudou", func(s *NetworkSnapshot) { s.Interfaces[0].DNSServers = []string{"1.1.1.1", "8.8.8.8"} }, nil},
+		{"só a concessão DHCP renovou", func(s *NetworkSnapshot) { s.Interfaces[0].DHCPLeaseExpires = "2026-10-20T08:00:00" }, nil},
+		{
+			"novo IP por DHCP",
+			func(s *NetworkSnapshot) {
+				s.IPAddress = "192.168.0.42"
+				s.Interfaces[0].IPv4Addresses = []string{"192.168.0.42/24"}
+			},
+			[]string{"Ethernet: endereços alterados", "IP: 192.168.0.10 -> 192.168.0.42"},
+		},
+		{
+			"troca de roteador",
+			func(s *NetworkSnapshot) {
+				s.DefaultGateway = "10.0.0.1"
+				s.SubnetMask = "255.255.252.0"
+				s.Interfaces[0].Gateways = []string{"10.0.0.1"}
+				s.Interfaces[0].DHCPServer = "10.0.0.1"
+			},
+			[]string{"Ethernet: gateways alterados", "Ethernet: servidor DHCP 192.168.0.1 -> 10.0.0.1", "Gateway: 192.168.0.1 -> 10.0.0.1", "Máscara: 255.255.255.0 -> 255.255.252.0"},
+		},
+		{
+			"Wi-Fi ligado e VPN conectada",
+			func(s *NetworkSnapshot) {
+				s.Interfaces[1].IsUp = true
+				s.Interfaces = append(s.Interfaces, NetworkInterface{InterfaceName: "VPN", IsUp: true})
+			},
+			[]string{"Interface adicionada: VPN", "Wi-Fi: ativa false -> true"},
+		},
+		{"interface removida", func(s *NetworkSnapshot) { s.Interfaces = s.Interfaces[:1] }, []string{"Interface removida: Wi-Fi"}},
+		{
+			"IPv6 temporário trocado",
+			func(s *NetworkSnapshot) { s.Interfaces[0].IPv6Addresses = []string{"2804:14c:65a1:8000::1a2b/64", "2804:14c:65a1:8000:9d1e:44ff:aa10:7c31/64", "fe80::1c2d:3e4f:5a6b:7c8d/64"} },
+			nil,
+		},
+		{
+			"prefixo IPv6 novo da operadora",
+			func(s *NetworkSnapshot) { s.Interfaces[0].IPv6Addresses = []string{"2804:14c:77b0:1200::1a2b/64", "fe80::1c2d:3e4f:5a6b:7c8d/64"} },
+			[]string{"Ethernet: endereços alterados"},
+		},
+	}
+	for _, c := range cases {
+		after := base()
+		c.change(&after)
+		if got := diffNetworkSnapshots(base(), after); !reflect.DeepEqual(got, c.want) { t.Errorf("%s: diffNetworkSnapshots = %q, esperado %q", c.name, got, c.want) }
+	}
+}
+
+func TestQuickNetworkKey(t *testing.T) {
+	snap := NetworkSnapshot{
+		IPAddress: "192.168.0.10",
+		Interfaces: []NetworkInterface{
+			{InterfaceName: "Ethernet", IsUp: true, IPv4Addresses: []string{"192.168.0.10/24", "10.0.0.5/8"}},
+			{InterfaceName: "Wi-Fi"},
+		},
+	}
+	reordered := NetworkSnapshot{
+		IPAddress: "192.168.0.10",
+		Interfaces: []NetworkInterface{
+			{InterfaceName: "Wi-Fi"},
+			{InterfaceName: "Ethernet", IsUp: true, IPv4Addresses: []string{"10.0.0.5/8", "192.168.0.10/24"}, DNSServers: []string{"8.8.8.8"}},
+		},
+	}
+	if quickNetworkKey(snap) != quickNetworkKey(reordered) { t.Error("ordem das interfaces e dos endereços não deveria mudar a chave") }
+
+	reordered.Interfaces[0].IsUp = true
+	if quickNetworkKey(snap) == quickNetworkKey(reordered) { t.Error("interface ativada deveria mudar a chave") }
+}
+
+func TestQuickNetworkKeyIgnoresTemporaryIPv6(t *testing.T) {
+	snap := func(ipv6 ...string) NetworkSnapshot {
+		return NetworkSnapshot{IPAddress: "192.168.0.10", Interfaces: []NetworkInterface{{InterfaceName: "Wi-Fi", IsUp: true, IPv4Addresses: []string{"192.168.0.10/24"}, IPv6Addresses: ipv6}}}
+	}
+	base := snap("2804:14c:65a1:8000:5c4f:2e1a:b3d0:91f7/64", "fe80::aa:bb/64")
+	cases := []struct {
+		name    string
+		after   NetworkSnapshot
+		changed bool
+	}{
+		{"temporário rotacionado", snap("2804:14c:65a1:8000:d3a9:11c2:7e08:4b5a/64", "fe80::aa:bb/64"), false},
+		{"temporário informado como /128", snap("2804:14c:65a1:8000:d3a9:11c2:7e08:4b5a/128", "fe80::aa:bb/64"), false},
+		{"link-local trocado", snap("2804:14c:65a1:8000:5c4f:2e1a:b3d0:91f7/64", "fe80::cc:dd/64"), false},
+		{"prefixo novo", snap("2804:14c:77b0:1200:5c4f:2e1a:b3d0:91f7/64", "fe80::aa:bb/64"), true},
+		{"IPv6 perdido", snap("fe80::aa:bb/64"), true},
+	}
+	for _, c := range cases {
+		if changed := quickNetworkKey(base) != quickNetworkKey(c.after); changed != c.changed { t.Errorf("%s: mudança = %v, esperado %v", c.name, changed, c.changed) }
+	}
+}
+
+func TestStableAddress(t *testing.T) {
+	cases := []struct{ address, want string }{
+		{"192.168.0.10", "192.168.0.10"},
+		{"2804:14c:65a1:8000:5c4f:2e1a:b3d0:91f7", "2804:14c:65a1:8000::/64"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := stableAddress(c.address); got != c.want { t.Errorf("stableAddress(%q) = %q, esperado %q", c.address, got, c.want) }
+	}
+}
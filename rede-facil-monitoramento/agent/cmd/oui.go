@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Prefixos OUI dos fabricantes mais comuns nas filiais. Não é a base completa do IEEE (dezenas de
+// milhares de prefixos); o que não estiver aqui aparece como "Desconhecido".
+var ouiPrefixes = map[string][]string{
+	// Rede: roteadores, switches, firewalls e access points
+	"Cisco": {
+		"00:00:0C", "00:01:42", "00:01:43", "00:01:64", "00:1B:54", "00:22:55", "00:26:0B", "58:97:BD", "70:10:5C",
+		"F4:CF:E2",
+	},
+	"Cisco Meraki": {"00:18:0A", "0C:8D:DB", "88:15:44", "E0:55:3D"},
+	"Aruba": {
+		"00:0B:86", "00:1A:1E", "00:24:6C", "04:BD:88", "18:64:72", "20:4C:03", "24:DE:C6", "6C:F3:7F", "94:B4:0F",
+		"9C:1C:12", "AC:A3:1E", "D8:C7:C8",
+	},
+	"Juniper Networks":   {"00:05:85", "2C:6B:F5", "3C:61:04", "54:E0:32", "78:FE:3D", "84:18:88"},
+	"Fortinet":           {"00:09:0F", "08:5B:0E", "70:4C:A5", "90:6C:AC", "E8:1C:BA"},
+	"SonicWall":          {"00:17:C5", "18:B1:69", "2C:B8:ED", "C0:EA:E4"},
+	"Palo Alto Networks": {"00:1B:17"},
+	"MikroTik": {
+		"00:0C:42", "2C:C8:1B", "48:8F:5A", "4C:5E:0C", "64:D1:54", "6C:3B:6B", "74:4D:28", "B8:69:F4", "CC:2D:E0",
+		"D4:CA:6D", "DC:2C:6E", "E4:8D:8C",
+	},
+	"Ubiquiti": {
+		"00:15:6D", "00:27:22", "04:18:D6", "18:E8:29", "24:5A:4C", "24:A4:3C", "44:D9:E7", "68:72:51", "74:83:C2",
+		"74:AC:B9", "78:8A:20", "80:2A:A8", "B4:FB:E4", "DC:9F:DB", "E0:63:DA", "F0:9F:C2", "FC:EC:DA",
+	},
+	"TP-Link": {
+		"00:1D:0F", "00:27:19", "10:FE:ED", "14:CC:20", "18:A6:F7", "30:B5:C2", "50:C7:BF", "54:E6:FC", "60:E3:27",
+		"64:70:02", "90:F6:52", "98:DA:C4", "A4:2B:B0", "B0:4E:26", "C0:4A:00", "C4:6E:1F", "E8:DE:27", "EC:08:6B",
+		"F4:F2:6D", "F8:1A:67",
+	},
+	"D-Link": {
+		"00:05:5D", "00:0D:88", "00:11:95", "00:13:46", "00:15:E9", "00:17:9A", "00:19:5B", "00:1B:11", "00:1C:F0",
+		"00:1E:58", "00:21:91", "00:22:B0", "00:24:01", "00:26:5A", "14:D6:4D", "1C:7E:E5", "28:10:7B", "34:08:04",
+		"5C:D9:98", "78:54:2E", "84:C9:B2", "90:94:E4", "B8:A3:86", "C8:BE:19", "CC:B2:55", "F0:7D:68", "FC:75:16",
+	},
+	"Netgear": {
+		"00:09:5B", "00:0F:B5", "00:14:6C", "00:18:4D", "00:1B:2F", "00:1E:2A", "00:1F:33", "00:22:3F", "00:24:B2",
+		"00:26:F2", "20:4E:7F", "28:C6:8E", "2C:B0:5D", "30:46:9A", "84:1B:5E", "A0:04:60", "C0:3F:0E", "E0:46:9A",
+		"E0:91:F5",
+	},
+	"Tenda":     {"C8:3A:35"},
+	"Intelbras": {"00:1A:3F"},
+	"Huawei": {
+		"00:18:82", "00:1E:10", "00:25:9E", "00:9A:CD", "00:E0:FC", "20:F3:A3", "28:6E:D4", "48:46:FB", "70:72:3C",
+		"80:B6:86", "88:53:D4", "AC:E2:15", "E0:24:7F", "F4:C7:14",
+	},
+	"ZTE": {"00:19:C6", "00:1E:73", "00:22:93", "00:25:12"},
+
+	// Computadores e placas de rede
+	"Hewlett Packard": {
+		"00:01:E6", "00:01:E7", "00:08:02", "00:0B:CD", "00:0E:7F", "00:0F:20", "00:11:0A", "00:11:85", "00:12:79",
+		"00:13:21", "00:14:38", "00:15:60", "00:16:35", "00:17:08", "00:18:FE", "00:19:BB", "00:1A:4B", "00:1B:78",
+		"00:1C:C4", "00:1E:0B", "00:1F:29", "00:21:5A", "00:22:64", "00:23:7D", "00:24:81", "00:25:B3", "00:26:55",
+		"10:1F:74", "2C:44:FD", "3C:D9:2B", "94:57:A5", "9C:8E:99", "A0:D3:C1", "B4:99:BA", "D4:85:64", "EC:B1:D7",
+	},
+	"Dell": {
+		"00:06:5B", "00:08:74", "00:0B:DB", "00:0D:56", "00:0F:1F", "00:11:43", "00:12:3F", "00:13:72", "00:14:22",
+		"00:15:C5", "00:18:8B", "00:19:B9", "00:1A:A0", "00:1C:23", "00:1D:09", "00:1E:4F", "00:21:70", "00:22:19",
+		"00:23:AE", "00:24:E8", "00:25:64", "00:26:B9", "14:FE:B5", "18:03:73", "B8:AC:6F", "D4:BE:D9", "F8:B1:56",
+	},
+	"Lenovo (LCFC)": {"28:D2:44", "50:7B:9D", "54:E1:AD", "8C:8C:AA", "98:FA:9B", "E8:6A:64"},
+	"ASUS": {
+		"00:0C:6E", "00:0E:A6", "00:11:2F", "00:11:D8", "00:13:D4", "00:15:F2", "00:17:31", "00:18:F3", "00:1A:92",
+		"00:1B:FC", "00:1D:60", "00:1E:8C", "00:1F:C6", "00:22:15", "00:23:54", "00:24:8C", "00:26:18", "04:92:26",
+		"08:60:6E", "10:BF:48", "14:DA:E9", "1C:87:2C", "2C:56:DC", "30:85:A9", "38:D5:47", "40:16:7E", "50:46:5D",
+		"54:04:A6", "60:45:CB", "74:D0:2B", "AC:22:0B", "BC:EE:7B", "D8:50:E6", "F0:79:59", "F4:6D:04",
+	},
+	"Hon Hai (Foxconn)": {"00:1C:25", "00:22:68"},
+	"Intel": {
+		"00:02:B3", "00:03:47", "00:07:E9", "00:0E:0C", "00:13:E8", "00:15:17", "00:16:76", "00:19:D1", "00:1B:21",
+		"00:1C:C0", "00:1E:67", "00:1F:3B", "00:21:6A", "00:24:D7", "00:27:10", "3C:A9:F4", "48:51:B7", "68:05:CA",
+		"7C:7A:91", "80:86:F2", "A0:36:9F", "A4:4E:31", "B4:96:91", "F8:16:54",
+	},
+	"Realtek":  {"00:E0:4C"},
+	"Broadcom": {"00:10:18"},
+
+	// Celulares, tablets e dispositivos pessoais
+	"Apple":   {"00:03:93", "00:0A:95", "00:1C:B3", "28:CF:E9", "3C:07:54", "A4:5E:60", "AC:BC:32", "F0:18:98"},
+	"Samsung": {"00:00:F0", "00:12:FB", "00:15:99", "00:16:32"},
+	"LG Electronics": {
+		"00:1C:62", "00:1E:75", "00:1F:6B", "00:1F:E3", "00:22:A9", "00:26:E2", "10:68:3F", "20:21:A5", "34:FC:EF",
+		"58:A2:B5", "88:C9:D0", "A8:16:B2", "C4:9A:02", "CC:FA:00", "F8:0C:F3",
+	},
+	"Xiaomi": {
+		"00:9E:C8", "14:F6:5A", "28:6C:07", "34:80:B3", "50:8F:4C", "64:09:80", "64:B4:73", "78:02:F8", "7C:1D:D9",
+		"8C:BE:BE", "9C:99:A0", "F8:A4:5F",
+	},
+	"Motorola": {"5C:51:88", "60:BE:B5", "9C:D9:17"},
+	"Google":   {"3C:5A:B4", "54:60:09", "F4:F5:D8", "F8:8F:CA"},
+	"Amazon":   {"0C:47:C9", "44:65:0D", "68:54:FD", "74:C2:46", "FC:65:DE"},
+
+	// Impressoras, etiquetadoras e maquininhas
+	"Seiko Epson": {
+		"00:00:48", "00:26:AB", "38:1A:52", "44:D2:44", "64:EB:8C", "9C:AE:D3", "A4:EE:57", "AC:18:26", "B0:E8:92",
+		"DC:CD:2F", "F8:D0:27",
+	},
+	"Brother":            {"00:1B:A9", "00:80:77", "30:05:5C", "3C:2A:F4", "B4:22:00"},
+	"Canon":              {"00:00:85", "00:1E:8F", "00:BB:C1", "18:0C:AC", "2C:9E:FC", "60:12:8B", "74:BF:C0", "88:87:17", "F4:81:39"},
+	"Xerox":              {"00:00:AA"},
+	"Lexmark":            {"00:04:00", "00:21:B7"},
+	"Kyocera":            {"00:17:C8", "00:C0:EE"},
+	"Ricoh":              {"00:00:74", "00:26:73", "58:38:79"},
+	"Zebra Technologies": {"00:07:4D", "00:23:68"},
+	"Verifone":           {"00:0B:4F"},
+	"Ingenico":           {"00:03:81"},
+
+	// Câmeras, telefonia IP, armazenamento e IoT
+	"Hikvision":           {"18:68:CB", "28:57:BE", "44:19:B6", "4C:BD:8F", "54:C4:15", "A4:14:37", "BC:AD:28", "C0:56:E3", "C4:2F:90"},
+	"Dahua":               {"38:AF:29", "3C:EF:8C", "4C:11:BF", "90:02:A9", "A0:BD:1D", "E0:50:8B"},
+	"Axis Communications": {"00:40:8C", "AC:CC:8E", "B8:A4:4F"},
+	"Grandstream":         {"00:0B:82", "C0:74:AD"},
+	"Yealink":             {"00:15:65", "80:5E:C0"},
+	"Polycom":             {"00:04:F2", "64:16:7F"},
+	"Synology":            {"00:11:32"},
+	"QNAP":                {"00:08:9B", "24:5E:BE"},
+	"Espressif (IoT)": {
+		"18:FE:34", "24:0A:C4", "24:6F:28", "30:AE:A4", "3C:71:BF", "5C:CF:7F", "60:01:94", "84:F3:EB", "A4:CF:12",
+		"BC:DD:C2", "CC:50:E3", "DC:4F:22", "EC:FA:BC",
+	},
+	"Raspberry Pi": {"28:CD:C1", "B8:27:EB", "D8:3A:DD", "DC:A6:32", "E4:5F:01"},
+
+	// Máquinas virtuais
+	"VMware":            {"00:05:69", "00:0C:29", "00:1C:14", "00:50:56"},
+	"Microsoft Hyper-V": {"00:15:5D"},
+	"VirtualBox":        {"08:00:27"},
+	"QEMU/KVM":          {"52:54:00"},
+	"Xen":               {"00:16:3E"},
+	"Parallels":         {"00:1C:42"},
+}
+
+// Índice prefixo -> fabricante montado a partir da tabela acima
+var ouiVendors = indexOUIPrefixes(ouiPrefixes)
+
+func indexOUIPrefixes(prefixes map[string][]string) map[string]string {
+	index := map[string]string{}
+	for vendor, list := range prefixes {
+		for _, prefix := range list { index[prefix] = vendor }
+	}
+	return index
+}
+
+func lookupVendor(mac string) string {
+	mac = strings.ToUpper(mac)
+	if len(mac) < 8 { return "" }
+	if vendor, ok := ouiVendors[mac[:8]]; ok { return vendor }
+
+	// Bit "localmente administrado": MAC aleatório (celulares) ou virtual
+	if firstOctet, err := strconv.ParseUint(mac[:2], 16, 8); err == nil && firstOctet&0x02 != 0 {
+		return "MAC aleatório/local"
+	}
+	return "Desconhecido"
+}
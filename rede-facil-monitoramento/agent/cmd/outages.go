@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const OUTAGE_JOURNAL_FILE = "agente_quedas.json"
+const OUTAGE_UPLOAD_BATCH = 500
+
+// Com o backend recusando o envio por muito tempo, o diário guarda só as quedas mais recentes
+const OUTAGE_JOURNAL_MAX = 2000
+
+// Envio recusado pelo backend: espera antes de tentar de novo em vez de reenviar a cada telemetria
+const OUTAGE_UPLOAD_RETRY = 10 * time.Minute
+
+// Telemetria e monitor de rede chamam o backend a cada 20-30s. Sem nenhuma tentativa por mais
+// que isto o agente não estava rodando, e esse tempo não conta como queda do backend.
+const OUTAGE_SILENCE_LIMIT = 2 * time.Minute
+const OUTAGE_CHECKPOINT_INTERVAL = 5 * time.Minute
+
+// Modos de falha de conectividade com o backend
+const (
+	FAILURE_DNS  = "dns"
+	FAILURE_TCP  = "tcp"
+	FAILURE_TLS  = "tls"
+	FAILURE_HTTP = "http"
+)
+
+type Outage struct {
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	FailureMode     string    `json:"failure_mode"`
+	LastFailureMode string    `json:"last_failure_mode"`
+	LastError       string    `json:"last_error"`
+	LastFailureAt   time.Time `json:"last_failure_at"`
+	Failures        int       `json:"failures"`
+}
+
+type outageState struct {
+	Open    *Outage  `json:"open"`
+	Journal []Outage `json:"journal"`
+}
+
+type OutageReport struct {
+	MachineUUID string   `json:"machine_uuid"`
+	Outages     []Outage `json:"outages"`
+}
+
+var outages outageState
+var outageMutex sync.Mutex
+var outageLoaded bool
+var outageUploading bool
+var outageUploadFailedAt time.Time
+var outageSavedAt time.Time
+
+// Classifica o erro de uma chamada ao backend no modo de falha correspondente
+func classifyBackendError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) { return FAILURE_DNS }
+
+	var recordErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuth x509.UnknownAuthorityError
+	if errors.As(err, &recordErr) || errors.As(err, &certErr) || errors.As(err, &unknownAuth) { return FAILURE_TLS }
+	if strings.Contains(err.Error(), "tls:") || strings.Contains(err.Error(), "TLS handshake") { return FAILURE_TLS }
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) { return FAILURE_TCP }
+	return FAILURE_HTTP
+}
+
+// Registra o resultado de uma chamada ao backend; resp pode ser nil quando err != nil
+func recordBackendResult(resp *http.Response, err error) {
+	if err != nil {
+		recordBackendFailure(classifyBackendError(err), err.Error())
+		return
+	}
+	if resp.StatusCode >= 500 {
+		recordBackendFailure(FAILURE_HTTP, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		return
+	}
+	recordBackendSuccess()
+}
+
+func recordBackendFailure(mode string, errMsg string) {
+	outageMutex.Lock()
+	defer outageMutex.Unlock()
+	loadOutageJournal()
+
+	now := time.Now()
+	closed, opened := outages.failure(now, mode, errMsg)
+	if closed != nil { log.Printf("📴 Queda anterior encerrada no último erro registrado (agente parado por %s)", now.Sub(closed.EndedAt).Round(time.Second)) }
+	if opened { log.Printf("📴 Backend inacessível (%s): %s", mode, errMsg) }
+	// Salva na abertura e, durante uma queda longa, de tempos em tempos para não perder o último erro
+	if opened || closed != nil || now.Sub(outageSavedAt) >= OUTAGE_CHECKPOINT_INTERVAL { saveOutageJournal() }
+}
+
+func recordBackendSuccess() {
+	outageMutex.Lock()
+	loadOutageJournal()
+
+	if closed := outages.success(time.Now()); closed != nil {
+		saveOutageJournal()
+		log.Printf("📶 Conexão com o backend restabelecida após %ds (%s)", closed.DurationSeconds, closed.FailureMode)
+	}
+
+	pending := len(outages.Journal) > 0 && !outageUploading && time.Since(outageUploadFailedAt) >= OUTAGE_UPLOAD_RETRY
+	if pending { outageUploading = true }
+	outageMutex.Unlock()
+
+	if pending { go uploadOutageJournal() }
+}
+
+// Registra uma falha. Se o último erro da queda aberta é antigo demais, o agente ficou parado
+// (PC desligado ou suspenso) e aquela queda termina no último erro; uma nova começa agora.
+func (s *outageState) failure(now time.Time, mode string, errMsg string) (closed *Outage, opened bool) {
+	if s.Open != nil && now.Sub(s.Open.LastFailureAt) > OUTAGE_SILENCE_LIMIT { closed = s.closeOpen(s.Open.LastFailureAt) }
+	if s.Open == nil {
+		s.Open = &Outage{StartedAt: now, FailureMode: mode}
+		opened = true
+	}
+	s.Open.LastFailureMode = mode
+	s.Open.LastError = errMsg
+	s.Open.LastFailureAt = now
+	s.Open.Failures++
+	return closed, opened
+}
+
+// Fecha a queda aberta. Sem falhas recentes não dá para saber quando o backend voltou,
+// então a queda termina no último erro em vez de contar o tempo com o agente parado.
+func (s *outageState) success(now time.Time) *Outage {
+	if s.Open == nil { return nil }
+	end := now
+	if now.Sub(s.Open.LastFailureAt) > OUTAGE_SILENCE_LIMIT { end = s.Open.LastFailureAt }
+	return s.closeOpen(end)
+}
+
+func (s *outageState) closeOpen(end time.Time) *Outage {
+	closed := *s.Open
+	closed.EndedAt = end
+	closed.DurationSeconds = int64(end.Sub(closed.StartedAt).Seconds())
+	s.Open = nil
+	s.Journal = append(s.Journal, closed)
+	s.trim()
+	return &closed
+}
+
+// Descarta as quedas mais antigas além de OUTAGE_JOURNAL_MAX
+func (s *outageState) trim() {
+	if len(s.Journal) <= OUTAGE_JOURNAL_MAX { return }
+	s.Journal = append([]Outage(nil), s.Journal[len(s.Journal)-OUTAGE_JOURNAL_MAX:]...)
+}
+
+// Remove do diário as quedas enviadas. Durante o envio o diário pode ter crescido no fim e perdido
+// o início para o limite, então compara pelo início da queda (o diário está em ordem cronológica).
+func (s *outageState) removeSent(sent []Outage) {
+	if len(sent) == 0 { return }
+	last := sent[len(sent)-1].StartedAt
+	kept := s.Journal[:0]
+	for _, o := range s.Journal {
+		if o.StartedAt.After(last) { kept = append(kept, o) }
+	}
+	s.Journal = kept
+}
+
+// Envia o diário direto (sem postData) para não realimentar o próprio rastreamento
+func uploadOutageJournal() {
+	defer func() {
+		outageMutex.Lock()
+		outageUploading = false
+		outageMutex.Unlock()
+	}()
+
+	outageMutex.Lock()
+	toSend := append([]Outage(nil), outages.Journal...)
+	outageMutex.Unlock()
+	if len(toSend) > OUTAGE_UPLOAD_BATCH { toSend = toSend[:OUTAGE_UPLOAD_BATCH] }
+	if len(toSend) == 0 { return }
+
+	jsonValue, _ := json.Marshal(OutageReport{MachineUUID: getMachineUUID(), Outages: toSend})
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/telemetry/outages", API_BASE_URL), bytes.NewBuffer(jsonValue))
+	if err != nil { return }
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agent-secret", AgentSecret)
+
+	resp, err := httpClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 { err = fmt.Errorf("HTTP %d", resp.StatusCode) }
+	}
+	if err != nil {
+		outageMutex.Lock()
+		outageUploadFailedAt = time.Now()
+		outageMutex.Unlock()
+		log.Printf("⚠️ Falha ao enviar diário de quedas: %v", err)
+		return
+	}
+
+	outageMutex.Lock()
+	outages.removeSent(toSend)
+	saveOutageJournal()
+	outageMutex.Unlock()
+	log.Printf("📤 Diário de quedas enviado (%d registro(s))", len(toSend))
+}
+
+// Deve ser chamada com outageMutex travado
+func loadOutageJournal() {
+	if outageLoaded { return }
+	outageLoaded = true
+	data, err := os.ReadFile(getAgentDataPath(OUTAGE_JOURNAL_FILE))
+	if err != nil { return }
+	if err := json.Unmarshal(data, &outages); err != nil {
+		log.Printf("⚠️ Diário de quedas inválido: %v", err)
+		return
+	}
+	outages.trim()
+	// Queda aberta quando o agente parou: termina no último erro registrado
+	if outages.Open != nil {
+		if outages.Open.LastFailureAt.IsZero() { outages.Open.LastFailureAt = outages.Open.StartedAt }
+		outages.closeOpen(outages.Open.LastFailureAt)
+		saveOutageJournal()
+	}
+}
+
+// Deve ser chamada com outageMutex travado
+func saveOutageJournal() {
+	data, err := json.MarshalIndent(outages, "", "  ")
+	if err != nil { return }
+	if err := os.WriteFile(getAgentDataPath(OUTAGE_JOURNAL_FILE), data, 0600); err != nil {
+		log.Printf("⚠️ Erro ao salvar diário de quedas: %v", err)
+		return
+	}
+	outageSavedAt = time.Now()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestOutageStateMachine(t *testing.T) {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	var s outageState
+
+	if closed := s.success(start); closed != nil { t.Fatalf("sucesso sem queda aberta fechou %+v", closed) }
+
+	closed, opened := s.failure(start, FAILURE_TCP, "connection refused")
+	if closed != nil || !opened { t.Fatalf("primeira falha: closed=%v opened=%v", closed, opened) }
+	closed, opened = s.failure(start.Add(20*time.Second), FAILURE_TLS, "tls: handshake failure")
+	if closed != nil || opened { t.Fatalf("segunda falha: closed=%v opened=%v", closed, opened) }
+
+	if s.Open.FailureMode != FAILURE_TCP || s.Open.LastFailureMode != FAILURE_TLS || s.Open.Failures != 2 || !s.Open.LastFailureAt.Equal(start.Add(20*time.Second)) {
+		t.Fatalf("queda aberta = %+v", s.Open)
+	}
+
+	got := s.success(start.Add(50 * time.Second))
+	if got == nil || got.DurationSeconds != 50 || !got.EndedAt.Equal(start.Add(50*time.Second)) { t.Fatalf("queda fechada = %+v", got) }
+	if s.Open != nil || len(s.Journal) != 1 { t.Fatalf("estado após fechar = %+v", s) }
+}
+
+// PC desligado à noite durante uma queda: o tempo parado não conta como indisponibilidade do backend
+func TestOutageEndsAtLastFailureAfterSilence(t *testing.T) {
+	start := time.Date(2026, 6, 1, 18, 50, 0, 0, time.UTC)
+	var s outageState
+	s.failure(start, FAILURE_DNS, "no such host")
+	s.failure(start.Add(30*time.Second), FAILURE_DNS, "no such host")
+
+	got := s.success(start.Add(14 * time.Hour))
+	if got == nil || got.DurationSeconds != 30 || !got.EndedAt.Equal(start.Add(30*time.Second)) { t.Fatalf("queda fechada = %+v", got) }
+}
+
+func TestOutageSplitsAfterSilence(t *testing.T) {
+	start := time.Date(2026, 6, 1, 18, 50, 0, 0, time.UTC)
+	var s outageState
+	s.failure(start, FAILURE_TCP, "timeout")
+
+	next := start.Add(13 * time.Hour)
+	closed, opened := s.failure(next, FAILURE_TCP, "timeout")
+	if closed == nil || closed.DurationSeconds != 0 || !opened { t.Fatalf("closed=%+v opened=%v", closed, opened) }
+	if !s.Open.StartedAt.Equal(next) || s.Open.Failures != 1 || len(s.Journal) != 1 { t.Fatalf("estado = %+v", s) }
+}
+
+func TestOutageJournalIsCapped(t *testing.T) {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	var s outageState
+	for i := 0; i < OUTAGE_JOURNAL_MAX+10; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		s.failure(at, FAILURE_TCP, "timeout")
+		s.success(at.Add(30 * time.Second))
+	}
+	if len(s.Journal) != OUTAGE_JOURNAL_MAX { t.Fatalf("diário com %d quedas, limite %d", len(s.Journal), OUTAGE_JOURNAL_MAX) }
+	if !s.Journal[0].StartedAt.Equal(start.Add(10 * time.Hour)) { t.Errorf("primeira queda mantida = %s, esperado as mais recentes", s.Journal[0].StartedAt) }
+}
+
+func TestOutageRemoveSent(t *testing.T) {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	journal := func(hours ...int) []Outage {
+		var list []Outage
+		for _, h := range hours { list = append(list, Outage{StartedAt: start.Add(time.Duration(h) * time.Hour)}) }
+		return list
+	}
+
+	cases := []struct {
+		name    string
+		journal []Outage
+		sent    []Outage
+		want    int
+	}{
+		{"diário sem mudança", journal(0, 1, 2), journal(0, 1, 2), 0},
+		{"queda nova durante o envio", journal(0, 1, 2, 3), journal(0, 1, 2), 1},
+		{"início descartado pelo limite durante o envio", journal(1, 2, 3, 4), journal(0, 1, 2), 2},
+		{"nada enviado", journal(0, 1), nil, 2},
+	}
+	for _, c := range cases {
+		s := outageState{Journal: c.journal}
+		s.removeSent(c.sent)
+		if len(s.Journal) != c.want { t.Errorf("%s: restaram %d quedas, esperado %d", c.name, len(s.Journal), c.want) }
+	}
+}
+
+func TestClassifyBackendError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{&net.DNSError{Err: "no such host", Name: "backend"}, FAILURE_DNS},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, FAILURE_TCP},
+		{errors.New("remote error: tls: handshake failure"), FAILURE_TLS},
+		{errors.New("net/http: TLS handshake timeout"), FAILURE_TLS},
+		{errors.New("EOF"), FAILURE_HTTP},
+	}
+	for _, c := range cases {
+		if got := classifyBackendError(c.err); got != c.want { t.Errorf("classifyBackendError(%v) = %s, esperado %s", c.err, got, c.want) }
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const INSTALL_TIMEOUT = 30 * time.Minute
+
+// Exit codes do Windows Installer que indicam sucesso com reinício pendente
+const (
+	MSI_SUCCESS_REBOOT_REQUIRED  = 3010
+	MSI_SUCCESS_REBOOT_INITIATED = 1641
+)
+
+// Payload dos comandos install_package / uninstall_package.
+// Source: "msi", "winget", "exe", "apt" ou "dnf".
+// Args: um argumento por item, sem aspas extras (ex: ["INSTALLDIR=C:\\Program Files\\X"]).
+type PackageRequest struct {
+	Source    string   `json:"source"`
+	PackageID string   `json:"package_id"`
+	URL       string   `json:"url"`
+	SHA256    string   `json:"sha256"`
+	Args      []string `json:"args"`
+}
+
+// DEBIAN_FRONTEND só no processo do apt
+var aptEnv = []string{"DEBIAN_FRONTEND=noninteractive"}
+
+type PackageResult struct {
+	Action         string `json:"action"`
+	Source         string `json:"source"`
+	PackageID      string `json:"package_id"`
+	Success        bool   `json:"success"`
+	ExitCode       int    `json:"exit_code"`
+	RebootRequired bool   `json:"reboot_required"`
+	DurationMS     int64  `json:"duration_ms"`
+	Output         string `json:"output"`
+}
+
+func handlePackageCommand(action string, payload string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado no %s: %v", action, r)
+			sendCommandResult("", fmt.Sprintf("Erro interno: %v", r))
+		}
+	}()
+
+	var pkgReq PackageRequest
+	if err := json.Unmarshal([]byte(payload), &pkgReq); err != nil {
+		sendCommandResult("", fmt.Sprintf("Payload inválido: %v", err))
+		return
+	}
+	pkgReq.Source = strings.ToLower(strings.TrimSpace(pkgReq.Source))
+	if pkgReq.Source == "" { pkgReq.Source = defaultPackageSource() }
+
+	log.Printf("📦 %s via %s: %s", action, pkgReq.Source, pkgReq.PackageID)
+	start := time.Now()
+
+	var result PackageResult
+	var err error
+	if action == "install_package" {
+		result, err = installPackage(pkgReq)
+	} else {
+		result, err = uninstallPackage(pkgReq)
+	}
+	result.Action = action
+	result.Source = pkgReq.Source
+	result.PackageID = pkgReq.PackageID
+	result.DurationMS = time.Since(start).Milliseconds()
+	if len(result.Output) > 4000 { result.Output = result.Output[len(result.Output)-4000:] }
+
+	jsonResult, _ := json.Marshal(result)
+	if err != nil {
+		log.Printf("❌ %s falhou: %v", action, err)
+		sendCommandResult(string(jsonResult), err.Error())
+	} else {
+		log.Printf("✅ %s concluído (exit %d, reinício pendente: %v)", action, result.ExitCode, result.RebootRequired)
+		sendCommandResult(string(jsonResult), "")
+	}
+
+	// Reenvia o registro para que o inventário de software reflita a mudança
+	if result.Success {
+		invalidateCollector("software")
+		requestRegistration()
+	}
+}
+
+func defaultPackageSource() string {
+	if runtime.GOOS == "windows" { return "winget" }
+	if _, err := exec.LookPath("apt-get"); err == nil { return "apt" }
+	return "dnf"
+}
+
+func installPackage(pkgReq PackageRequest) (PackageResult, error) {
+	switch pkgReq.Source {
+	case "msi", "exe":
+		if runtime.GOOS != "windows" { return PackageResult{}, fmt.Errorf("fonte %s disponível apenas no Windows", pkgReq.Source) }
+		if pkgReq.URL == "" { return PackageResult{}, fmt.Errorf("url do instalador não informada") }
+
+		fileName := filepath.Base(pkgReq.URL)
+		if !strings.HasSuffix(strings.ToLower(fileName), "."+pkgReq.Source) { fileName = "installer." + pkgReq.Source }
+		installerPath, err := downloadVerifiedFile(pkgReq.URL, pkgReq.SHA256, fileName)
+		if err != nil { return PackageResult{}, err }
+		defer removeDownloadedFile(installerPath)
+
+		if pkgReq.Source == "msi" {
+			args := append([]string{"/i", installerPath, "/qn", "/norestart"}, pkgReq.Args...)
+			return runPackageCommand("msiexec", args...)
+		}
+		return runPackageCommand(installerPath, pkgReq.Args...)
+
+	case "winget":
+		if pkgReq.PackageID == "" { return PackageResult{}, fmt.Errorf("package_id não informado") }
+		args := []string{"install", "--id", pkgReq.PackageID, "-e", "--silent", "--accept-package-agreements", "--accept-source-agreements", "--disable-interactivity"}
+		return runPackageCommand("winget", append(args, pkgReq.Args...)...)
+
+	case "apt":
+		if pkgReq.PackageID == "" { return PackageResult{}, fmt.Errorf("package_id não informado") }
+		return runPackageCommandEnv(aptEnv, "apt-get", "install", "-y", pkgReq.PackageID)
+
+	case "dnf":
+		if pkgReq.PackageID == "" { return PackageResult{}, fmt.Errorf("package_id não informado") }
+		return runPackageCommand("dnf", "install", "-y", pkgReq.PackageID)
+	}
+	return PackageResult{}, fmt.Errorf("fonte de pacote desconhecida: %s", pkgReq.Source)
+}
+
+func uninstallPackage(pkgReq PackageRequest) (PackageResult, error) {
+	if pkgReq.PackageID == "" { return PackageResult{}, fmt.Errorf("package_id não informado") }
+
+	switch pkgReq.Source {
+	case "msi":
+		// PackageID é o ProductCode, ex: {XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX}
+		args := append([]string{"/x", pkgReq.PackageID, "/qn", "/norestart"}, pkgReq.Args...)
+		return runPackageCommand("msiexec", args...)
+
+	case "exe":
+		// PackageID é o DisplayName registrado em Uninstall; usa a QuietUninstallString quando existir
+		uninstallCmd, err := findUninstallString(pkgReq.PackageID)
+		if err != nil { return PackageResult{}, err }
+		program, args := splitCommandLine(uninstallCmd)
+		if program == "" { return PackageResult{}, fmt.Errorf("UninstallString vazia para %s", pkgReq.PackageID) }
+		if isMsiexec(program) { args = silentMsiUninstallArgs(args) }
+		return runPackageCommand(program, append(args, pkgReq.Args...)...)
+
+	case "winget":
+		args := []string{"uninstall", "--id", pkgReq.PackageID, "-e", "--silent", "--accept-source-agreements", "--disable-interactivity"}
+		return runPackageCommand("winget", append(args, pkgReq.Args...)...)
+
+	case "apt":
+		return runPackageCommandEnv(aptEnv, "apt-get", "remove", "-y", pkgReq.PackageID)
+
+	case "dnf":
+		return runPackageCommand("dnf", "remove", "-y", pkgReq.PackageID)
+	}
+	return PackageResult{}, fmt.Errorf("fonte de pacote desconhecida: %s", pkgReq.Source)
+}
+
+func runPackageCommand(command string, args ...string) (PackageResult, error) {
+	return runPackageCommandEnv(nil, command, args...)
+}
+
+func runPackageCommandEnv(env []string, command string, args ...string) (PackageResult, error) {
+	output, exitCode, err := runCommandWithExitCodeEnv(INSTALL_TIMEOUT, env, command, args...)
+	result := PackageResult{ExitCode: exitCode, Output: strings.TrimSpace(output)}
+	if err != nil { return result, err }
+
+	switch exitCode {
+	case 0:
+		result.Success = true
+	case MSI_SUCCESS_REBOOT_REQUIRED, MSI_SUCCESS_REBOOT_INITIATED:
+		result.Success = true
+		result.RebootRequired = true
+	default:
+		return result, fmt.Errorf("%s terminou com código %d", command, exitCode)
+	}
+
+	if !result.RebootRequired { result.RebootRequired = isRebootPending() }
+	return result, nil
+}
+
+func findUninstallString(displayName string) (string, error) {
+	if runtime.GOOS != "windows" { return "", fmt.Errorf("desinstalação por exe disponível apenas no Windows") }
+	psCommand := fmt.Sprintf(`Get-ItemProperty HKLM:\Software\Microsoft\Windows\CurrentVersion\Uninstall\*, HKLM:\Software\Wow6432Node\Microsoft\Windows\CurrentVersion\Uninstall\* | Where-Object { $_.DisplayName -eq '%s' } | Select-Object -First 1 | ForEach-Object { if ($_.QuietUninstallString) { $_.QuietUninstallString } else { $_.UninstallString } }`, strings.ReplaceAll(displayName, "'", "''"))
+	output, err := runCommandHidden("powershell", "-NoProfile", "-Command", psCommand)
+	if err != nil { return "", fmt.Errorf("falha ao consultar registro: %v", err) }
+	uninstallCmd := strings.TrimSpace(output)
+	if uninstallCmd == "" { return "", fmt.Errorf("software não encontrado: %s", displayName) }
+	return uninstallCmd, nil
+}
+
+// Separa uma linha de comando do registro em programa e argumentos. O caminho do programa
+// pode vir sem aspas e com espaços ("C:\Program Files\X\uninstall.exe /S").
+func splitCommandLine(cmdLine string) (string, []string) {
+	cmdLine = strings.TrimSpace(cmdLine)
+	var program, rest string
+	if strings.HasPrefix(cmdLine, `"`) {
+		end := strings.Index(cmdLine[1:], `"`)
+		if end < 0 { return strings.Trim(cmdLine, `"`), nil }
+		program, rest = cmdLine[1:end+1], cmdLine[end+2:]
+	} else if idx := strings.Index(strings.ToLower(cmdLine), ".exe"); idx >= 0 {
+		program, rest = cmdLine[:idx+4], cmdLine[idx+4:]
+	} else {
+		fields := strings.SplitN(cmdLine, " ", 2)
+		program = fields[0]
+		if len(fields) > 1 { rest = fields[1] }
+	}
+
+	var args []string
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+	for _, r := range rest {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasArg { args = append(args, current.String()) }
+			current.Reset()
+			hasArg = false
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg { args = append(args, current.String()) }
+	return program, args
+}
+
+func isMsiexec(program string) bool {
+	base := strings.ToLower(filepath.Base(strings.ReplaceAll(program, `\`, "/")))
+	return base == "msiexec" || base == "msiexec.exe"
+}
+
+// Muitos instaladores registram "MsiExec.exe /I{GUID}", que abre o assistente de manutenção
+// e fica esperando o usuário. Troca por /X e acrescenta /qn /norestart.
+func silentMsiUninstallArgs(args []string) []string {
+	var result []string
+	quiet, norestart := false, false
+	for _, arg := range args {
+		lower := strings.ToLower(arg)
+		switch {
+		case lower == "/i" || lower == "-i":
+			arg = "/X"
+		case strings.HasPrefix(lower, "/i{") || strings.HasPrefix(lower, "-i{"):
+			arg = "/X" + arg[2:]
+		case strings.HasPrefix(lower, "/q") || strings.HasPrefix(lower, "-q"):
+			quiet = true
+		case lower == "/norestart" || lower == "-norestart":
+			norestart = true
+		}
+		result = append(result, arg)
+	}
+	if !quiet { result = append(result, "/qn") }
+	if !norestart { result = append(result, "/norestart") }
+	return result
+}
+
+// Verifica se o sistema sinaliza reinício pendente após a instalação
+func isRebootPending() bool {
+	if runtime.GOOS == "windows" {
+		psCommand := `(Test-Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Component Based Servicing\RebootPending') -or (Test-Path 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\WindowsUpdate\Auto Update\RebootRequired') -or ((Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\Session Manager' -Name PendingFileRenameOperations -ErrorAction SilentlyContinue) -ne $null)`
+		output, err := runCommandHidden("powershell", "-NoProfile", "-Command", psCommand)
+		return err == nil && strings.EqualFold(strings.TrimSpace(output), "True")
+	}
+	if _, err := os.Stat("/var/run/reboot-required"); err == nil { return true }
+	if _, err := exec.LookPath("needs-restarting"); err == nil {
+		_, exitCode, err := runCommandWithExitCode(30*time.Second, "needs-restarting", "-r")
+		return err == nil && exitCode == 1
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	cases := []struct {
+		cmdLine     string
+		wantProgram string
+		wantArgs    []string
+	}{
+		{`MsiExec.exe /I{23170F69-40C1-2702-2409-000001000000}`, "MsiExec.exe", []string{"/I{23170F69-40C1-2702-2409-000001000000}"}},
+		{`"C:\Program Files\7-Zip\Uninstall.exe" /S`, `C:\Program Files\7-Zip\Uninstall.exe`, []string{"/S"}},
+		{`C:\Program Files (x86)\Foxit Software\Foxit PDF Reader\unins000.exe /VERYSILENT /NORESTART`, `C:\Program Files (x86)\Foxit Software\Foxit PDF Reader\unins000.exe`, []string{"/VERYSILENT", "/NORESTART"}},
+		{`"C:\ProgramData\Package Cache\{abc}\setup.exe" /uninstall /log "C:\Temp\un install.log"`, `C:\ProgramData\Package Cache\{abc}\setup.exe`, []string{"/uninstall", "/log", `C:\Temp\un install.log`}},
+		{`"C:\Program Files\App\uninst.exe"`, `C:\Program Files\App\uninst.exe`, nil},
+		{`rundll32 dfshim.dll,ShArpMaintain App.application`, "rundll32", []string{"dfshim.dll,ShArpMaintain", "App.application"}},
+	}
+	for _, c := range cases {
+		program, args := splitCommandLine(c.cmdLine)
+		if program != c.wantProgram || !reflect.DeepEqual(args, c.wantArgs) {
+			t.Errorf("splitCommandLine(%q) = %q, %q; esperado %q, %q", c.cmdLine, program, args, c.wantProgram, c.wantArgs)
+		}
+	}
+}
+
+func TestSilentMsiUninstallArgs(t *testing.T) {
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"/I{23170F69-40C1-2702-2409-000001000000}"}, []string{"/X{23170F69-40C1-2702-2409-000001000000}", "/qn", "/norestart"}},
+		{[]string{"/i", "{A1B2}"}, []string{"/X", "{A1B2}", "/qn", "/norestart"}},
+		{[]string{"/X{A1B2}", "/quiet"}, []string{"/X{A1B2}", "/quiet", "/norestart"}},
+		{[]string{"/x", "{A1B2}", "/qn", "/norestart"}, []string{"/x", "{A1B2}", "/qn", "/norestart"}},
+	}
+	for _, c := range cases {
+		if got := silentMsiUninstallArgs(c.args); !reflect.DeepEqual(got, c.want) { t.Errorf("silentMsiUninstallArgs(%q) = %q, esperado %q", c.args, got, c.want) }
+	}
+}
+
+func TestIsMsiexec(t *testing.T) {
+	for program, want := range map[string]bool{
+		"MsiExec.exe":                       true,
+		`C:\Windows\System32\msiexec.exe`:   true,
+		"msiexec":                           true,
+		`C:\Program Files\App\uninstall.exe`: false,
+	} {
+		if got := isMsiexec(program); got != want { t.Errorf("isMsiexec(%q) = %v, esperado %v", program, got, want) }
+	}
+}
+
+func TestPackageRequestArgsKeepSpaces(t *testing.T) {
+	var req PackageRequest
+	payload := `{"source":"msi","url":"https://srv/app.msi","args":["INSTALLDIR=C:\\Program Files\\App","ALLUSERS=1"]}`
+	if err := json.Unmarshal([]byte(payload), &req); err != nil { t.Fatal(err) }
+	want := []string{`INSTALLDIR=C:\Program Files\App`, "ALLUSERS=1"}
+	if !reflect.DeepEqual(req.Args, want) { t.Errorf("args = %q, esperado %q", req.Args, want) }
+}
+
+func TestRequestRegistrationCoalesces(t *testing.T) {
+	for len(registrationRequests) > 0 { <-registrationRequests }
+	requestRegistration()
+	requestRegistration()
+	requestRegistration()
+	if len(registrationRequests) != 1 { t.Fatalf("%d pedidos pendentes, esperado 1", len(registrationRequests)) }
+	<-registrationRequests
+}
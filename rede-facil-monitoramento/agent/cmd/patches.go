@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PATCH_SCAN_TIMEOUT = 10 * time.Minute
+
+type InstalledHotfix struct {
+	HotfixID    string `json:"hotfix_id"`
+	Description string `json:"description"`
+	InstalledOn string `json:"installed_on"`
+	InstalledBy string `json:"installed_by"`
+}
+
+type PendingUpdate struct {
+	Title          string   `json:"title"`
+	KB             []string `json:"kb"`
+	Package        string   `json:"package,omitempty"`
+	Version        string   `json:"version,omitempty"`
+	Severity       string   `json:"severity"`
+	IsSecurity     bool     `json:"is_security"`
+	SizeMB         float64  `json:"size_mb"`
+	Categories     []string `json:"categories,omitempty"`
+	RebootRequired bool     `json:"reboot_required"`
+}
+
+// Origem das atualizações no Windows: WSUS (WUServer), Windows Update for Business (adiamentos) ou padrão
+type UpdateConfig struct {
+	Mode                    string `json:"mode"`
+	WSUSServer              string `json:"wsus_server"`
+	WSUSStatusServer        string `json:"wsus_status_server"`
+	TargetGroup             string `json:"target_group"`
+	AutoUpdateOption        int    `json:"auto_update_option"`
+	AutoUpdateDisabled      bool   `json:"auto_update_disabled"`
+	DeferQualityUpdatesDays int    `json:"defer_quality_updates_days"`
+	DeferFeatureUpdatesDays int    `json:"defer_feature_updates_days"`
+	TargetReleaseVersion    string `json:"target_release_version"`
+}
+
+type PatchReport struct {
+	MachineUUID          string            `json:"machine_uuid"`
+	Source               string            `json:"source"`
+	CollectedAt          string            `json:"collected_at"`
+	InstalledHotfixes    []InstalledHotfix `json:"installed_hotfixes"`
+	PendingUpdates       []PendingUpdate   `json:"pending_updates"`
+	PendingCount         int               `json:"pending_count"`
+	PendingSecurityCount int               `json:"pending_security_count"`
+	LastScanSuccess      string            `json:"last_scan_success"`
+	LastInstallSuccess   string            `json:"last_install_success"`
+	RebootPending        bool              `json:"reboot_pending"`
+	UpdateConfig         *UpdateConfig     `json:"update_config,omitempty"`
+	ScanError            string            `json:"scan_error,omitempty"`
+}
+
+type Win32_QuickFixEngineering struct {
+	HotFixID    string
+	Description string
+	InstalledOn string
+	InstalledBy string
+}
+
+// Saída do script do Windows Update Agent (datas no formato 's' do PowerShell)
+type windowsUpdateScan struct {
+	Pending []struct {
+		Title          string   `json:"Title"`
+		KB             []string `json:"KB"`
+		Severity       string   `json:"Severity"`
+		SizeBytes      int64    `json:"SizeBytes"`
+		Categories     []string `json:"Categories"`
+		RebootRequired bool     `json:"RebootRequired"`
+	} `json:"Pending"`
+	LastSearch       string `json:"LastSearch"`
+	LastInstall      string `json:"LastInstall"`
+	Error            string `json:"Error"`
+	ShortDatePattern string `json:"ShortDatePattern"`
+	Policy           struct {
+		WUServer       string `json:"WUServer"`
+		WUStatusServer string `json:"WUStatusServer"`
+		TargetGroup    string `json:"TargetGroup"`
+		UseWUServer    int    `json:"UseWUServer"`
+		AUOptions      int    `json:"AUOptions"`
+		NoAutoUpdate   int    `json:"NoAutoUpdate"`
+		DeferQuality   int    `json:"DeferQuality"`
+		DeferFeature   int    `json:"DeferFeature"`
+		TargetRelease  string `json:"TargetRelease"`
+	} `json:"Policy"`
+}
+
+// Formatos do InstalledOn do Win32_QuickFixEngineering (a ordem dia/mês depende da localidade do sistema)
+var hotfixDateLayoutsMDY = []string{"1/2/2006", "2006-01-02", "20060102"}
+var hotfixDateLayoutsDMY = []string{"2/1/2006", "2006-01-02", "20060102"}
+
+// Intervalos de 100ns entre 1601-01-01 (FILETIME) e 1970-01-01
+const FILETIME_UNIX_EPOCH = 116444736000000000
+
+var aptUpgradablePattern = regexp.MustCompile(`^(\S+)/(\S+)\s+(\S+)\s+\S+`)
+
+// Coletor agendado: além de ficar em cache, cada execução envia o relatório ao backend
+func collectPatches(ctx context.Context) (interface{}, error) {
+	var report PatchReport
+	if runtime.GOOS == "windows" {
+		report = windowsPatchReport()
+	} else {
+		report = linuxPatchReport()
+	}
+	report.MachineUUID = getMachineUUID()
+	report.CollectedAt = time.Now().Format(time.RFC3339)
+	report.RebootPending = isRebootPending()
+	if report.InstalledHotfixes == nil { report.InstalledHotfixes = []InstalledHotfix{} }
+	if report.PendingUpdates == nil { report.PendingUpdates = []PendingUpdate{} }
+	report.PendingCount = len(report.PendingUpdates)
+	for _, u := range report.PendingUpdates {
+		if u.IsSecurity { report.PendingSecurityCount++ }
+	}
+
+	go postData("/telemetry/patches", report)
+	if report.ScanError != "" { return report, fmt.Errorf("busca de atualizações: %s", report.ScanError) }
+	return report, nil
+}
+
+func windowsPatchReport() PatchReport {
+	report := PatchReport{Source: "windows_update"}
+
+	var hotfixes []Win32_QuickFixEngineering
+	hotfixErr := queryWMI(&hotfixes, "")
+
+	scan, scanErr := runWindowsUpdateScan()
+	report.InstalledHotfixes = installedHotfixes(hotfixes, scan.ShortDatePattern)
+
+	var errs []string
+	if hotfixErr != nil { errs = append(errs, fmt.Sprintf("Win32_QuickFixEngineering: %v", hotfixErr)) }
+	if scanErr != nil { errs = append(errs, scanErr.Error()) }
+	if scan.Error != "" { errs = append(errs, scan.Error) }
+	report.ScanError = strings.Join(errs, "; ")
+	if scanErr != nil { return report }
+
+	report.LastScanSuccess = scan.LastSearch
+	report.LastInstallSuccess = scan.LastInstall
+
+	for _, p := range scan.Pending {
+		update := PendingUpdate{
+			Title:          p.Title,
+			KB:             p.KB,
+			Severity:       p.Severity,
+			SizeMB:         float64(p.SizeBytes*10/(1024*1024)) / 10,
+			Categories:     p.Categories,
+			RebootRequired: p.RebootRequired,
+		}
+		for _, c := range p.Categories {
+			if strings.Contains(strings.ToLower(c), "security") || strings.Contains(strings.ToLower(c), "segurança") { update.IsSecurity = true }
+		}
+		if update.Severity != "" { update.IsSecurity = true }
+		if update.KB == nil { update.KB = []string{} }
+		report.PendingUpdates = append(report.PendingUpdates, update)
+	}
+
+	cfg := &UpdateConfig{
+		Mode:                    "windows_update",
+		WSUSServer:              scan.Policy.WUServer,
+		WSUSStatusServer:        scan.Policy.WUStatusServer,
+		TargetGroup:             scan.Policy.TargetGroup,
+		AutoUpdateOption:        scan.Policy.AUOptions,
+		AutoUpdateDisabled:      scan.Policy.NoAutoUpdate == 1,
+		DeferQualityUpdatesDays: scan.Policy.DeferQuality,
+		DeferFeatureUpdatesDays: scan.Policy.DeferFeature,
+		TargetReleaseVersion:    scan.Policy.TargetRelease,
+	}
+	switch {
+	case scan.Policy.UseWUServer == 1 && cfg.WSUSServer != "":
+		cfg.Mode = "wsus"
+	case cfg.DeferQualityUpdatesDays > 0 || cfg.DeferFeatureUpdatesDays > 0 || cfg.TargetReleaseVersion != "":
+		cfg.Mode = "wufb"
+	}
+	report.UpdateConfig = cfg
+	return report
+}
+
+func runWindowsUpdateScan() (windowsUpdateScan, error) {
+	var scan windowsUpdateScan
+	psCommand := `$ErrorActionPreference = 'SilentlyContinue'
+	$result = [ordered]@{ Pending = @(); LastSearch = ''; LastInstall = ''; Error = ''; Policy = @{}; ShortDatePattern = (Get-Culture).DateTimeFormat.ShortDatePattern }
+	try {
+		$searcher = (New-Object -ComObject Microsoft.Update.Session).CreateUpdateSearcher()
+		$search = $searcher.Search("IsInstalled=0 and IsHidden=0 and Type='Software'")
+		$result.Pending = @($search.Updates | ForEach-Object {
+			[PSCustomObject]@{
+				Title = [string]$_.Title
+				KB = @($_.KBArticleIDs | ForEach-Object { 'KB' + $_ })
+				Severity = [string]$_.MsrcSeverity
+				SizeBytes = [int64]$_.MaxDownloadSize
+				Categories = @($_.Categories | ForEach-Object { [string]$_.Name })
+				RebootRequired = [bool]$_.RebootRequired
+			}
+		})
+	} catch { $result.Error = $_.Exception.Message }
+	$au = (New-Object -ComObject Microsoft.Update.AutoUpdate).Results
+	if ($au.LastSearchSuccessDate) { $result.LastSearch = $au.LastSearchSuccessDate.ToString('s') }
+	if ($au.LastInstallationSuccessDate) { $result.LastInstall = $au.LastInstallationSuccessDate.ToString('s') }
+	$wu = Get-ItemProperty 'HKLM:\SOFTWARE\Policies\Microsoft\Windows\WindowsUpdate'
+	$auPol = Get-ItemProperty 'HKLM:\SOFTWARE\Policies\Microsoft\Windows\WindowsUpdate\AU'
+	$result.Policy = @{
+		WUServer = [string]$wu.WUServer; WUStatusServer = [string]$wu.WUStatusServer; TargetGroup = [string]$wu.TargetGroup
+		UseWUServer = [int]$auPol.UseWUServer; AUOptions = [int]$auPol.AUOptions; NoAutoUpdate = [int]$auPol.NoAutoUpdate
+		DeferQuality = [int]$wu.DeferQualityUpdatesPeriodInDays; DeferFeature = [int]$wu.DeferFeatureUpdatesPeriodInDays
+		TargetRelease = [string]$wu.TargetReleaseVersionInfo
+	}
+	$result | ConvertTo-Json -Depth 4 -Compress`
+	output, _, err := runCommandWithExitCode(PATCH_SCAN_TIMEOUT, "powershell", "-NoProfile", "-Command", psCommand)
+	if err != nil { return scan, err }
+
+	start := strings.Index(output, "{")
+	if start < 0 || json.Unmarshal([]byte(output[start:]), &scan) != nil { return scan, fmt.Errorf("resposta inválida do Windows Update Agent") }
+	return scan, nil
+}
+
+// Hotfixes do mais recente para o mais antigo
+func installedHotfixes(hotfixes []Win32_QuickFixEngineering, shortDatePattern string) []InstalledHotfix {
+	var values []string
+	for _, h := range hotfixes { values = append(values, h.InstalledOn) }
+	dayFirst := hotfixDatesDayFirst(values, shortDatePattern)
+
+	var list []InstalledHotfix
+	for _, h := range hotfixes {
+		list = append(list, InstalledHotfix{
+			HotfixID:    h.HotFixID,
+			Description: h.Description,
+			InstalledOn: normalizeHotfixDate(h.InstalledOn, dayFirst),
+			InstalledBy: h.InstalledBy,
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].InstalledOn > list[j].InstalledOn })
+	return list
+}
+
+// O InstalledOn segue o formato de data da localidade (3/4/2024 é 3 de abril em pt-BR e 4 de março em en-US).
+// Uma data com o primeiro número acima de 12 resolve a ordem para a lista toda; sem isso vale o padrão
+// da cultura do sistema e, por último, o formato americano que o WMI usa por padrão.
+func hotfixDatesDayFirst(values []string, shortDatePattern string) bool {
+	for _, value := range values {
+		parts := strings.Split(strings.TrimSpace(value), "/")
+		if len(parts) != 3 { continue }
+		first, err1 := strconv.Atoi(parts[0])
+		second, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil { continue }
+		if first > 12 { return true }
+		if second > 12 { return false }
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(shortDatePattern)), "d")
+}
+
+func normalizeHotfixDate(value string, dayFirst bool) string {
+	value = strings.TrimSpace(value)
+	// Alguns sistemas devolvem o FILETIME em hexadecimal
+	if len(value) == 16 {
+		if ft, err := strconv.ParseUint(value, 16, 64); err == nil {
+			return time.Unix(0, 0).Add(time.Duration(ft-FILETIME_UNIX_EPOCH) * 100).UTC().Format("2006-01-02")
+		}
+	}
+	layouts := hotfixDateLayoutsMDY
+	if dayFirst { layouts = hotfixDateLayoutsDMY }
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil { return t.Format("2006-01-02") }
+	}
+	return value
+}
+
+func linuxPatchReport() PatchReport {
+	if _, err := exec.LookPath("apt"); err == nil { return aptPatchReport() }
+	if _, err := exec.LookPath("dnf"); err == nil { return dnfPatchReport() }
+	return PatchReport{Source: "unsupported", ScanError: "gerenciador de pacotes não suportado"}
+}
+
+// Usa o cache do apt (sem "apt update", que exige root); a data da última busca é a do stamp do apt
+func aptPatchReport() PatchReport {
+	report := PatchReport{Source: "apt"}
+	output, _, err := runCommandWithExitCodeEnv(PATCH_SCAN_TIMEOUT, []string{"LC_ALL=C"}, "apt", "list", "--upgradable")
+	if err != nil {
+		report.ScanError = err.Error()
+		return report
+	}
+	report.PendingUpdates = parseAptUpgradable(output)
+	report.LastScanSuccess = fileModTime("/var/lib/apt/periodic/update-success-stamp", "/var/lib/apt/lists")
+	report.LastInstallSuccess = fileModTime("/var/log/dpkg.log")
+	return report
+}
+
+func parseAptUpgradable(output string) []PendingUpdate {
+	var updates []PendingUpdate
+	for _, line := range strings.Split(output, "\n") {
+		// "openssl/jammy-security,jammy-updates 3.0.2-0ubuntu1.15 amd64 [upgradable from: 3.0.2-0ubuntu1.14]"
+		// O texto entre colchetes depende da localidade; o formato pacote/origem já separa as linhas de pacote
+		match := aptUpgradablePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil { continue }
+		update := PendingUpdate{Title: match[1], Package: match[1], Version: match[3], KB: []string{}}
+		if strings.Contains(match[2], "-security") {
+			update.IsSecurity = true
+			update.Severity = "security"
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+func dnfPatchReport() PatchReport {
+	report := PatchReport{Source: "dnf"}
+
+	security := map[string]string{}
+	if output, _, err := runCommandWithExitCode(PATCH_SCAN_TIMEOUT, "dnf", "-q", "updateinfo", "list", "--security"); err == nil { security = parseDnfSecurityAdvisories(output) }
+
+	// check-update sai com 100 quando há atualizações
+	output, exitCode, err := runCommandWithExitCode(PATCH_SCAN_TIMEOUT, "dnf", "-q", "check-update")
+	if err != nil || (exitCode != 0 && exitCode != 100) {
+		report.ScanError = fmt.Sprintf("dnf check-update falhou (código %d)", exitCode)
+		return report
+	}
+	report.PendingUpdates = parseDnfCheckUpdate(output, security)
+	// check-update pode responder do cache; a busca real é a última atualização dos metadados dos repositórios
+	report.LastScanSuccess = newestModTime("/var/cache/dnf/*/repodata/repomd.xml", "/var/cache/libdnf5/*/repodata/repomd.xml")
+	report.LastInstallSuccess = fileModTime("/var/lib/dnf/history.sqlite")
+	return report
+}
+
+// Avisos de segurança por nome de pacote: "FEDORA-2024-1234 Important/Sec. openssl-libs-3.1.4-1.fc39.x86_64"
+func parseDnfSecurityAdvisories(output string) map[string]string {
+	security := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 { continue }
+		name, _, _, ok := parseNEVRA(fields[2])
+		if !ok { continue }
+		security[name] = strings.TrimSuffix(fields[1], "/Sec.")
+	}
+	return security
+}
+
+func parseDnfCheckUpdate(output string, security map[string]string) []PendingUpdate {
+	var updates []PendingUpdate
+	for _, line := range strings.Split(output, "\n") {
+		// "openssl.x86_64  1:3.1.4-1.fc39  updates"
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, " ") { continue }
+		update := PendingUpdate{Title: fields[0], Package: fields[0], Version: fields[1], KB: []string{}}
+		name := strings.TrimSuffix(fields[0], "."+packageArch(fields[0]))
+		if severity, ok := security[name]; ok {
+			update.IsSecurity = true
+			update.Severity = severity
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// Separa "nome-[época:]versão-release.arquitetura". O nome pode ter hífens (openssl-libs),
+// então versão e release são os dois últimos trechos separados por "-".
+func parseNEVRA(nevra string) (name string, version string, release string, ok bool) {
+	nevra = strings.TrimSuffix(nevra, "."+packageArch(nevra))
+	relIdx := strings.LastIndex(nevra, "-")
+	if relIdx <= 0 { return "", "", "", false }
+	verIdx := strings.LastIndex(nevra[:relIdx], "-")
+	if verIdx <= 0 { return "", "", "", false }
+	return nevra[:verIdx], nevra[verIdx+1 : relIdx], nevra[relIdx+1:], true
+}
+
+// Arquitetura no fim do nome do pacote do dnf ("openssl.x86_64" -> "x86_64")
+func packageArch(value string) string {
+	idx := strings.LastIndex(value, ".")
+	if idx < 0 { return "" }
+	return value[idx+1:]
+}
+
+// Data de modificação mais recente entre os arquivos que casam com os padrões
+func newestModTime(patterns ...string) string {
+	var newest time.Time
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && info.ModTime().After(newest) { newest = info.ModTime() }
+		}
+	}
+	if newest.IsZero() { return "" }
+	return newest.Format("2006-01-02T15:04:05")
+}
+
+// Data de modificação do primeiro caminho existente
+func fileModTime(paths ...string) string {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil { return info.ModTime().Format("2006-01-02T15:04:05") }
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeHotfixDate(t *testing.T) {
+	cases := []struct {
+		value    string
+		dayFirst bool
+		want     string
+	}{
+		{"3/14/2024", false, "2024-03-14"},
+		{"03/04/2024", false, "2024-03-04"},
+		{"03/04/2024", true, "2024-04-03"},
+		{"14/3/2024", true, "2024-03-14"},
+		{"2024-03-14", true, "2024-03-14"},
+		{"20240314", false, "2024-03-14"},
+		{"01d9a3c5e8b2c000", false, "2023-06-20"},
+		{"", false, ""},
+		{"sem data", false, "sem data"},
+	}
+	for _, c := range cases {
+		if got := normalizeHotfixDate(c.value, c.dayFirst); got != c.want { t.Errorf("normalizeHotfixDate(%q, %v) = %q, esperado %q", c.value, c.dayFirst, got, c.want) }
+	}
+}
+
+func TestHotfixDatesDayFirst(t *testing.T) {
+	cases := []struct {
+		name    string
+		values  []string
+		pattern string
+		want    bool
+	}{
+		{"dia acima de 12 na lista", []string{"05/03/2024", "20/02/2024"}, "", true},
+		{"mês/dia americano", []string{"05/03/2024", "2/20/2024"}, "dd/MM/yyyy", false},
+		{"ambíguo, cultura pt-BR", []string{"05/03/2024", "01/02/2024"}, "dd/MM/yyyy", true},
+		{"ambíguo, cultura en-US", []string{"05/03/2024"}, "M/d/yyyy", false},
+		{"ambíguo, sem cultura", []string{"05/03/2024"}, "", false},
+	}
+	for _, c := range cases {
+		if got := hotfixDatesDayFirst(c.values, c.pattern); got != c.want { t.Errorf("%s: hotfixDatesDayFirst = %v, esperado %v", c.name, got, c.want) }
+	}
+}
+
+func TestInstalledHotfixesSortedByDate(t *testing.T) {
+	hotfixes := []Win32_QuickFixEngineering{
+		{HotFixID: "KB5034441", InstalledOn: "10/01/2024"},
+		{HotFixID: "KB5035845", InstalledOn: "13/03/2024"},
+		{HotFixID: "KB5034763", InstalledOn: "02/02/2024"},
+	}
+	var ids []string
+	for _, h := range installedHotfixes(hotfixes, "dd/MM/yyyy") { ids = append(ids, h.HotfixID+"@"+h.InstalledOn) }
+	want := []string{"KB5035845@2024-03-13", "KB5034763@2024-02-02", "KB5034441@2024-01-10"}
+	if !reflect.DeepEqual(ids, want) { t.Errorf("hotfixes = %v, esperado %v", ids, want) }
+}
+
+func TestParseAptUpgradable(t *testing.T) {
+	output := `Listing... Done
+openssl/jammy-security,jammy-updates 3.0.2-0ubuntu1.15 amd64 [upgradable from: 3.0.2-0ubuntu1.14]
+firefox/jammy-updates 1:1snap1-0ubuntu2 amd64 [upgradable from: 1:1snap1-0ubuntu1]
+libssl3/jammy-security 3.0.2-0ubuntu1.15 amd64 [upgradable from: 3.0.2-0ubuntu1.14]
+`
+	want := []PendingUpdate{
+		{Title: "openssl", Package: "openssl", Version: "3.0.2-0ubuntu1.15", KB: []string{}, IsSecurity: true, Severity: "security"},
+		{Title: "firefox", Package: "firefox", Version: "1:1snap1-0ubuntu2", KB: []string{}},
+		{Title: "libssl3", Package: "libssl3", Version: "3.0.2-0ubuntu1.15", KB: []string{}, IsSecurity: true, Severity: "security"},
+	}
+	if got := parseAptUpgradable(output); !reflect.DeepEqual(got, want) { t.Errorf("parseAptUpgradable() = %+v\nesperado %+v", got, want) }
+
+	// Sem LC_ALL=C (versões antigas do agente), o apt responde na localidade do sistema
+	ptBR := `Listando... Pronto
+WARNING: apt does not have a stable CLI interface. Use with caution in scripts.
+
+openssl/jammy-security,jammy-updates 3.0.2-0ubuntu1.15 amd64 [atualizável a partir de: 3.0.2-0ubuntu1.14]
+firefox/jammy-updates 1:1snap1-0ubuntu2 amd64 [atualizável a partir de: 1:1snap1-0ubuntu1]
+libssl3/jammy-security 3.0.2-0ubuntu1.15 amd64 [atualizável a partir de: 3.0.2-0ubuntu1.14]
+N: Existe 1 versão adicional. Por favor use a opção '-a' para vê-la.
+`
+	if got := parseAptUpgradable(ptBR); !reflect.DeepEqual(got, want) { t.Errorf("parseAptUpgradable(pt_BR) = %+v\nesperado %+v", got, want) }
+}
+
+func TestParseNEVRA(t *testing.T) {
+	cases := []struct {
+		nevra                  string
+		name, version, release string
+		ok                     bool
+	}{
+		{"openssl-libs-3.1.4-1.fc39.x86_64", "openssl-libs", "3.1.4", "1.fc39", true},
+		{"openssl-1:3.1.4-1.fc39.x86_64", "openssl", "1:3.1.4", "1.fc39", true},
+		{"kernel-core-6.8.9-300.fc40.x86_64", "kernel-core", "6.8.9", "300.fc40", true},
+		{"python3-3.12.3-2.fc40.noarch", "python3", "3.12.3", "2.fc40", true},
+		{"semversao.x86_64", "", "", "", false},
+	}
+	for _, c := range cases {
+		name, version, release, ok := parseNEVRA(c.nevra)
+		if name != c.name || version != c.version || release != c.release || ok != c.ok {
+			t.Errorf("parseNEVRA(%q) = %q, %q, %q, %v", c.nevra, name, version, release, ok)
+		}
+	}
+}
+
+func TestParseDnfSecurityMatchesExactName(t *testing.T) {
+	advisories := `FEDORA-2024-1a2b3c Important/Sec. openssl-libs-1:3.1.4-1.fc39.x86_64
+FEDORA-2024-4d5e6f Moderate/Sec.  curl-8.2.1-4.fc39.x86_64
+`
+	checkUpdate := `
+openssl.x86_64                1:3.1.4-1.fc39       updates
+openssl-libs.x86_64           1:3.1.4-1.fc39       updates
+curl.x86_64                   8.2.1-4.fc39         updates
+curl-minimal.x86_64           8.2.1-4.fc39         updates
+Obsoleting Packages
+`
+	security := parseDnfSecurityAdvisories(advisories)
+	if !reflect.DeepEqual(security, map[string]string{"openssl-libs": "Important", "curl": "Moderate"}) { t.Fatalf("avisos = %v", security) }
+
+	got := map[string]string{}
+	for _, u := range parseDnfCheckUpdate(checkUpdate, security) {
+		if u.IsSecurity { got[u.Package] = u.Severity }
+	}
+	want := map[string]string{"openssl-libs.x86_64": "Important", "curl.x86_64": "Moderate"}
+	if !reflect.DeepEqual(got, want) { t.Errorf("pacotes de segurança = %v, esperado %v", got, want) }
+}
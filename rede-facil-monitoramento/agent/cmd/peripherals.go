@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+type MonitorInfo struct {
+	Manufacturer      string `json:"manufacturer"`
+	ManufacturerCode  string `json:"manufacturer_code"`
+	Model             string `json:"model"`
+	ProductCode       string `json:"product_code"`
+	SerialNumber      string `json:"serial_number"`
+	YearOfManufacture int    `json:"year_of_manufacture"`
+	Connector         string `json:"connector"`
+}
+
+type USBDevice struct {
+	VendorID     string `json:"vendor_id"`
+	ProductID    string `json:"product_id"`
+	Description  string `json:"description"`
+	Manufacturer string `json:"manufacturer"`
+	SerialNumber string `json:"serial_number"`
+	DeviceClass  string `json:"device_class"`
+}
+
+type PrinterInfo struct {
+	Name      string `json:"name"`
+	Driver    string `json:"driver"`
+	Port      string `json:"port"`
+	IsDefault bool   `json:"is_default"`
+	IsShared  bool   `json:"is_shared"`
+	ShareName string `json:"share_name"`
+	IsNetwork bool   `json:"is_network"`
+	IsOffline bool   `json:"is_offline"`
+}
+
+// Códigos PNP de fabricante (3 letras do EDID) mais comuns nas filiais
+var edidVendors = map[string]string{
+	"ACR": "Acer",
+	"AOC": "AOC",
+	"AUO": "AU Optronics",
+	"AUS": "ASUS",
+	"BNQ": "BenQ",
+	"BOE": "BOE",
+	"CMN": "Chimei Innolux",
+	"DEL": "Dell",
+	"GSM": "LG",
+	"HWP": "HP",
+	"HPN": "HP",
+	"IVM": "Iiyama",
+	"LEN": "Lenovo",
+	"LGD": "LG Display",
+	"PHL": "Philips",
+	"SAM": "Samsung",
+	"SDC": "Samsung Display",
+	"SHP": "Sharp",
+	"SNY": "Sony",
+	"VSC": "ViewSonic",
+}
+
+var usbIDPattern = regexp.MustCompile(`(?i)VID_([0-9A-F]{4})&PID_([0-9A-F]{4})(?:\\([^\\]+))?`)
+
+// --- Classes WMI ---
+
+// Dados do EDID decodificados pelo Windows (namespace root\WMI)
+type WmiMonitorID struct {
+	InstanceName      string
+	Active            bool
+	ManufacturerName  []uint16
+	ProductCodeID     []uint16
+	SerialNumberID    []uint16
+	UserFriendlyName  []uint16
+	YearOfManufacture uint16
+}
+
+type Win32_PnPEntity struct {
+	Name         string
+	DeviceID     string
+	Manufacturer string
+	PNPClass     string
+}
+
+type Win32_Printer struct {
+	Name        string
+	DriverName  string
+	PortName    string
+	Default     bool
+	Shared      bool
+	ShareName   string
+	Network     bool
+	WorkOffline bool
+}
+
+func collectMonitors(ctx context.Context) (interface{}, error) {
+	if runtime.GOOS == "windows" { return windowsMonitors() }
+	return linuxMonitors(), nil
+}
+
+func windowsMonitors() ([]MonitorInfo, error) {
+	var ids []WmiMonitorID
+	if err := queryWMINamespace(&ids, "Active = TRUE", `root\WMI`); err != nil { return nil, err }
+
+	monitors := []MonitorInfo{}
+	for _, id := range ids {
+		code := wmiString(id.ManufacturerName)
+		m := MonitorInfo{
+			ManufacturerCode:  code,
+			Manufacturer:      edidVendorName(code),
+			Model:             wmiString(id.UserFriendlyName),
+			ProductCode:       wmiString(id.ProductCodeID),
+			SerialNumber:      wmiString(id.SerialNumberID),
+			YearOfManufacture: int(id.YearOfManufacture),
+			Connector:         id.InstanceName,
+		}
+		// Painel interno do notebook normalmente não tem nome no EDID
+		if m.Model == "" { m.Model = m.ProductCode }
+		monitors = append(monitors, m)
+	}
+	return monitors, nil
+}
+
+// Os campos do WmiMonitorID são arrays de caracteres terminados em zero
+func wmiString(chars []uint16) string {
+	var b strings.Builder
+	for _, c := range chars {
+		if c == 0 { break }
+		b.WriteRune(rune(c))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func edidVendorName(code string) string {
+	if name, ok := edidVendors[code]; ok { return name }
+	return code
+}
+
+func linuxMonitors() []MonitorInfo {
+	monitors := []MonitorInfo{}
+	connectors, _ := filepath.Glob("/sys/class/drm/card*-*")
+	for _, dir := range connectors {
+		if readSysfsString(filepath.Join(dir, "status")) != "connected" { continue }
+		edid, err := os.ReadFile(filepath.Join(dir, "edid"))
+		if err != nil { continue }
+		m, ok := parseEDID(edid)
+		if !ok { continue }
+		m.Connector = strings.SplitN(filepath.Base(dir), "-", 2)[1]
+		monitors = append(monitors, m)
+	}
+	return monitors
+}
+
+// Bloco base do EDID (128 bytes): fabricante, código do produto, serial numérico, ano e
+// quatro descritores de 18 bytes, onde ficam o nome (0xFC) e o serial em texto (0xFF)
+func parseEDID(edid []byte) (MonitorInfo, bool) {
+	if len(edid) < 128 || binary.BigEndian.Uint64(edid[0:8]) != 0x00FFFFFFFFFFFF00 { return MonitorInfo{}, false }
+
+	packed := binary.BigEndian.Uint16(edid[8:10])
+	code := string([]byte{
+		byte('A' - 1 + (packed>>10)&0x1F),
+		byte('A' - 1 + (packed>>5)&0x1F),
+		byte('A' - 1 + packed&0x1F),
+	})
+	m := MonitorInfo{
+		ManufacturerCode:  code,
+		Manufacturer:      edidVendorName(code),
+		ProductCode:       fmt.Sprintf("%04X", binary.LittleEndian.Uint16(edid[10:12])),
+		YearOfManufacture: 1990 + int(edid[17]),
+	}
+	if serial := binary.LittleEndian.Uint32(edid[12:16]); serial != 0 { m.SerialNumber = fmt.Sprintf("%d", serial) }
+
+	for offset := 54; offset+18 <= 126; offset += 18 {
+		d := edid[offset : offset+18]
+		if d[0] != 0 || d[1] != 0 || d[2] != 0 { continue }
+		text := strings.TrimSpace(strings.SplitN(string(d[5:18]), "\n", 2)[0])
+		switch d[3] {
+		case 0xFC:
+			m.Model = text
+		case 0xFF:
+			m.SerialNumber = text
+		}
+	}
+	if m.Model == "" { m.Model = m.ProductCode }
+	return m, true
+}
+
+func collectUSBDevices(ctx context.Context) (interface{}, error) {
+	if runtime.GOOS == "windows" { return windowsUSBDevices() }
+	return linuxUSBDevices(), nil
+}
+
+func windowsUSBDevices() ([]USBDevice, error) {
+	var entities []Win32_PnPEntity
+	if err := queryWMI(&entities, "DeviceID LIKE 'USB%'"); err != nil { return nil, err }
+
+	devices := []USBDevice{}
+	for _, e := range entities {
+		// Interfaces de um dispositivo composto (&MI_xx) repetem o mesmo VID/PID
+		if strings.Contains(strings.ToUpper(e.DeviceID), "&MI_") { continue }
+		match := usbIDPattern.FindStringSubmatch(e.DeviceID)
+		if match == nil { continue }
+		d := USBDevice{
+			VendorID:     strings.ToLower(match[1]),
+			ProductID:    strings.ToLower(match[2]),
+			Description:  e.Name,
+			Manufacturer: e.Manufacturer,
+			DeviceClass:  e.PNPClass,
+		}
+		// Quando o dispositivo tem serial, ele é o último trecho do ID; sem serial o Windows gera um com "&"
+		if len(match) > 3 && !strings.Contains(match[3], "&") { d.SerialNumber = match[3] }
+		devices = append(devices, d)
+	}
+	sortUSBDevices(devices)
+	return devices, nil
+}
+
+func linuxUSBDevices() []USBDevice {
+	devices := []USBDevice{}
+	dirs, _ := filepath.Glob("/sys/bus/usb/devices/*")
+	for _, dir := range dirs {
+		vendor := readSysfsString(filepath.Join(dir, "idVendor"))
+		if vendor == "" || vendor == "1d6b" { continue } // 1d6b = hubs raiz do kernel
+		devices = append(devices, USBDevice{
+			VendorID:     vendor,
+			ProductID:    readSysfsString(filepath.Join(dir, "idProduct")),
+			Description:  readSysfsString(filepath.Join(dir, "product")),
+			Manufacturer: readSysfsString(filepath.Join(dir, "manufacturer")),
+			SerialNumber: readSysfsString(filepath.Join(dir, "serial")),
+			DeviceClass:  readSysfsString(filepath.Join(dir, "bDeviceClass")),
+		})
+	}
+	sortUSBDevices(devices)
+	return devices
+}
+
+func sortUSBDevices(devices []USBDevice) {
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].VendorID+devices[i].ProductID < devices[j].VendorID+devices[j].ProductID
+	})
+}
+
+func collectPrinters(ctx context.Context) (interface{}, error) {
+	if runtime.GOOS == "windows" { return windowsPrinters() }
+	return linuxPrinters(), nil
+}
+
+func windowsPrinters() ([]PrinterInfo, error) {
+	var printers []Win32_Printer
+	if err := queryWMI(&printers, ""); err != nil { return nil, err }
+
+	list := []PrinterInfo{}
+	for _, p := range printers {
+		list = append(list, PrinterInfo{
+			Name:      p.Name,
+			Driver:    p.DriverName,
+			Port:      p.PortName,
+			IsDefault: p.Default,
+			IsShared:  p.Shared,
+			ShareName: p.ShareName,
+			IsNetwork: p.Network,
+			IsOffline: p.WorkOffline,
+		})
+	}
+	return list, nil
+}
+
+// Impressoras do CUPS: "lpstat -v" lista as filas e as URIs; o driver e o compartilhamento vêm do lpoptions
+func linuxPrinters() []PrinterInfo {
+	list := []PrinterInfo{}
+	if _, err := exec.LookPath("lpstat"); err != nil { return list }
+	output, err := runCommandHidden("lpstat", "-v")
+	if err != nil { return list }
+
+	defaultPrinter := ""
+	if out, err := runCommandHidden("lpstat", "-d"); err == nil {
+		if idx := strings.LastIndex(out, ":"); idx >= 0 { defaultPrinter = strings.TrimSpace(out[idx+1:]) }
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		// "device for Nome: ipp://10.0.0.5/ipp/print"
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "device for ") { continue }
+		rest := strings.TrimPrefix(line, "device for ")
+		idx := strings.Index(rest, ":")
+		if idx < 0 { continue }
+		p := PrinterInfo{Name: rest[:idx], Port: strings.TrimSpace(rest[idx+1:])}
+		p.IsDefault = p.Name == defaultPrinter
+		p.IsNetwork = !strings.HasPrefix(p.Port, "usb:") && !strings.HasPrefix(p.Port, "parallel:") && strings.Contains(p.Port, "://")
+
+		if opts, err := runCommandHidden("lpoptions", "-p", p.Name); err == nil {
+			p.Driver = lpOption(opts, "printer-make-and-model")
+			p.IsShared = lpOption(opts, "printer-is-shared") == "true"
+			p.IsOffline = lpOption(opts, "printer-state") == "5"
+		}
+		list = append(list, p)
+	}
+	return list
+}
+
+// Lê chave=valor ou chave='valor com espaços' da saída do lpoptions
+func lpOption(output string, key string) string {
+	// A chave precisa começar no início ou depois de espaço: "printer-state=" não pode casar com "x-printer-state="
+	idx := -1
+	for from := 0; from < len(output); {
+		found := strings.Index(output[from:], key+"=")
+		if found < 0 { break }
+		found += from
+		if found == 0 || output[found-1] == ' ' {
+			idx = found
+			break
+		}
+		from = found + 1
+	}
+	if idx < 0 { return "" }
+	value := output[idx+len(key)+1:]
+	if strings.HasPrefix(value, "'") {
+		if end := strings.Index(value[1:], "'"); end >= 0 { return value[1 : end+1] }
+	}
+	fields := strings.Fields(value)
+	if len(fields) == 0 { return "" }
+	return fields[0]
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// EDID base de um Dell P2419H: fabricante DEL, produto A0C5, ano 2019, nome e serial nos descritores
+func testEDID() []byte {
+	edid := make([]byte, 128)
+	copy(edid, []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00})
+	binary.BigEndian.PutUint16(edid[8:10], 0x10AC)
+	binary.LittleEndian.PutUint16(edid[10:12], 0xA0C5)
+	binary.LittleEndian.PutUint32(edid[12:16], 0x4C4C5130)
+	edid[16], edid[17] = 10, 29
+
+	descriptor := func(offset int, tag byte, text string) {
+		copy(edid[offset:offset+5], []byte{0, 0, 0, tag, 0})
+		payload := []byte(text + "\n            ")
+		copy(edid[offset+5:offset+18], payload[:13])
+	}
+	copy(edid[54:72], []byte{0x02, 0x3A}) // timing detalhado: não é descritor de texto
+	descriptor(72, 0xFC, "DELL P2419H")
+	descriptor(90, 0xFF, "CFV9N97A0QLL")
+	descriptor(108, 0xFD, "")
+	return edid
+}
+
+func TestParseEDID(t *testing.T) {
+	want := MonitorInfo{Manufacturer: "Dell", ManufacturerCode: "DEL", Model: "DELL P2419H", ProductCode: "A0C5", SerialNumber: "CFV9N97A0QLL", YearOfManufacture: 2019}
+	got, ok := parseEDID(testEDID())
+	if !ok || !reflect.DeepEqual(got, want) { t.Errorf("parseEDID = %+v, %v\nesperado %+v", got, ok, want) }
+
+	// Painel de notebook: sem nome nem serial em texto
+	panel := testEDID()
+	for offset := 72; offset < 126; offset += 18 { panel[offset+3] = 0x10 }
+	got, ok = parseEDID(panel)
+	if !ok || got.Model != "A0C5" || got.SerialNumber != "1280069936" { t.Errorf("parseEDID(painel) = %+v, %v", got, ok) }
+
+	truncated := testEDID()[:100]
+	if _, ok := parseEDID(truncated); ok { t.Error("EDID truncado deveria ser rejeitado") }
+	badHeader := testEDID()
+	badHeader[0] = 0xFF
+	if _, ok := parseEDID(badHeader); ok { t.Error("EDID com cabeçalho inválido deveria ser rejeitado") }
+}
+
+func TestEDIDVendorName(t *testing.T) {
+	cases := map[string]string{"GSM": "LG", "HWP": "HP", "SAM": "Samsung", "XYZ": "XYZ", "": ""}
+	for code, want := range cases {
+		if got := edidVendorName(code); got != want { t.Errorf("edidVendorName(%q) = %q, esperado %q", code, got, want) }
+	}
+}
+
+func TestLpOption(t *testing.T) {
+	output := "copies=1 device-uri=ipp://10.0.0.5/ipp/print finishings=3 marker-printer-state=3 printer-info='Recepcao 2' printer-is-shared=true printer-make-and-model='HP LaserJet Pro M404-M405' printer-state=5 printer-type=8425476"
+	cases := []struct{ key, want string }{
+		{"printer-make-and-model", "HP LaserJet Pro M404-M405"},
+		{"printer-info", "Recepcao 2"},
+		{"printer-is-shared", "true"},
+		{"printer-state", "5"},
+		{"device-uri", "ipp://10.0.0.5/ipp/print"},
+		{"copies", "1"},
+		{"printer-location", ""},
+	}
+	for _, c := range cases {
+		if got := lpOption(output, c.key); got != c.want { t.Errorf("lpOption(%s) = %q, esperado %q", c.key, got, c.want) }
+	}
+	if got := lpOption("printer-info=", "printer-info"); got != "" { t.Errorf("valor vazio = %q", got) }
+}
+
+func TestWmiString(t *testing.T) {
+	chars := []uint16{'D', 'E', 'L', 'L', ' ', 'P', '2', '4', '1', '9', 'H', 0, 0, 0}
+	if got := wmiString(chars); got != "DELL P2419H" { t.Errorf("wmiString = %q", got) }
+	if got := wmiString(nil); got != "" { t.Errorf("wmiString(nil) = %q", got) }
+}
+
+func TestWindowsMonitors(t *testing.T) {
+	name := func(s string) []uint16 {
+		var chars []uint16
+		for _, r := range s { chars = append(chars, uint16(r)) }
+		return append(chars, 0, 0)
+	}
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"WmiMonitorID": []WmiMonitorID{
+			{InstanceName: `DISPLAY\DELA0C5\5&1a2b3c&0&UID4352_0`, Active: true, ManufacturerName: name("DEL"), ProductCodeID: name("A0C5"), SerialNumberID: name("CFV9N97A0QLL"), UserFriendlyName: name("DELL P2419H"), YearOfManufacture: 2019},
+			{InstanceName: `DISPLAY\LGD05E5\4&2b3c4d&0&UID265988_0`, Active: true, ManufacturerName: name("LGD"), ProductCodeID: name("05E5"), SerialNumberID: name("0"), YearOfManufacture: 2017},
+		},
+	}})
+
+	monitors, err := windowsMonitors()
+	want := []MonitorInfo{
+		{Manufacturer: "Dell", ManufacturerCode: "DEL", Model: "DELL P2419H", ProductCode: "A0C5", SerialNumber: "CFV9N97A0QLL", YearOfManufacture: 2019, Connector: `DISPLAY\DELA0C5\5&1a2b3c&0&UID4352_0`},
+		{Manufacturer: "LG Display", ManufacturerCode: "LGD", Model: "05E5", ProductCode: "05E5", SerialNumber: "0", YearOfManufacture: 2017, Connector: `DISPLAY\LGD05E5\4&2b3c4d&0&UID265988_0`},
+	}
+	if err != nil || !reflect.DeepEqual(monitors, want) { t.Errorf("windowsMonitors = %+v, %v\nesperado %+v", monitors, err, want) }
+}
+
+func TestWindowsUSBDevices(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"Win32_PnPEntity": []Win32_PnPEntity{
+			{Name: "Leitor de código de barras", DeviceID: `USB\VID_0C2E&PID_0B61\14168B0512`, Manufacturer: "Honeywell", PNPClass: "HIDClass"},
+			{Name: "Dispositivo composto USB", DeviceID: `USB\VID_046D&PID_C52B\5&2A7E8B1&0&2`, Manufacturer: "Logitech", PNPClass: "USB"},
+			{Name: "Receptor Unifying", DeviceID: `USB\VID_046D&PID_C52B&MI_00\6&1F2E3D&0&0000`, Manufacturer: "Logitech", PNPClass: "HIDClass"},
+			{Name: "Hub raiz", DeviceID: `USB\ROOT_HUB30\4&3A2B1C&0&0`, PNPClass: "USB"},
+		},
+	}})
+
+	devices, err := windowsUSBDevices()
+	want := []USBDevice{
+		{VendorID: "046d", ProductID: "c52b", Description: "Dispositivo composto USB", Manufacturer: "Logitech", DeviceClass: "USB"},
+		{VendorID: "0c2e", ProductID: "0b61", Description: "Leitor de código de barras", Manufacturer: "Honeywell", SerialNumber: "14168B0512", DeviceClass: "HIDClass"},
+	}
+	if err != nil || !reflect.DeepEqual(devices, want) { t.Errorf("windowsUSBDevices = %+v, %v\nesperado %+v", devices, err, want) }
+
+	useFakeWMI(t, &fakeWMI{Errors: map[string]error{"Win32_PnPEntity": errors.New("acesso negado")}})
+	if _, err := windowsUSBDevices(); err == nil { t.Error("erro do WMI deveria ser devolvido") }
+}
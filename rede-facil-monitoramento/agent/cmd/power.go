@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Padrões do fluxo de reinício/desligamento com aviso ao usuário
+const DEFAULT_POWER_COUNTDOWN = 300
+const DEFAULT_MAX_POSTPONES = 3
+const DEFAULT_POSTPONE_MINUTES = 15
+
+// Resultados reportados ao servidor
+const (
+	POWER_OUTCOME_DONE      = "done"
+	POWER_OUTCOME_POSTPONED = "postponed"
+	POWER_OUTCOME_CANCELLED = "cancelled"
+)
+
+// Payload dos comandos shutdown / restart (todos os campos são opcionais)
+type PowerRequest struct {
+	CountdownSeconds int    `json:"countdown_seconds"`
+	MaxPostpones     *int   `json:"max_postpones"` // ausente = padrão; 0 = sem adiamento
+	PostponeMinutes  int    `json:"postpone_minutes"`
+	Deadline         string `json:"deadline"` // RFC3339; depois dele não é mais possível adiar
+	Message          string `json:"message"`
+}
+
+type PowerActionReport struct {
+	MachineUUID string `json:"machine_uuid"`
+	Action      string `json:"action"`
+	Outcome     string `json:"outcome"`
+	Postpones   int    `json:"postpones"`
+	Reason      string `json:"reason"`
+}
+
+type powerAction struct {
+	action       string
+	req          PowerRequest
+	deadline     time.Time
+	maxPostpones int
+	postpones    int
+	round        int
+	nowCh        chan bool
+	postpone     chan bool
+	cancel       chan bool
+}
+
+var activePowerAction *powerAction
+var powerMutex sync.Mutex
+
+func signalPowerAction(pick func(pa *powerAction) chan bool) {
+	powerMutex.Lock()
+	pa := activePowerAction
+	powerMutex.Unlock()
+	if pa == nil { return }
+	select {
+	case pick(pa) <- true:
+	default:
+	}
+}
+
+func powerActionLabel(action string) string {
+	if action == "restart" { return "reiniciado" }
+	return "desligado"
+}
+
+func handlePowerCommand(action string, payload string) {
+	var req PowerRequest
+	if payload != "" {
+		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			log.Printf("⚠️ Payload de %s inválido, usando padrões: %v", action, err)
+		}
+	}
+	startPowerAction(action, req, "Solicitado pela TI")
+}
+
+func cancelPowerAction() {
+	powerMutex.Lock()
+	pa := activePowerAction
+	powerMutex.Unlock()
+	if pa == nil {
+		sendCommandResult("", "Nenhuma ação de energia pendente")
+		return
+	}
+	select {
+	case pa.cancel <- true:
+	default:
+	}
+}
+
+// Aplica os padrões do pedido. max_postpones ausente usa o padrão; 0 (ou negativo) não permite adiar.
+func newPowerAction(action string, req PowerRequest) *powerAction {
+	if req.CountdownSeconds <= 0 { req.CountdownSeconds = DEFAULT_POWER_COUNTDOWN }
+	if req.PostponeMinutes <= 0 { req.PostponeMinutes = DEFAULT_POSTPONE_MINUTES }
+
+	pa := &powerAction{
+		action:       action,
+		req:          req,
+		maxPostpones: DEFAULT_MAX_POSTPONES,
+		nowCh:        make(chan bool, 1),
+		postpone:     make(chan bool, 1),
+		cancel:       make(chan bool, 1),
+	}
+	if req.MaxPostpones != nil {
+		pa.maxPostpones = *req.MaxPostpones
+		if pa.maxPostpones < 0 { pa.maxPostpones = 0 }
+	}
+	if req.Deadline != "" {
+		if d, err := time.Parse(time.RFC3339, req.Deadline); err == nil { pa.deadline = d }
+	}
+	return pa
+}
+
+// Ainda há adiamentos e o próximo não passa do prazo final
+func (pa *powerAction) canPostpone(now time.Time) bool {
+	if pa.postpones >= pa.maxPostpones { return false }
+	if pa.deadline.IsZero() { return true }
+	return !now.Add(time.Duration(pa.req.PostponeMinutes) * time.Minute).After(pa.deadline)
+}
+
+// Inicia a contagem regressiva. Retorna false se já existe uma ação em andamento.
+func startPowerAction(action string, req PowerRequest, reason string) bool {
+	pa := newPowerAction(action, req)
+
+	powerMutex.Lock()
+	if activePowerAction != nil {
+		running := activePowerAction.action
+		powerMutex.Unlock()
+		log.Printf("⚠️ Já existe uma ação de energia em andamento (%s)", running)
+		return false
+	}
+	activePowerAction = pa
+	powerMutex.Unlock()
+
+	go runPowerAction(pa, reason)
+	return true
+}
+
+func runPowerAction(pa *powerAction, reason string) {
+	defer func() {
+		powerMutex.Lock()
+		activePowerAction = nil
+		powerMutex.Unlock()
+		hidePowerTrayItems()
+		if r := recover(); r != nil { log.Printf("⚠️ Erro recuperado na ação de energia: %v", r) }
+	}()
+
+	label := powerActionLabel(pa.action)
+	log.Printf("⏳ %s agendado em %ds (%s)", pa.action, pa.req.CountdownSeconds, reason)
+
+	for {
+		outcome := runPowerCountdown(pa, label)
+		switch outcome {
+		case POWER_OUTCOME_CANCELLED:
+			log.Printf("🚫 %s cancelado", pa.action)
+			reportPowerOutcome(pa, POWER_OUTCOME_CANCELLED, reason)
+			return
+
+		case POWER_OUTCOME_POSTPONED:
+			pa.postpones++
+			wait := time.Duration(pa.req.PostponeMinutes) * time.Minute
+			log.Printf("⏰ %s adiado por %v (%d/%d)", pa.action, wait, pa.postpones, pa.maxPostpones)
+			reportPowerOutcome(pa, POWER_OUTCOME_POSTPONED, reason)
+			hidePowerTrayItems()
+
+			select {
+			case <-time.After(wait):
+			case <-pa.cancel:
+				reportPowerOutcome(pa, POWER_OUTCOME_CANCELLED, reason)
+				return
+			}
+
+		default:
+			reportPowerOutcome(pa, POWER_OUTCOME_DONE, reason)
+			executePowerAction(pa.action)
+			return
+		}
+	}
+}
+
+// Mostra a contagem e espera o usuário, o servidor ou o fim do prazo.
+func runPowerCountdown(pa *powerAction, label string) string {
+	canPostpone := pa.canPostpone(time.Now())
+
+	msg := pa.req.Message
+	if msg == "" { msg = fmt.Sprintf("Este computador será %s pela TI.", label) }
+	remaining := pa.req.CountdownSeconds
+
+	postponeTitle := ""
+	if canPostpone { postponeTitle = fmt.Sprintf("⏰ Adiar %d min (%d restantes)", pa.req.PostponeMinutes, pa.maxPostpones-pa.postpones) }
+	showPowerTrayItems(postponeTitle)
+
+	powerMutex.Lock()
+	pa.round++
+	round := pa.round
+	powerMutex.Unlock()
+	go askPowerConfirmation(pa, round, msg, label, remaining, canPostpone)
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	for remaining > 0 {
+		status := fmt.Sprintf("⏳ Será %s em %02d:%02d", label, remaining/60, remaining%60)
+		setPowerTrayStatus(status)
+
+		select {
+		case <-ticker.C:
+			remaining--
+		case <-pa.nowCh:
+			return POWER_OUTCOME_DONE
+		case <-pa.postpone:
+			if canPostpone { return POWER_OUTCOME_POSTPONED }
+		case <-pa.cancel:
+			return POWER_OUTCOME_CANCELLED
+		}
+	}
+	return POWER_OUTCOME_DONE
+}
+
+// Janela nativa: "Sim" executa agora, "Não" adia (quando permitido)
+func askPowerConfirmation(pa *powerAction, round int, msg string, label string, countdown int, canPostpone bool) {
+	if runtime.GOOS != "windows" { return }
+
+	text := fmt.Sprintf("%s\n\nSalve seus arquivos. O computador será %s automaticamente em %d minuto(s).", msg, label, (countdown+59)/60)
+	if !canPostpone {
+		showNativeMessage("Rede Fácil - TI", text, MB_ICONEXCLAMATION)
+		return
+	}
+	text += fmt.Sprintf("\n\nSim = executar agora\nNão = adiar por %d minutos", pa.req.PostponeMinutes)
+
+	runNow := askNativeYesNo("Rede Fácil - TI", text, MB_ICONEXCLAMATION)
+
+	// A janela pode ter ficado aberta após a contagem já ter terminado
+	powerMutex.Lock()
+	stillActive := activePowerAction == pa && pa.round == round
+	powerMutex.Unlock()
+	if !stillActive { return }
+
+	if runNow {
+		signalPowerAction(func(p *powerAction) chan bool { return p.nowCh })
+	} else {
+		signalPowerAction(func(p *powerAction) chan bool { return p.postpone })
+	}
+}
+
+func executePowerAction(action string) {
+	log.Printf("⏻ Executando %s...", action)
+	if runtime.GOOS == "windows" {
+		flag := "/s"
+		if action == "restart" { flag = "/r" }
+		runCommandHidden("shutdown", flag, "/t", "0", "/f")
+		return
+	}
+	flag := "-h"
+	if action == "restart" { flag = "-r" }
+	runCommandHidden("shutdown", flag, "now")
+}
+
+func reportPowerOutcome(pa *powerAction, outcome string, reason string) {
+	report := PowerActionReport{
+		MachineUUID: getMachineUUID(),
+		Action:      pa.action,
+		Outcome:     outcome,
+		Postpones:   pa.postpones,
+		Reason:      reason,
+	}
+	jsonReport, _ := json.Marshal(report)
+	sendCommandResult(string(jsonReport), "")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestNewPowerActionDefaults(t *testing.T) {
+	cases := []struct {
+		name          string
+		payload       PowerRequest
+		wantCountdown int
+		wantPostpones int
+		wantMinutes   int
+		wantDeadline  bool
+	}{
+		{"payload vazio", PowerRequest{}, DEFAULT_POWER_COUNTDOWN, DEFAULT_MAX_POSTPONES, DEFAULT_POSTPONE_MINUTES, false},
+		{"valores informados", PowerRequest{CountdownSeconds: 60, MaxPostpones: intPtr(1), PostponeMinutes: 5}, 60, 1, 5, false},
+		{"zero adiamentos força a ação", PowerRequest{MaxPostpones: intPtr(0)}, DEFAULT_POWER_COUNTDOWN, 0, DEFAULT_POSTPONE_MINUTES, false},
+		{"adiamentos negativos", PowerRequest{MaxPostpones: intPtr(-1)}, DEFAULT_POWER_COUNTDOWN, 0, DEFAULT_POSTPONE_MINUTES, false},
+		{"contagem e intervalo inválidos", PowerRequest{CountdownSeconds: -5, PostponeMinutes: -1}, DEFAULT_POWER_COUNTDOWN, DEFAULT_MAX_POSTPONES, DEFAULT_POSTPONE_MINUTES, false},
+		{"prazo final", PowerRequest{Deadline: "2026-10-20T18:00:00-03:00"}, DEFAULT_POWER_COUNTDOWN, DEFAULT_MAX_POSTPONES, DEFAULT_POSTPONE_MINUTES, true},
+		{"prazo inválido é ignorado", PowerRequest{Deadline: "amanhã"}, DEFAULT_POWER_COUNTDOWN, DEFAULT_MAX_POSTPONES, DEFAULT_POSTPONE_MINUTES, false},
+	}
+	for _, c := range cases {
+		pa := newPowerAction("restart", c.payload)
+		if pa.req.CountdownSeconds != c.wantCountdown || pa.maxPostpones != c.wantPostpones || pa.req.PostponeMinutes != c.wantMinutes || pa.deadline.IsZero() == c.wantDeadline {
+			t.Errorf("%s: contagem %d, adiamentos %d, intervalo %d, prazo %v", c.name, pa.req.CountdownSeconds, pa.maxPostpones, pa.req.PostponeMinutes, pa.deadline)
+		}
+	}
+}
+
+func TestPowerRequestMaxPostponesFromJSON(t *testing.T) {
+	cases := []struct {
+		payload string
+		want    int
+	}{
+		{`{}`, DEFAULT_MAX_POSTPONES},
+		{`{"max_postpones": 0}`, 0},
+		{`{"max_postpones": 5}`, 5},
+	}
+	for _, c := range cases {
+		var req PowerRequest
+		if err := json.Unmarshal([]byte(c.payload), &req); err != nil { t.Fatal(err) }
+		if got := newPowerAction("shutdown", req).maxPostpones; got != c.want { t.Errorf("%s: %d adiamentos, esperado %d", c.payload, got, c.want) }
+	}
+}
+
+func TestPowerActionCanPostpone(t *testing.T) {
+	now := time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		max       int
+		postpones int
+		deadline  time.Time
+		want      bool
+	}{
+		{"adiamentos disponíveis", 3, 0, time.Time{}, true},
+		{"último adiamento", 3, 2, time.Time{}, true},
+		{"adiamentos esgotados", 3, 3, time.Time{}, false},
+		{"sem adiamento", 0, 0, time.Time{}, false},
+		{"próximo adiamento termina antes do prazo", 3, 0, now.Add(30 * time.Minute), true},
+		{"próximo adiamento termina no prazo", 3, 0, now.Add(15 * time.Minute), true},
+		{"próximo adiamento passa do prazo", 3, 0, now.Add(10 * time.Minute), false},
+		{"prazo já vencido", 3, 0, now.Add(-time.Minute), false},
+	}
+	for _, c := range cases {
+		pa := newPowerAction("restart", PowerRequest{MaxPostpones: intPtr(c.max), PostponeMinutes: 15})
+		pa.postpones = c.postpones
+		pa.deadline = c.deadline
+		if got := pa.canPostpone(now); got != c.want { t.Errorf("%s: canPostpone = %v, esperado %v", c.name, got, c.want) }
+	}
+}
+
+func TestStartPowerActionRejectsConcurrentAction(t *testing.T) {
+	running := newPowerAction("restart", PowerRequest{})
+	powerMutex.Lock()
+	previous := activePowerAction
+	activePowerAction = running
+	powerMutex.Unlock()
+	t.Cleanup(func() {
+		powerMutex.Lock()
+		activePowerAction = previous
+		powerMutex.Unlock()
+	})
+
+	if startPowerAction("shutdown", PowerRequest{}, "teste") { t.Fatal("segunda ação de energia aceita com outra em andamento") }
+	powerMutex.Lock()
+	defer powerMutex.Unlock()
+	if activePowerAction != running { t.Errorf("ação em andamento substituída por %+v", activePowerAction) }
+}
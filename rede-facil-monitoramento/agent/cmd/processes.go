@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const PROCESS_POLICY_FILE = "agente_processos.json"
+const PROCESS_MODE_ALWAYS = "always"
+const PROCESS_MODE_THRESHOLD = "threshold"
+const PROCESS_MAX_TOP_N = 25
+const PROCESS_CMDLINE_MAX_LEN = 512
+
+// Política de envio dos processos (comando set_process_policy)
+type ProcessPolicy struct {
+	TopN               int      `json:"top_n"`
+	Mode               string   `json:"mode"`
+	CPUThreshold       float64  `json:"cpu_threshold"`
+	RAMThreshold       float64  `json:"ram_threshold"`
+	IncludeCommandLine bool     `json:"include_command_line"`
+	IncludeUsername    bool     `json:"include_username"`
+	RedactPatterns     []string `json:"redact_patterns"`
+	ExcludeNames       []string `json:"exclude_names"`
+}
+
+type ProcessUsage struct {
+	PID           int32   `json:"pid"`
+	Name          string  `json:"name"`
+	Username      string  `json:"username,omitempty"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+	MemoryMB      float64 `json:"memory_mb"`
+	CommandLine   string  `json:"command_line,omitempty"`
+}
+
+type TopProcesses struct {
+	Reason   string         `json:"reason"`
+	ByCPU    []ProcessUsage `json:"by_cpu"`
+	ByMemory []ProcessUsage `json:"by_memory"`
+}
+
+// Segredos comuns passados por linha de comando (senha=..., --token ..., -pSENHA, /p:SENHA, usuário:senha@ em URLs)
+var defaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|passwd|pwd|senha|token|secret|apikey|api-key|api_key|auth)\s*[=:\s]\s*)(?:"[^"]*"|'[^']*'|\S+)`),
+	regexp.MustCompile(`(?i)(://[^:/\s]+:)[^@\s]+(@)`),
+	// mysql -uroot -pS3cret: a senha colada na opção (-p sozinho pede a senha e não tem o que ocultar)
+	regexp.MustCompile(`(\s-p)(?:"[^"]*"|'[^']*'|\S+)`),
+	regexp.MustCompile(`(?i)(\s/p(?:ass(?:word)?)?:)(?:"[^"]*"|'[^']*'|\S+)`),
+}
+
+var processPolicy = defaultProcessPolicy()
+var processRedactors []*regexp.Regexp
+var processPolicyLoaded bool
+var processMutex sync.Mutex
+
+// Tempo de CPU acumulado por PID na amostra anterior, para calcular o uso no intervalo
+type processSample struct {
+	cpuSeconds float64
+	createTime int64
+}
+
+var lastProcessSamples map[int32]processSample
+var lastProcessSampleAt time.Time
+
+// Por padrão só envia quando CPU ou RAM passam do limite, e nunca envia linha de comando
+func defaultProcessPolicy() ProcessPolicy {
+	return ProcessPolicy{TopN: 5, Mode: PROCESS_MODE_THRESHOLD, CPUThreshold: 80, RAMThreshold: 85, IncludeUsername: true}
+}
+
+func handleSetProcessPolicy(payload string) {
+	policy := defaultProcessPolicy()
+	if err := json.Unmarshal([]byte(payload), &policy); err != nil {
+		sendCommandResult("", fmt.Sprintf("Payload inválido: %v", err))
+		return
+	}
+	if policy.Mode != PROCESS_MODE_ALWAYS && policy.Mode != PROCESS_MODE_THRESHOLD {
+		sendCommandResult("", fmt.Sprintf("Modo desconhecido: %s", policy.Mode))
+		return
+	}
+	if policy.TopN <= 0 || policy.TopN > PROCESS_MAX_TOP_N {
+		sendCommandResult("", fmt.Sprintf("top_n deve estar entre 1 e %d", PROCESS_MAX_TOP_N))
+		return
+	}
+	redactors, err := compileRedactPatterns(policy.RedactPatterns)
+	if err != nil {
+		sendCommandResult("", err.Error())
+		return
+	}
+
+	processMutex.Lock()
+	processPolicy = policy
+	processRedactors = redactors
+	processPolicyLoaded = true
+	processMutex.Unlock()
+
+	data, _ := json.MarshalIndent(policy, "", "  ")
+	if err := os.WriteFile(getAgentDataPath(PROCESS_POLICY_FILE), data, 0600); err != nil {
+		log.Printf("⚠️ Erro ao salvar política de processos: %v", err)
+	}
+	log.Printf("📊 Política de processos atualizada: top %d, modo %s", policy.TopN, policy.Mode)
+	sendCommandResult(fmt.Sprintf("Política de processos atualizada: top %d, modo %s", policy.TopN, policy.Mode), "")
+}
+
+func compileRedactPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var redactors []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil { return nil, fmt.Errorf("Padrão de ocultação inválido %q: %v", p, err) }
+		redactors = append(redactors, re)
+	}
+	return redactors, nil
+}
+
+func currentProcessPolicy() (ProcessPolicy, []*regexp.Regexp) {
+	processMutex.Lock()
+	defer processMutex.Unlock()
+	if !processPolicyLoaded {
+		processPolicyLoaded = true
+		if data, err := os.ReadFile(getAgentDataPath(PROCESS_POLICY_FILE)); err == nil {
+			policy := defaultProcessPolicy()
+			if err := json.Unmarshal(data, &policy); err == nil && policy.TopN > 0 {
+				if redactors, err := compileRedactPatterns(policy.RedactPatterns); err == nil {
+					processPolicy = policy
+					processRedactors = redactors
+				}
+			}
+		}
+	}
+	return processPolicy, processRedactors
+}
+
+// Amostra todos os processos. O uso de CPU é o delta desde a amostra anterior,
+// normalizado pelo número de núcleos (0-100%, como no Gerenciador de Tarefas).
+func collectProcesses(ctx context.Context) (interface{}, error) {
+	policy, redactors := currentProcessPolicy()
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil { return nil, err }
+
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil { return nil, err }
+
+	excluded := map[string]bool{}
+	for _, name := range policy.ExcludeNames { excluded[strings.ToLower(name)] = true }
+
+	now := time.Now()
+	processMutex.Lock()
+	previous, previousAt := lastProcessSamples, lastProcessSampleAt
+	processMutex.Unlock()
+	elapsed := now.Sub(previousAt).Seconds()
+
+	samples := map[int32]processSample{}
+	var usages []ProcessUsage
+	procByPID := map[int32]*process.Process{}
+	for _, p := range procs {
+		if ctx.Err() != nil { return nil, ctx.Err() }
+		name, err := p.NameWithContext(ctx)
+		if err != nil || name == "" || excluded[strings.ToLower(name)] { continue }
+
+		usage := ProcessUsage{PID: p.Pid, Name: name}
+		if memInfo, err := p.MemoryInfoWithContext(ctx); err == nil && vm.Total > 0 {
+			usage.MemoryMB = math.Round(float64(memInfo.RSS)/(1024*1024)*10) / 10
+			usage.MemoryPercent = math.Round(float64(memInfo.RSS)/float64(vm.Total)*1000) / 10
+		}
+		if times, err := p.TimesWithContext(ctx); err == nil {
+			createTime, _ := p.CreateTimeWithContext(ctx)
+			sample := processSample{cpuSeconds: times.User + times.System, createTime: createTime}
+			samples[p.Pid] = sample
+			// PID reutilizado por outro processo não conta como delta
+			if prev, ok := previous[p.Pid]; ok && prev.createTime == createTime && elapsed > 0 {
+				percent := (sample.cpuSeconds - prev.cpuSeconds) / elapsed / float64(runtime.NumCPU()) * 100
+				usage.CPUPercent = math.Round(math.Max(percent, 0)*10) / 10
+			}
+		}
+		usages = append(usages, usage)
+		procByPID[p.Pid] = p
+	}
+
+	processMutex.Lock()
+	lastProcessSamples = samples
+	lastProcessSampleAt = now
+	processMutex.Unlock()
+
+	top := TopProcesses{
+		ByCPU:    topProcessesBy(usages, policy.TopN, func(a, b ProcessUsage) bool { return a.CPUPercent > b.CPUPercent }),
+		ByMemory: topProcessesBy(usages, policy.TopN, func(a, b ProcessUsage) bool { return a.MemoryMB > b.MemoryMB }),
+	}
+
+	// Usuário e linha de comando só dos que vão no payload (consultas caras no Windows)
+	for _, list := range [][]ProcessUsage{top.ByCPU, top.ByMemory} {
+		for i := range list {
+			p := procByPID[list[i].PID]
+			if policy.IncludeUsername {
+				if username, err := p.UsernameWithContext(ctx); err == nil { list[i].Username = username }
+			}
+			if policy.IncludeCommandLine {
+				if cmdline, err := p.CmdlineWithContext(ctx); err == nil { list[i].CommandLine = redactCommandLine(cmdline, redactors) }
+			}
+		}
+	}
+	return top, nil
+}
+
+func topProcessesBy(usages []ProcessUsage, n int, less func(a, b ProcessUsage) bool) []ProcessUsage {
+	sorted := append([]ProcessUsage(nil), usages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	if len(sorted) > n { sorted = sorted[:n] }
+	return sorted
+}
+
+func redactCommandLine(cmdline string, extra []*regexp.Regexp) string {
+	for _, re := range defaultRedactPatterns { cmdline = re.ReplaceAllString(cmdline, "${1}***${2}") }
+	for _, re := range extra { cmdline = re.ReplaceAllString(cmdline, "***") }
+	if len(cmdline) > PROCESS_CMDLINE_MAX_LEN {
+		// Corta no início de um caractere para não mandar UTF-8 inválido
+		cut := PROCESS_CMDLINE_MAX_LEN
+		for cut > 0 && !utf8.RuneStart(cmdline[cut]) { cut-- }
+		cmdline = cmdline[:cut] + "..."
+	}
+	return cmdline
+}
+
+// Decide se os processos vão nesta telemetria, conforme a política
+func topProcessesForTelemetry(top TopProcesses, cpuPercent float64, ramPercent float64) *TopProcesses {
+	policy, _ := currentProcessPolicy()
+	switch {
+	case policy.Mode == PROCESS_MODE_ALWAYS:
+		top.Reason = "always"
+	case cpuPercent >= policy.CPUThreshold:
+		top.Reason = fmt.Sprintf("cpu >= %.0f%%", policy.CPUThreshold)
+	case ramPercent >= policy.RAMThreshold:
+		top.Reason = fmt.Sprintf("ram >= %.0f%%", policy.RAMThreshold)
+	default:
+		return nil
+	}
+	return &top
+}
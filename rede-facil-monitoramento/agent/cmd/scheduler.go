@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+const PENDING_COMMANDS_FILE = "agente_comandos_pendentes.json"
+const SCHEDULER_INTERVAL = 30 * time.Second
+const DEFAULT_IDLE_THRESHOLD = 300
+
+// Janela de manutenção padrão (pode ser sobrescrita pelo servidor em cada comando)
+const MAINTENANCE_WINDOW_START = "19:00"
+const MAINTENANCE_WINDOW_END = "07:00"
+
+// Modos de execução aceitos em ExecutionCondition.Mode
+const (
+	EXEC_NOW         = "now"
+	EXEC_AT          = "at"
+	EXEC_IDLE        = "idle"
+	EXEC_NEXT_BOOT   = "next_boot"
+	EXEC_MAINTENANCE = "maintenance_window"
+)
+
+// Estados reportados ao servidor
+const (
+	CMD_STATE_PENDING = "pending"
+	CMD_STATE_RUNNING = "running"
+	CMD_STATE_DONE    = "done"
+	CMD_STATE_EXPIRED = "expired"
+)
+
+type ExecutionCondition struct {
+	Mode        string `json:"mode"`
+	RunAt       string `json:"run_at"`       // RFC3339, usado por "at"
+	IdleSeconds uint32 `json:"idle_seconds"` // usado por "idle"
+	WindowStart string `json:"window_start"` // HH:MM, usado por "maintenance_window"
+	WindowEnd   string `json:"window_end"`
+	ExpiresAt   string `json:"expires_at"`   // RFC3339, opcional
+}
+
+type PendingCommand struct {
+	ID         string             `json:"id"`
+	Command    string             `json:"command"`
+	Payload    string             `json:"payload"`
+	Condition  ExecutionCondition `json:"condition"`
+	State      string             `json:"state"`
+	ReceivedAt time.Time          `json:"received_at"`
+	BootTime   uint64             `json:"boot_time"`
+}
+
+type CommandStateReport struct {
+	MachineUUID string `json:"machine_uuid"`
+	CommandID   string `json:"command_id"`
+	Command     string `json:"command"`
+	Mode        string `json:"mode"`
+	State       string `json:"state"`
+	Detail      string `json:"detail"`
+}
+
+var pendingCommands []PendingCommand
+var pendingMutex sync.Mutex
+var pendingRestoreOnce sync.Once
+
+// Caminho de arquivos de estado do agente, ao lado do executável
+func getAgentDataPath(fileName string) string {
+	exePath, err := os.Executable()
+	if err != nil { return fileName }
+	return filepath.Join(filepath.Dir(exePath), fileName)
+}
+
+// Decide se o comando roda agora ou vai para a fila de execução adiada
+func dispatchServerCommand(resp ServerResponse) {
+	mode := resp.Condition.Mode
+	if mode == "" || mode == EXEC_NOW {
+		handleRemoteCommand(resp.Command, resp.Payload)
+		return
+	}
+
+	switch mode {
+	case EXEC_AT, EXEC_IDLE, EXEC_NEXT_BOOT, EXEC_MAINTENANCE:
+	default:
+		log.Printf("❓ Condição de execução desconhecida: %s", mode)
+		sendCommandResult("", fmt.Sprintf("Condição de execução desconhecida: %s", mode))
+		return
+	}
+
+	if mode == EXEC_AT {
+		if _, err := time.Parse(time.RFC3339, resp.Condition.RunAt); err != nil {
+			sendCommandResult("", fmt.Sprintf("run_at inválido: %v", err))
+			return
+		}
+	}
+
+	pc := PendingCommand{
+		ID:         resp.CommandID,
+		Command:    resp.Command,
+		Payload:    resp.Payload,
+		Condition:  resp.Condition,
+		State:      CMD_STATE_PENDING,
+		ReceivedAt: time.Now(),
+		BootTime:   currentBootTime(),
+	}
+	if pc.ID == "" { pc.ID = fmt.Sprintf("%s-%d", pc.Command, pc.ReceivedAt.UnixNano()) }
+
+	pendingMutex.Lock()
+	pendingCommands = append(pendingCommands, pc)
+	savePendingCommands()
+	pendingMutex.Unlock()
+
+	log.Printf("⏳ Comando %s adiado (%s)", pc.Command, mode)
+	reportCommandState(pc, "")
+}
+
+func startCommandScheduler() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado no agendador: %v", r)
+			time.Sleep(SCHEDULER_INTERVAL)
+			go startCommandScheduler()
+		}
+	}()
+
+	restorePendingCommands()
+	for {
+		runDueCommands()
+		time.Sleep(SCHEDULER_INTERVAL)
+	}
+}
+
+func runDueCommands() {
+	pendingMutex.Lock()
+	var due, remaining []PendingCommand
+	now := time.Now()
+	for _, pc := range pendingCommands {
+		if isCommandExpired(pc, now) {
+			pc.State = CMD_STATE_EXPIRED
+			due = append(due, pc)
+			continue
+		}
+		if isCommandDue(pc, now) {
+			pc.State = CMD_STATE_RUNNING
+			due = append(due, pc)
+			continue
+		}
+		remaining = append(remaining, pc)
+	}
+	if len(due) == 0 {
+		pendingMutex.Unlock()
+		return
+	}
+	pendingCommands = remaining
+	savePendingCommands()
+	pendingMutex.Unlock()
+
+	for _, pc := range due {
+		if pc.State == CMD_STATE_EXPIRED {
+			log.Printf("⌛ Comando %s expirou sem executar", pc.Command)
+			reportCommandState(pc, "Prazo expirado antes da condição ser atendida")
+			continue
+		}
+		log.Printf("▶️ Executando comando adiado %s (%s)", pc.Command, pc.Condition.Mode)
+		reportCommandState(pc, "")
+		handleRemoteCommand(pc.Command, pc.Payload)
+		pc.State = CMD_STATE_DONE
+		reportCommandState(pc, "Comando disparado")
+	}
+}
+
+func isCommandExpired(pc PendingCommand, now time.Time) bool {
+	if pc.Condition.ExpiresAt == "" { return false }
+	expires, err := time.Parse(time.RFC3339, pc.Condition.ExpiresAt)
+	return err == nil && now.After(expires)
+}
+
+func isCommandDue(pc PendingCommand, now time.Time) bool {
+	switch pc.Condition.Mode {
+	case EXEC_AT:
+		runAt, err := time.Parse(time.RFC3339, pc.Condition.RunAt)
+		return err == nil && !now.Before(runAt)
+	case EXEC_IDLE:
+		threshold := pc.Condition.IdleSeconds
+		if threshold == 0 { threshold = DEFAULT_IDLE_THRESHOLD }
+		return getIdleTime() >= threshold
+	case EXEC_NEXT_BOOT:
+		boot := currentBootTime()
+		return boot != 0 && boot > pc.BootTime
+	case EXEC_MAINTENANCE:
+		start, end := pc.Condition.WindowStart, pc.Condition.WindowEnd
+		if start == "" { start = MAINTENANCE_WINDOW_START }
+		if end == "" { end = MAINTENANCE_WINDOW_END }
+		return isWithinWindow(now, start, end)
+	}
+	return false
+}
+
+// Verifica se o horário está dentro da janela HH:MM-HH:MM (aceita janelas que cruzam a meia-noite)
+func isWithinWindow(now time.Time, start string, end string) bool {
+	startT, err1 := time.Parse("15:04", start)
+	endT, err2 := time.Parse("15:04", end)
+	if err1 != nil || err2 != nil { return false }
+
+	minutes := now.Hour()*60 + now.Minute()
+	startMin := startT.Hour()*60 + startT.Minute()
+	endMin := endT.Hour()*60 + endT.Minute()
+	if startMin <= endMin { return minutes >= startMin && minutes < endMin }
+	return minutes >= startMin || minutes < endMin
+}
+
+func currentBootTime() uint64 {
+	boot, err := host.BootTime()
+	if err != nil { return 0 }
+	return boot
+}
+
+// Deve ser chamada com pendingMutex travado
+func savePendingCommands() {
+	data, err := json.MarshalIndent(pendingCommands, "", "  ")
+	if err != nil { return }
+	if err := os.WriteFile(getAgentDataPath(PENDING_COMMANDS_FILE), data, 0600); err != nil {
+		log.Printf("⚠️ Erro ao salvar comandos pendentes: %v", err)
+	}
+}
+
+// A fila em disco só é lida na primeira partida; quando o agendador se recupera de um pânico
+// os comandos já estão em memória e ler de novo duplicaria cada um.
+func restorePendingCommands() {
+	pendingRestoreOnce.Do(loadPendingCommands)
+}
+
+func loadPendingCommands() {
+	data, err := os.ReadFile(getAgentDataPath(PENDING_COMMANDS_FILE))
+	if err != nil { return }
+
+	var loaded []PendingCommand
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		log.Printf("⚠️ Arquivo de comandos pendentes inválido: %v", err)
+		return
+	}
+
+	pendingMutex.Lock()
+	pendingCommands = append(loaded, pendingCommands...)
+	pendingMutex.Unlock()
+
+	if len(loaded) > 0 { log.Printf("⏳ %d comando(s) pendente(s) restaurado(s)", len(loaded)) }
+	for _, pc := range loaded { reportCommandState(pc, "Restaurado após reinício do agente") }
+}
+
+// Destino dos estados de comando. Em produção vai para o backend; os testes trocam
+// commandStateReporter por um registrador em memória (scheduler_test.go).
+type CommandStateReporter interface {
+	Report(report CommandStateReport)
+}
+
+type backendCommandStateReporter struct{}
+
+func (backendCommandStateReporter) Report(report CommandStateReport) {
+	go postData(fmt.Sprintf("/machines/%s/command-state", report.MachineUUID), report)
+}
+
+var commandStateReporter CommandStateReporter = backendCommandStateReporter{}
+
+func reportCommandState(pc PendingCommand, detail string) {
+	commandStateReporter.Report(CommandStateReport{
+		MachineUUID: getMachineUUID(),
+		CommandID:   pc.ID,
+		Command:     pc.Command,
+		Mode:        pc.Condition.Mode,
+		State:       pc.State,
+		Detail:      detail,
+	})
+}
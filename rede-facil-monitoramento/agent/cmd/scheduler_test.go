@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingReporter struct {
+	mu      sync.Mutex
+	reports []CommandStateReport
+}
+
+func (r *recordingReporter) Report(report CommandStateReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+}
+
+// Fila vazia e estados registrados em memória, sem chamadas ao backend
+func resetPendingQueue(t *testing.T) *recordingReporter {
+	t.Helper()
+	path := getAgentDataPath(PENDING_COMMANDS_FILE)
+	os.Remove(path)
+	pendingCommands = nil
+	pendingRestoreOnce = sync.Once{}
+
+	recorder := &recordingReporter{}
+	previousReporter := commandStateReporter
+	commandStateReporter = recorder
+	t.Cleanup(func() {
+		os.Remove(path)
+		pendingCommands = nil
+		pendingRestoreOnce = sync.Once{}
+		commandStateReporter = previousReporter
+	})
+	return recorder
+}
+
+func TestPendingCommandsRoundTrip(t *testing.T) {
+	recorder := resetPendingQueue(t)
+
+	queued := []PendingCommand{
+		{ID: "1", Command: "restart", Condition: ExecutionCondition{Mode: EXEC_MAINTENANCE, WindowStart: "22:00", WindowEnd: "05:00"}, State: CMD_STATE_PENDING, ReceivedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), BootTime: 1700000000},
+		{ID: "2", Command: "install_package", Payload: `{"name":"7zip"}`, Condition: ExecutionCondition{Mode: EXEC_AT, RunAt: "2026-03-02T03:00:00Z"}, State: CMD_STATE_PENDING},
+	}
+	pendingCommands = queued
+	savePendingCommands()
+
+	// Agente reiniciado: memória vazia, a fila vem do disco
+	pendingCommands = nil
+	restorePendingCommands()
+	if len(pendingCommands) != len(queued) { t.Fatalf("restaurou %d comandos, esperado %d", len(pendingCommands), len(queued)) }
+	for i, pc := range pendingCommands {
+		want := queued[i]
+		if pc.ID != want.ID || pc.Command != want.Command || pc.Payload != want.Payload || pc.Condition != want.Condition || !pc.ReceivedAt.Equal(want.ReceivedAt) || pc.BootTime != want.BootTime {
+			t.Errorf("comando %d = %+v, esperado %+v", i, pc, want)
+		}
+	}
+
+	// Agendador recuperado de um pânico: não pode ler a fila de novo
+	restorePendingCommands()
+	if len(pendingCommands) != len(queued) { t.Fatalf("após reiniciar o agendador a fila tem %d comandos, esperado %d", len(pendingCommands), len(queued)) }
+
+	// Um aviso de "restaurado" por comando, só na primeira leitura
+	if len(recorder.reports) != len(queued) { t.Fatalf("%d estados reportados, esperado %d", len(recorder.reports), len(queued)) }
+	for i, r := range recorder.reports {
+		if r.CommandID != queued[i].ID || r.State != CMD_STATE_PENDING || r.Mode != queued[i].Condition.Mode { t.Errorf("estado %d = %+v", i, r) }
+	}
+}
+
+func TestRestoreKeepsCommandsReceivedBeforeLoad(t *testing.T) {
+	resetPendingQueue(t)
+
+	pendingCommands = []PendingCommand{{ID: "disk", Command: "restart"}}
+	savePendingCommands()
+	pendingCommands = []PendingCommand{{ID: "new", Command: "shutdown"}}
+
+	restorePendingCommands()
+	if len(pendingCommands) != 2 || pendingCommands[0].ID != "disk" || pendingCommands[1].ID != "new" {
+		t.Fatalf("fila = %+v, esperado [disk new]", pendingCommands)
+	}
+}
+
+func TestIsWithinWindow(t *testing.T) {
+	cases := []struct {
+		now        string
+		start, end string
+		want       bool
+	}{
+		{"10:00", "08:00", "18:00", true},
+		{"18:00", "08:00", "18:00", false},
+		{"07:59", "08:00", "18:00", false},
+		{"23:30", "19:00", "07:00", true},
+		{"06:59", "19:00", "07:00", true},
+		{"12:00", "19:00", "07:00", false},
+		{"12:00", "xx", "07:00", false},
+	}
+	for _, c := range cases {
+		now, _ := time.Parse("15:04", c.now)
+		if got := isWithinWindow(now, c.start, c.end); got != c.want {
+			t.Errorf("isWithinWindow(%s, %s-%s) = %v, esperado %v", c.now, c.start, c.end, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Assinaturas mais antigas que isto reprovam o item de antivírus atualizado
+const SECURITY_SIGNATURE_MAX_AGE_DAYS = 3
+
+// Partida a frio do PowerShell em PCs mais lentos das filiais passa fácil dos 5s do runCommandHidden
+const SECURITY_POWERSHELL_TIMEOUT = 60 * time.Second
+
+type AntivirusProduct struct {
+	Name              string `json:"name"`
+	Enabled           bool   `json:"enabled"`
+	SignaturesUpdated bool   `json:"signatures_updated"`
+	SignatureAgeDays  *int   `json:"signature_age_days"`
+	RealTimeEnabled   *bool  `json:"real_time_enabled"`
+}
+
+type FirewallProfile struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+type VolumeEncryption struct {
+	Volume    string `json:"volume"`
+	Protected bool   `json:"protected"`
+	Status    string `json:"status"`
+}
+
+// Item da auditoria. Passed nil = não se aplica a este sistema ou não pôde ser avaliado (não entra na nota).
+type PostureCheck struct {
+	Name   string `json:"name"`
+	Passed *bool  `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+type SecurityPosture struct {
+	MachineUUID string             `json:"machine_uuid"`
+	CollectedAt string             `json:"collected_at"`
+	Antivirus   []AntivirusProduct `json:"antivirus"`
+	Firewall    []FirewallProfile  `json:"firewall"`
+	Encryption  []VolumeEncryption `json:"encryption"`
+	SecureBoot  *bool              `json:"secure_boot"`
+	TPMPresent  bool               `json:"tpm_present"`
+	TPMVersion  string             `json:"tpm_version"`
+	UACLevel    string             `json:"uac_level"`
+	Checks      []PostureCheck     `json:"checks"`
+	Score       int                `json:"score"`
+	Compliant   bool               `json:"compliant"`
+
+	// Áreas que não puderam ser lidas (ex: consulta WMI sem elevação) e o motivo; viram checks com Passed nil
+	unevaluated map[string]string
+}
+
+// --- Classes WMI ---
+
+// root\SecurityCenter2 (somente em estações; servidores não têm o Security Center)
+type AntiVirusProduct struct {
+	DisplayName  string
+	ProductState uint32
+}
+
+// root\Microsoft\Windows\Defender, a mesma fonte do Get-MpComputerStatus
+type MSFT_MpComputerStatus struct {
+	AntivirusEnabled          bool
+	RealTimeProtectionEnabled bool
+	AntivirusSignatureAge     uint32
+}
+
+// Perfil do Get-NetFirewallProfile. Enabled: 0 = falso, 1 = verdadeiro, 2 = não configurado (herda o padrão, ativo)
+type MSFT_NetFirewallProfile struct {
+	Name    string
+	Enabled uint16
+}
+
+// root\CIMV2\Security\MicrosoftVolumeEncryption
+type Win32_EncryptableVolume struct {
+	DriveLetter      string
+	ProtectionStatus uint32
+	ConversionStatus uint32
+}
+
+// root\CIMV2\Security\MicrosoftTpm
+type Win32_Tpm struct {
+	IsEnabled_InitialValue   bool
+	IsActivated_InitialValue bool
+	SpecVersion              string
+}
+
+var bitlockerConversionStatus = map[uint32]string{0: "decrypted", 1: "encrypted", 2: "encrypting", 3: "decrypting", 4: "encryption_paused", 5: "decryption_paused"}
+
+// Coletor agendado; como o de atualizações, envia o resultado ao backend a cada execução
+func collectSecurityPosture(ctx context.Context) (interface{}, error) {
+	var posture SecurityPosture
+	var errs []error
+	if runtime.GOOS == "windows" {
+		posture, errs = windowsSecurityPosture()
+	} else {
+		posture = linuxSecurityPosture()
+	}
+	posture.MachineUUID = getMachineUUID()
+	posture.CollectedAt = time.Now().Format(time.RFC3339)
+	if posture.Antivirus == nil { posture.Antivirus = []AntivirusProduct{} }
+	if posture.Firewall == nil { posture.Firewall = []FirewallProfile{} }
+	if posture.Encryption == nil { posture.Encryption = []VolumeEncryption{} }
+	scorePosture(&posture)
+
+	go postData("/telemetry/security-posture", posture)
+	return posture, partialFailure(errs)
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+func scorePosture(p *SecurityPosture) {
+	addCheck := func(name string, area string, passed bool, detail string) {
+		if reason, skipped := p.unevaluated[area]; skipped {
+			p.Checks = append(p.Checks, PostureCheck{Name: name, Detail: "Não avaliado: " + reason})
+			return
+		}
+		p.Checks = append(p.Checks, PostureCheck{Name: name, Passed: boolPtr(passed), Detail: detail})
+	}
+
+	var av, avCurrent bool
+	avDetail := "Nenhum antivírus detectado"
+	for _, product := range p.Antivirus {
+		if !product.Enabled { continue }
+		av = true
+		avDetail = product.Name
+		if product.SignaturesUpdated { avCurrent = true }
+	}
+	addCheck("antivirus_enabled", "antivirus", av, avDetail)
+	addCheck("antivirus_signatures_current", "antivirus", avCurrent, fmt.Sprintf("Assinaturas com até %d dia(s)", SECURITY_SIGNATURE_MAX_AGE_DAYS))
+
+	firewall := len(p.Firewall) > 0
+	var off []string
+	for _, profile := range p.Firewall {
+		if !profile.Enabled {
+			firewall = false
+			off = append(off, profile.Name)
+		}
+	}
+	fwDetail := "Todos os perfis ativos"
+	if len(p.Firewall) == 0 { fwDetail = "Firewall não detectado" }
+	if len(off) > 0 { fwDetail = "Desativado: " + strings.Join(off, ", ") }
+	addCheck("firewall_enabled", "firewall", firewall, fwDetail)
+
+	// Basta o volume do sistema estar protegido
+	encrypted := false
+	encDetail := "Volume do sistema sem criptografia"
+	for _, v := range p.Encryption {
+		if strings.EqualFold(v.Volume, systemMountpoint()) && v.Protected {
+			encrypted = true
+			encDetail = fmt.Sprintf("%s: %s", v.Volume, v.Status)
+		}
+	}
+	addCheck("disk_encryption", "encryption", encrypted, encDetail)
+
+	secureBootDetail := "Firmware sem UEFI ou estado indisponível"
+	if p.SecureBoot != nil { secureBootDetail = fmt.Sprintf("Secure Boot ativo: %v", *p.SecureBoot) }
+	p.Checks = append(p.Checks, PostureCheck{Name: "secure_boot", Passed: p.SecureBoot, Detail: secureBootDetail})
+
+	tpmDetail := "TPM não encontrado"
+	if p.TPMPresent { tpmDetail = "TPM " + p.TPMVersion }
+	addCheck("tpm_present", "tpm", p.TPMPresent, tpmDetail)
+
+	if p.UACLevel != "not_applicable" {
+		if p.UACLevel == "" { p.markUnevaluated("uac", "configuração do UAC não encontrada no registro") }
+		addCheck("uac_enabled", "uac", p.UACLevel != "disabled" && p.UACLevel != "never_notify", p.UACLevel)
+	}
+
+	applicable, passed := 0, 0
+	for _, c := range p.Checks {
+		if c.Passed == nil { continue }
+		applicable++
+		if *c.Passed { passed++ }
+	}
+	if applicable > 0 { p.Score = int(math.Round(float64(passed) / float64(applicable) * 100)) }
+	p.Compliant = applicable > 0 && passed == applicable
+}
+
+func (p *SecurityPosture) markUnevaluated(area string, reason string) {
+	if p.unevaluated == nil { p.unevaluated = map[string]string{} }
+	p.unevaluated[area] = reason
+}
+
+// As consultas que falham (muitas exigem elevação) não viram "ausente": a área fica sem avaliação
+// e o erro volta para collector_errors.
+func windowsSecurityPosture() (SecurityPosture, []error) {
+	var p SecurityPosture
+
+	var errs []error
+	var products []AntiVirusProduct
+	productsErr := queryWMINamespace(&products, "", `root\SecurityCenter2`)
+	var defender []MSFT_MpComputerStatus
+	defenderErr := queryWMINamespace(&defender, "", `root\Microsoft\Windows\Defender`)
+
+	for _, product := range products {
+		// productState: bit 0x1000 = ativo; bit 0x10 = assinaturas desatualizadas
+		av := AntivirusProduct{
+			Name:              product.DisplayName,
+			Enabled:           product.ProductState&0x1000 != 0,
+			SignaturesUpdated: product.ProductState&0x10 == 0,
+		}
+		if strings.Contains(strings.ToLower(product.DisplayName), "defender") && len(defender) > 0 {
+			applyDefenderStatus(&av, defender[0])
+		}
+		p.Antivirus = append(p.Antivirus, av)
+	}
+	// Windows Server: sem Security Center, só o Defender
+	if len(products) == 0 && len(defender) > 0 {
+		av := AntivirusProduct{Name: "Microsoft Defender Antivirus", Enabled: defender[0].AntivirusEnabled}
+		applyDefenderStatus(&av, defender[0])
+		p.Antivirus = append(p.Antivirus, av)
+	}
+	if productsErr != nil { errs = append(errs, fmt.Errorf("AntiVirusProduct: %w", productsErr)) }
+	if defenderErr != nil { errs = append(errs, fmt.Errorf("MSFT_MpComputerStatus: %w", defenderErr)) }
+	// Sem produto encontrado e com alguma fonte falhando não dá para afirmar que não há antivírus
+	if len(p.Antivirus) == 0 && len(errs) > 0 { p.markUnevaluated("antivirus", errs[len(errs)-1].Error()) }
+
+	profiles, err := activeFirewallProfiles()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("firewall: %w", err))
+		p.markUnevaluated("firewall", err.Error())
+	}
+	for _, profile := range profiles {
+		p.Firewall = append(p.Firewall, FirewallProfile{Name: profile.Name, Enabled: profile.Enabled != 0})
+	}
+
+	var volumes []Win32_EncryptableVolume
+	if err := queryWMINamespace(&volumes, "", `root\CIMV2\Security\MicrosoftVolumeEncryption`); err != nil {
+		errs = append(errs, fmt.Errorf("Win32_EncryptableVolume: %w", err))
+		p.markUnevaluated("encryption", err.Error())
+	}
+	for _, v := range volumes {
+		if v.DriveLetter == "" { continue }
+		status := bitlockerConversionStatus[v.ConversionStatus]
+		if status == "" { status = "unknown" }
+		p.Encryption = append(p.Encryption, VolumeEncryption{Volume: v.DriveLetter, Protected: v.ProtectionStatus == 1, Status: status})
+	}
+
+	if value, ok := regQueryDWORD(`HKLM\SYSTEM\CurrentControlSet\Control\SecureBoot\State`, "UEFISecureBootEnabled"); ok { p.SecureBoot = boolPtr(value == 1) }
+
+	var tpms []Win32_Tpm
+	if err := queryWMINamespace(&tpms, "", `root\CIMV2\Security\MicrosoftTpm`); err != nil {
+		errs = append(errs, fmt.Errorf("Win32_Tpm: %w", err))
+		p.markUnevaluated("tpm", err.Error())
+	} else if len(tpms) > 0 {
+		p.TPMPresent = tpms[0].IsEnabled_InitialValue && tpms[0].IsActivated_InitialValue
+		// SpecVersion: "2.0, 0, 1.38"; a primeira parte é a versão
+		p.TPMVersion = strings.TrimSpace(strings.Split(tpms[0].SpecVersion, ",")[0])
+	}
+
+	p.UACLevel = windowsUACLevel()
+	return p, errs
+}
+
+// Perfis efetivos (ActiveStore): a configuração local mesclada com a GPO. A classe MSFT_NetFirewallProfile
+// consultada direto devolve só o armazenamento local e mostra como ativo um firewall desligado por GPO.
+func activeFirewallProfiles() ([]MSFT_NetFirewallProfile, error) {
+	psCommand := `ConvertTo-Json -Compress -InputObject @(Get-NetFirewallProfile -PolicyStore ActiveStore -ErrorAction Stop | ForEach-Object { [PSCustomObject]@{ Name = [string]$_.Name; Enabled = [int]$_.Enabled } })`
+	output, exitCode, err := runCommandWithExitCode(SECURITY_POWERSHELL_TIMEOUT, "powershell", "-NoProfile", "-Command", psCommand)
+	if err != nil { return nil, err }
+	if exitCode != 0 { return nil, fmt.Errorf("Get-NetFirewallProfile saiu com código %d: %s", exitCode, strings.TrimSpace(output)) }
+	return parseFirewallProfiles(output)
+}
+
+func parseFirewallProfiles(output string) ([]MSFT_NetFirewallProfile, error) {
+	start := strings.Index(output, "[")
+	if start < 0 { return nil, fmt.Errorf("resposta inválida do Get-NetFirewallProfile") }
+	var profiles []MSFT_NetFirewallProfile
+	if err := json.Unmarshal([]byte(output[start:]), &profiles); err != nil { return nil, fmt.Errorf("resposta inválida do Get-NetFirewallProfile: %v", err) }
+	if len(profiles) == 0 { return nil, fmt.Errorf("nenhum perfil de firewall retornado") }
+	return profiles, nil
+}
+
+func applyDefenderStatus(av *AntivirusProduct, status MSFT_MpComputerStatus) {
+	age := int(status.AntivirusSignatureAge)
+	av.SignatureAgeDays = &age
+	av.SignaturesUpdated = age <= SECURITY_SIGNATURE_MAX_AGE_DAYS
+	av.RealTimeEnabled = boolPtr(status.RealTimeProtectionEnabled)
+}
+
+// Mesmos níveis do controle deslizante do UAC
+func windowsUACLevel() string {
+	key := `HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\Policies\System`
+	lua, ok := regQueryDWORD(key, "EnableLUA")
+	if !ok { return "" }
+	consent, _ := regQueryDWORD(key, "ConsentPromptBehaviorAdmin")
+	secureDesktop, _ := regQueryDWORD(key, "PromptOnSecureDesktop")
+	return uacLevel(lua, consent, secureDesktop)
+}
+
+func uacLevel(lua int64, consent int64, secureDesktop int64) string {
+	if lua == 0 { return "disabled" }
+	switch {
+	case consent == 0:
+		return "never_notify"
+	case consent == 2:
+		return "always_notify"
+	case consent == 5 && secureDesktop == 0:
+		return "notify_no_dim"
+	case consent == 5:
+		return "default"
+	}
+	return fmt.Sprintf("custom_%d", consent)
+}
+
+// "reg query" responde com "    Nome    REG_DWORD    0x1"
+func regQueryDWORD(key string, name string) (int64, bool) {
+	output, err := runCommandHidden("reg", "query", key, "/v", name)
+	if err != nil { return 0, false }
+	return parseRegDWORD(output, name)
+}
+
+func parseRegDWORD(output string, name string) (int64, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && strings.EqualFold(fields[0], name) && fields[1] == "REG_DWORD" {
+			value, err := strconv.ParseInt(strings.TrimPrefix(fields[2], "0x"), 16, 64)
+			return value, err == nil
+		}
+	}
+	return 0, false
+}
+
+func linuxSecurityPosture() SecurityPosture {
+	var p SecurityPosture
+
+	// ClamAV: a idade das assinaturas é a data do banco "daily"
+	if _, err := exec.LookPath("clamscan"); err == nil {
+		av := AntivirusProduct{Name: "ClamAV", Enabled: systemdActive("clamav-daemon") || systemdActive("clamd@scan")}
+		for _, db := range []string{"/var/lib/clamav/daily.cld", "/var/lib/clamav/daily.cvd"} {
+			if info, err := os.Stat(db); err == nil {
+				age := int(time.Since(info.ModTime()).Hours() / 24)
+				av.SignatureAgeDays = &age
+				av.SignaturesUpdated = age <= SECURITY_SIGNATURE_MAX_AGE_DAYS
+				break
+			}
+		}
+		p.Antivirus = append(p.Antivirus, av)
+	}
+
+	switch {
+	case commandExists("ufw"):
+		out, _ := runCommandHidden("ufw", "status")
+		p.Firewall = append(p.Firewall, FirewallProfile{Name: "ufw", Enabled: strings.Contains(out, "Status: active")})
+	case commandExists("firewall-cmd"):
+		p.Firewall = append(p.Firewall, FirewallProfile{Name: "firewalld", Enabled: systemdActive("firewalld")})
+	case commandExists("nft"):
+		out, _ := runCommandHidden("nft", "list", "ruleset")
+		p.Firewall = append(p.Firewall, FirewallProfile{Name: "nftables", Enabled: strings.Contains(out, "hook input")})
+	}
+
+	// Raiz sobre um dispositivo dm-crypt/LUKS
+	if source, err := runCommandHidden("findmnt", "-n", "-o", "SOURCE", "/"); err == nil {
+		source = strings.TrimSpace(source)
+		types, _ := runCommandHidden("lsblk", "-s", "-n", "-o", "TYPE", source)
+		protected := strings.Contains(types, "crypt")
+		status := "decrypted"
+		if protected { status = "encrypted" }
+		p.Encryption = append(p.Encryption, VolumeEncryption{Volume: "/", Protected: protected, Status: status})
+	}
+
+	// Variável EFI SecureBoot: 4 bytes de atributos seguidos do valor
+	if files, _ := filepath.Glob("/sys/firmware/efi/efivars/SecureBoot-*"); len(files) > 0 {
+		if data, err := os.ReadFile(files[0]); err == nil && len(data) > 0 { p.SecureBoot = boolPtr(data[len(data)-1] == 1) }
+	} else if _, err := os.Stat("/sys/firmware/efi"); err == nil {
+		p.SecureBoot = boolPtr(false)
+	}
+
+	if _, err := os.Stat("/sys/class/tpm/tpm0"); err == nil {
+		p.TPMPresent = true
+		p.TPMVersion = "1.2"
+		if readSysfsString("/sys/class/tpm/tpm0/tpm_version_major") == "2" { p.TPMVersion = "2.0" }
+	}
+	p.UACLevel = "not_applicable"
+	return p
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func systemdActive(unit string) bool {
+	out, err := runCommandHidden("systemctl", "is-active", unit)
+	return err == nil && strings.TrimSpace(out) == "active"
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func checkResult(p SecurityPosture, name string) (PostureCheck, bool) {
+	for _, c := range p.Checks {
+		if c.Name == name { return c, true }
+	}
+	return PostureCheck{}, false
+}
+
+func TestScorePosture(t *testing.T) {
+	system := systemMountpoint()
+	healthy := func() SecurityPosture {
+		return SecurityPosture{
+			Antivirus:  []AntivirusProduct{{Name: "Microsoft Defender Antivirus", Enabled: true, SignaturesUpdated: true}},
+			Firewall:   []FirewallProfile{{Name: "Domain", Enabled: true}, {Name: "Private", Enabled: true}, {Name: "Public", Enabled: true}},
+			Encryption: []VolumeEncryption{{Volume: system, Protected: true, Status: "encrypted"}},
+			SecureBoot: boolPtr(true),
+			TPMPresent: true,
+			TPMVersion: "2.0",
+			UACLevel:   "default",
+		}
+	}
+
+	cases := []struct {
+		name          string
+		change        func(p *SecurityPosture)
+		wantScore     int
+		wantCompliant bool
+		wantFailed    []string
+		wantSkipped   []string
+	}{
+		{"tudo em ordem", func(p *SecurityPosture) {}, 100, true, nil, nil},
+		{"firewall desligado em um perfil", func(p *SecurityPosture) { p.Firewall[2].Enabled = false }, 86, false, []string{"firewall_enabled"}, nil},
+		{"sem antivírus", func(p *SecurityPosture) { p.Antivirus = nil }, 71, false, []string{"antivirus_enabled", "antivirus_signatures_current"}, nil},
+		{"só outro volume criptografado", func(p *SecurityPosture) { p.Encryption[0].Volume = "Z:" }, 86, false, []string{"disk_encryption"}, nil},
+		{"firmware legado", func(p *SecurityPosture) { p.SecureBoot = nil }, 100, true, nil, []string{"secure_boot"}},
+		{"UAC em nunca notificar", func(p *SecurityPosture) { p.UACLevel = "never_notify" }, 86, false, []string{"uac_enabled"}, nil},
+		{"UAC não lido", func(p *SecurityPosture) { p.UACLevel = "" }, 100, true, nil, []string{"uac_enabled"}},
+		{"UAC fora do Windows", func(p *SecurityPosture) { p.UACLevel = "not_applicable" }, 100, true, nil, nil},
+		{
+			"consultas sem permissão não reprovam",
+			func(p *SecurityPosture) {
+				p.Encryption, p.TPMPresent = nil, false
+				p.markUnevaluated("encryption", "acesso negado")
+				p.markUnevaluated("tpm", "acesso negado")
+			},
+			100, true, nil, []string{"disk_encryption", "tpm_present"},
+		},
+		{
+			"antivírus não avaliado com firewall reprovado",
+			func(p *SecurityPosture) {
+				p.Antivirus = nil
+				p.markUnevaluated("antivirus", "namespace inválido")
+				p.Firewall[0].Enabled = false
+			},
+			80, false, []string{"firewall_enabled"}, []string{"antivirus_enabled", "antivirus_signatures_current"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := healthy()
+			c.change(&p)
+			scorePosture(&p)
+			if p.Score != c.wantScore || p.Compliant != c.wantCompliant { t.Errorf("score = %d, compliant = %v; esperado %d, %v", p.Score, p.Compliant, c.wantScore, c.wantCompliant) }
+
+			failed := map[string]bool{}
+			for _, name := range c.wantFailed { failed[name] = true }
+			skipped := map[string]bool{}
+			for _, name := range c.wantSkipped { skipped[name] = true }
+			for _, check := range p.Checks {
+				switch {
+				case skipped[check.Name]:
+					if check.Passed != nil { t.Errorf("%s = %v, esperado não avaliado", check.Name, *check.Passed) }
+				case check.Passed == nil:
+					t.Errorf("%s não avaliado (%s)", check.Name, check.Detail)
+				case *check.Passed == failed[check.Name]:
+					t.Errorf("%s passed = %v (%s)", check.Name, *check.Passed, check.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestWindowsSecurityPostureReportsWMIErrors(t *testing.T) {
+	denied := errors.New("acesso negado")
+	useFakeWMI(t, &fakeWMI{
+		Results: map[string]interface{}{
+			"Win32_EncryptableVolume": []Win32_EncryptableVolume{{DriveLetter: "C:", ProtectionStatus: 1, ConversionStatus: 1}, {ProtectionStatus: 1}},
+		},
+		Errors: map[string]error{
+			"AntiVirusProduct":      errors.New("namespace inválido"),
+			"MSFT_MpComputerStatus": denied,
+			"Win32_Tpm":             denied,
+		},
+	})
+
+	p, errs := windowsSecurityPosture()
+	wmiErrors := 0
+	for _, err := range errs {
+		if errors.Is(err, denied) || err.Error() == "AntiVirusProduct: namespace inválido" { wmiErrors++ }
+	}
+	if wmiErrors != 3 { t.Errorf("erros = %v, esperado os 3 erros do WMI", errs) }
+
+	for _, area := range []string{"antivirus", "tpm"} {
+		if _, skipped := p.unevaluated[area]; !skipped { t.Errorf("%s deveria ficar sem avaliação", area) }
+	}
+	if _, skipped := p.unevaluated["encryption"]; skipped { t.Error("criptografia foi lida e não deveria ficar sem avaliação") }
+	if len(p.Encryption) != 1 || p.Encryption[0].Status != "encrypted" { t.Errorf("encryption = %+v", p.Encryption) }
+}
+
+func TestParseFirewallProfiles(t *testing.T) {
+	profiles, err := parseFirewallProfiles(`[{"Name":"Domain","Enabled":0},{"Name":"Private","Enabled":1},{"Name":"Public","Enabled":1}]`)
+	if err != nil || len(profiles) != 3 || profiles[0].Name != "Domain" || profiles[0].Enabled != 0 || profiles[1].Enabled != 1 {
+		t.Errorf("parseFirewallProfiles = %+v, %v", profiles, err)
+	}
+	for _, output := range []string{"", "[]", "Get-NetFirewallProfile : Acesso negado."} {
+		if _, err := parseFirewallProfiles(output); err == nil { t.Errorf("parseFirewallProfiles(%q) deveria falhar", output) }
+	}
+}
+
+func TestUACLevel(t *testing.T) {
+	cases := []struct {
+		lua, consent, secureDesktop int64
+		want                        string
+	}{
+		{0, 5, 1, "disabled"},
+		{1, 0, 0, "never_notify"},
+		{1, 2, 1, "always_notify"},
+		{1, 5, 0, "notify_no_dim"},
+		{1, 5, 1, "default"},
+		{1, 1, 1, "custom_1"},
+	}
+	for _, c := range cases {
+		if got := uacLevel(c.lua, c.consent, c.secureDesktop); got != c.want {
+			t.Errorf("uacLevel(%d, %d, %d) = %s, esperado %s", c.lua, c.consent, c.secureDesktop, got, c.want)
+		}
+	}
+}
+
+func TestParseRegDWORD(t *testing.T) {
+	output := "\r\nHKEY_LOCAL_MACHINE\\SOFTWARE\\Microsoft\\Windows\\CurrentVersion\\Policies\\System\r\n    ConsentPromptBehaviorAdmin    REG_DWORD    0x5\r\n\r\n"
+	if value, ok := parseRegDWORD(output, "ConsentPromptBehaviorAdmin"); !ok || value != 5 { t.Errorf("parseRegDWORD = %d, %v", value, ok) }
+	if _, ok := parseRegDWORD(output, "EnableLUA"); ok { t.Error("valor ausente deveria retornar ok = false") }
+	if _, ok := parseRegDWORD("    EnableLUA    REG_SZ    1", "EnableLUA"); ok { t.Error("valor que não é DWORD deveria retornar ok = false") }
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SERVICE_CHECKS_FILE = "agente_service_checks.json"
+const SERVICE_CHECK_TICK = 10 * time.Second
+const SERVICE_CHECK_DEFAULT_INTERVAL = 60
+const SERVICE_CHECK_DEFAULT_TIMEOUT = 10
+const SERVICE_CHECK_MAX_BODY = 1 << 20
+
+// Tipos de verificação aceitos na política
+const (
+	CHECK_TCP  = "tcp"
+	CHECK_TLS  = "tls"
+	CHECK_HTTP = "http"
+	CHECK_DNS  = "dns"
+)
+
+// Uma verificação sintética definida pela política do servidor (comando set_service_checks)
+type ServiceCheck struct {
+	Name               string `json:"name"`
+	Type               string `json:"type"`
+	Target             string `json:"target"` // host:porta (tcp/tls), URL (http) ou nome (dns)
+	IntervalSeconds    int    `json:"interval_seconds"`
+	TimeoutSeconds     int    `json:"timeout_seconds"`
+	ExpectedStatus     int    `json:"expected_status"`
+	BodyContains       string `json:"body_contains"`
+	ExpectedAnswer     string `json:"expected_answer"`
+	DNSServer          string `json:"dns_server"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	CertWarnDays       int    `json:"cert_warn_days"`
+}
+
+type ServiceCheckResult struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Target       string   `json:"target"`
+	Success      bool     `json:"success"`
+	Error        string   `json:"error"`
+	StatusCode   int      `json:"status_code"`
+	Answers      []string `json:"answers"`
+	DNSMS        float64  `json:"dns_ms"`
+	ConnectMS    float64  `json:"connect_ms"`
+	TLSMS        float64  `json:"tls_ms"`
+	TTFBMS       float64  `json:"ttfb_ms"`
+	TotalMS      float64  `json:"total_ms"`
+	CertExpiry   string   `json:"cert_expiry"`
+	CertDaysLeft int      `json:"cert_days_left"`
+	CheckedAt    string   `json:"checked_at"`
+}
+
+type ServiceCheckReport struct {
+	MachineUUID string               `json:"machine_uuid"`
+	Results     []ServiceCheckResult `json:"results"`
+}
+
+var serviceChecks []ServiceCheck
+var serviceChecksLastRun = map[string]time.Time{}
+var serviceChecksMutex sync.Mutex
+
+func handleSetServiceChecks(payload string) {
+	var checks []ServiceCheck
+	if err := json.Unmarshal([]byte(payload), &checks); err != nil {
+		sendCommandResult("", fmt.Sprintf("Payload inválido: %v", err))
+		return
+	}
+	for _, c := range checks {
+		switch c.Type {
+		case CHECK_TCP, CHECK_TLS, CHECK_HTTP, CHECK_DNS:
+		default:
+			sendCommandResult("", fmt.Sprintf("Tipo de verificação desconhecido em %s: %s", c.Name, c.Type))
+			return
+		}
+	}
+
+	serviceChecksMutex.Lock()
+	serviceChecks = checks
+	serviceChecksLastRun = map[string]time.Time{}
+	serviceChecksMutex.Unlock()
+
+	data, _ := json.MarshalIndent(checks, "", "  ")
+	if err := os.WriteFile(getAgentDataPath(SERVICE_CHECKS_FILE), data, 0600); err != nil {
+		log.Printf("⚠️ Erro ao salvar política de verificações: %v", err)
+	}
+	log.Printf("🩺 Política de verificações atualizada: %d verificação(ões)", len(checks))
+	sendCommandResult(fmt.Sprintf("%d verificação(ões) configurada(s)", len(checks)), "")
+}
+
+func loadServiceChecks() {
+	data, err := os.ReadFile(getAgentDataPath(SERVICE_CHECKS_FILE))
+	if err != nil { return }
+	var checks []ServiceCheck
+	if err := json.Unmarshal(data, &checks); err != nil {
+		log.Printf("⚠️ Política de verificações inválida: %v", err)
+		return
+	}
+	serviceChecksMutex.Lock()
+	serviceChecks = checks
+	serviceChecksMutex.Unlock()
+}
+
+func startServiceChecks() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado nas verificações sintéticas: %v", r)
+			time.Sleep(SERVICE_CHECK_TICK)
+			go startServiceChecks()
+		}
+	}()
+
+	loadServiceChecks()
+	for {
+		if due := dueServiceChecks(time.Now()); len(due) > 0 {
+			results := make([]ServiceCheckResult, len(due))
+			var wg sync.WaitGroup
+			for i, check := range due {
+				wg.Add(1)
+				go func(i int, check ServiceCheck) {
+					defer wg.Done()
+					results[i] = runServiceCheck(check)
+				}(i, check)
+			}
+			wg.Wait()
+			postData("/telemetry/service-checks", ServiceCheckReport{MachineUUID: getMachineUUID(), Results: results})
+		}
+		time.Sleep(SERVICE_CHECK_TICK)
+	}
+}
+
+func dueServiceChecks(now time.Time) []ServiceCheck {
+	serviceChecksMutex.Lock()
+	defer serviceChecksMutex.Unlock()
+
+	var due []ServiceCheck
+	for _, c := range serviceChecks {
+		interval := c.IntervalSeconds
+		if interval <= 0 { interval = SERVICE_CHECK_DEFAULT_INTERVAL }
+		key := c.Type + "|" + c.Name + "|" + c.Target
+		if now.Sub(serviceChecksLastRun[key]) < time.Duration(interval)*time.Second { continue }
+		serviceChecksLastRun[key] = now
+		due = append(due, c)
+	}
+	return due
+}
+
+func runServiceCheck(check ServiceCheck) ServiceCheckResult {
+	timeout := time.Duration(check.TimeoutSeconds) * time.Second
+	if timeout <= 0 { timeout = SERVICE_CHECK_DEFAULT_TIMEOUT * time.Second }
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result := ServiceCheckResult{Name: check.Name, Type: check.Type, Target: check.Target, CheckedAt: time.Now().Format(time.RFC3339)}
+	start := time.Now()
+	var err error
+	switch check.Type {
+	case CHECK_TCP:
+		err = runTCPCheck(ctx, check, &result, false)
+	case CHECK_TLS:
+		err = runTCPCheck(ctx, check, &result, true)
+	case CHECK_HTTP:
+		err = runHTTPCheck(ctx, check, &result)
+	case CHECK_DNS:
+		err = runDNSCheck(ctx, check, &result)
+	default:
+		err = fmt.Errorf("tipo desconhecido: %s", check.Type)
+	}
+	result.TotalMS = msSince(start)
+	result.Success = err == nil
+	if err != nil { result.Error = err.Error() }
+	return result
+}
+
+// Conexão TCP com tempos de DNS e conexão; com withTLS faz também o handshake e lê a validade do certificado
+func runTCPCheck(ctx context.Context, check ServiceCheck, result *ServiceCheckResult, withTLS bool) error {
+	host, port, err := net.SplitHostPort(check.Target)
+	if err != nil { return fmt.Errorf("alvo deve ser host:porta: %v", err) }
+
+	dnsStart := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	result.DNSMS = msSince(dnsStart)
+	if err != nil || len(addrs) == 0 { return fmt.Errorf("dns: %v", err) }
+
+	var d net.Dialer
+	connStart := time.Now()
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(addrs[0], port))
+	result.ConnectMS = msSince(connStart)
+	if err != nil { return fmt.Errorf("connect: %v", err) }
+	defer conn.Close()
+	if !withTLS { return nil }
+
+	// Handshake sem validação para sempre obter o certificado; a cadeia é validada logo depois
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	tlsStart := time.Now()
+	err = tlsConn.HandshakeContext(ctx)
+	result.TLSMS = msSince(tlsStart)
+	if err != nil { return fmt.Errorf("tls: %v", err) }
+
+	return inspectCertificates(tlsConn.ConnectionState(), host, check, result)
+}
+
+func inspectCertificates(state tls.ConnectionState, host string, check ServiceCheck, result *ServiceCheckResult) error {
+	if len(state.PeerCertificates) == 0 { return fmt.Errorf("tls: servidor não apresentou certificado") }
+	leaf := state.PeerCertificates[0]
+	result.CertExpiry = leaf.NotAfter.Format(time.RFC3339)
+	result.CertDaysLeft = int(math.Floor(time.Until(leaf.NotAfter).Hours() / 24))
+
+	if !check.InsecureSkipVerify {
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] { intermediates.AddCert(cert) }
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates}); err != nil {
+			return fmt.Errorf("certificado inválido: %v", err)
+		}
+	}
+	if check.CertWarnDays > 0 && result.CertDaysLeft < check.CertWarnDays {
+		return fmt.Errorf("certificado expira em %d dia(s)", result.CertDaysLeft)
+	}
+	return nil
+}
+
+func runHTTPCheck(ctx context.Context, check ServiceCheck, result *ServiceCheckResult) error {
+	u, err := url.Parse(check.Target)
+	if err != nil { return fmt.Errorf("url inválida: %v", err) }
+
+	var dnsStart, connStart, tlsStart, reqStart time.Time
+	var tlsState *tls.ConnectionState
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { result.DNSMS = msSince(dnsStart) },
+		ConnectStart:      func(string, string) { connStart = time.Now() },
+		ConnectDone:       func(string, string, error) { result.ConnectMS = msSince(connStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(state tls.ConnectionState, _ error) {
+			result.TLSMS = msSince(tlsStart)
+			tlsState = &state
+		},
+		GotFirstResponseByte: func() { result.TTFBMS = msSince(reqStart) },
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), "GET", check.Target, nil)
+	if err != nil { return err }
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+			Proxy:             http.ProxyFromEnvironment,
+		},
+	}
+	reqStart = time.Now()
+	resp, err := client.Do(req)
+	if err != nil { return fmt.Errorf("http: %v", err) }
+	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
+
+	if tlsState != nil {
+		if err := inspectCertificates(*tlsState, u.Hostname(), check, result); err != nil { return err }
+	}
+
+	expected := check.ExpectedStatus
+	if expected == 0 { expected = http.StatusOK }
+	if resp.StatusCode != expected { return fmt.Errorf("status %d (esperado %d)", resp.StatusCode, expected) }
+
+	if check.BodyContains != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, SERVICE_CHECK_MAX_BODY))
+		if err != nil { return fmt.Errorf("leitura do corpo: %v", err) }
+		if !strings.Contains(string(body), check.BodyContains) { return fmt.Errorf("corpo não contém %q", check.BodyContains) }
+	}
+	return nil
+}
+
+func runDNSCheck(ctx context.Context, check ServiceCheck, result *ServiceCheckResult) error {
+	resolver := net.DefaultResolver
+	if check.DNSServer != "" {
+		server := check.DNSServer
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "udp", net.JoinHostPort(server, "53"))
+			},
+		}
+	}
+
+	start := time.Now()
+	answers, err := resolver.LookupHost(ctx, check.Target)
+	result.DNSMS = msSince(start)
+	result.Answers = answers
+	if err != nil { return fmt.Errorf("dns: %v", err) }
+
+	if check.ExpectedAnswer != "" {
+		for _, a := range answers {
+			if a == check.ExpectedAnswer { return nil }
+		}
+		return fmt.Errorf("resposta esperada %s não encontrada", check.ExpectedAnswer)
+	}
+	return nil
+}
+
+func msSince(t time.Time) float64 {
+	return math.Round(float64(time.Since(t).Microseconds())/100) / 10
+}
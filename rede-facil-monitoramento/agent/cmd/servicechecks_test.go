@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDueServiceChecks(t *testing.T) {
+	serviceChecksMutex.Lock()
+	previous, previousRuns := serviceChecks, serviceChecksLastRun
+	serviceChecks = []ServiceCheck{
+		{Name: "erp", Type: CHECK_HTTP, Target: "https://erp.interno/health", IntervalSeconds: 30},
+		{Name: "pdv", Type: CHECK_TCP, Target: "10.0.0.20:5432"},
+	}
+	serviceChecksLastRun = map[string]time.Time{}
+	serviceChecksMutex.Unlock()
+	t.Cleanup(func() {
+		serviceChecksMutex.Lock()
+		serviceChecks, serviceChecksLastRun = previous, previousRuns
+		serviceChecksMutex.Unlock()
+	})
+
+	start := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	names := func(checks []ServiceCheck) string {
+		var list []string
+		for _, c := range checks { list = append(list, c.Name) }
+		return strings.Join(list, ",")
+	}
+	steps := []struct {
+		after time.Duration
+		want  string
+	}{
+		{0, "erp,pdv"},
+		{10 * time.Second, ""},
+		{30 * time.Second, "erp"},
+		{SERVICE_CHECK_DEFAULT_INTERVAL * time.Second, "erp,pdv"},
+	}
+	for _, s := range steps {
+		if got := names(dueServiceChecks(start.Add(s.after))); got != s.want { t.Errorf("após %s: %q, esperado %q", s.after, got, s.want) }
+	}
+}
+
+func TestRunServiceCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			fmt.Fprint(w, `{"status":"UP"}`)
+		default:
+			http.Error(w, "manutenção", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name      string
+		check     ServiceCheck
+		wantOK    bool
+		wantError string
+	}{
+		{"status 200", ServiceCheck{Type: CHECK_HTTP, Target: server.URL + "/health"}, true, ""},
+		{"corpo esperado", ServiceCheck{Type: CHECK_HTTP, Target: server.URL + "/health", BodyContains: `"UP"`}, true, ""},
+		{"corpo diferente", ServiceCheck{Type: CHECK_HTTP, Target: server.URL + "/health", BodyContains: "DOWN"}, false, "corpo não contém"},
+		{"status inesperado", ServiceCheck{Type: CHECK_HTTP, Target: server.URL + "/pdv"}, false, "status 503"},
+		{"status esperado diferente de 200", ServiceCheck{Type: CHECK_HTTP, Target: server.URL + "/pdv", ExpectedStatus: 503}, true, ""},
+		{"tipo desconhecido", ServiceCheck{Type: "icmp", Target: "10.0.0.1"}, false, "tipo desconhecido"},
+	}
+	for _, c := range cases {
+		result := runServiceCheck(c.check)
+		if result.Success != c.wantOK || !strings.Contains(result.Error, c.wantError) {
+			t.Errorf("%s: success = %v, erro = %q", c.name, result.Success, result.Error)
+		}
+	}
+}
+
+func TestRunServiceCheckTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	target := strings.TrimPrefix(server.URL, "https://")
+
+	// Certificado autoassinado do httptest: não é confiável, a não ser com insecure_skip_verify
+	result := runServiceCheck(ServiceCheck{Type: CHECK_TLS, Target: target})
+	if result.Success || !strings.Contains(result.Error, "certificado inválido") || result.CertExpiry == "" { t.Errorf("sem confiança: %+v", result) }
+
+	result = runServiceCheck(ServiceCheck{Type: CHECK_TLS, Target: target, InsecureSkipVerify: true})
+	if !result.Success || result.CertDaysLeft <= 0 { t.Errorf("insecure: %+v", result) }
+
+	result = runServiceCheck(ServiceCheck{Type: CHECK_TLS, Target: target, InsecureSkipVerify: true, CertWarnDays: result.CertDaysLeft + 1})
+	if result.Success || !strings.Contains(result.Error, "certificado expira") { t.Errorf("aviso de validade: %+v", result) }
+
+	result = runServiceCheck(ServiceCheck{Type: CHECK_HTTP, Target: server.URL, InsecureSkipVerify: true})
+	if !result.Success || result.StatusCode != http.StatusOK || result.CertExpiry == "" { t.Errorf("https: %+v", result) }
+}
+
+func TestRunServiceCheckTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil { t.Fatal(err) }
+	address := listener.Addr().String()
+
+	if result := runServiceCheck(ServiceCheck{Type: CHECK_TCP, Target: address}); !result.Success { t.Errorf("porta aberta: %+v", result) }
+	listener.Close()
+
+	if result := runServiceCheck(ServiceCheck{Type: CHECK_TCP, Target: address, TimeoutSeconds: 2}); result.Success || !strings.HasPrefix(result.Error, "connect:") { t.Errorf("porta fechada: %+v", result) }
+	if result := runServiceCheck(ServiceCheck{Type: CHECK_TCP, Target: "10.0.0.20"}); result.Success || !strings.Contains(result.Error, "host:porta") { t.Errorf("alvo sem porta: %+v", result) }
+}
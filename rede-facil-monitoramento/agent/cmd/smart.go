@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const SMART_STATUS_OK = "OK"
+const SMART_STATUS_WARNING = "WARNING"
+const SMART_STATUS_FAILING = "FAILING"
+const SMART_STATUS_UNKNOWN = "UNKNOWN"
+
+// Limites para alerta; setores realocados ou pendentes acima de zero já geram aviso
+const SMART_WEAR_WARNING_PERCENT = 90
+const SMART_TEMPERATURE_WARNING = 60
+
+const SMARTCTL_TIMEOUT = 30 * time.Second
+
+const STORAGE_WMI_NAMESPACE = `root\Microsoft\Windows\Storage`
+
+// MSFT_PhysicalDisk.BusType dos discos que respondem ao SMART do driver
+const STORAGE_BUS_ATA = 3
+const STORAGE_BUS_SATA = 11
+
+// Saúde de um disco físico. Campos nil significam que o disco não informa o atributo.
+type DiskHealth struct {
+	Device             string           `json:"device"`
+	Model              string           `json:"model"`
+	SerialNumber       string           `json:"serial_number"`
+	Source             string           `json:"source"`
+	Status             string           `json:"status"`
+	Reasons            []string         `json:"reasons"`
+	PredictFailure     bool             `json:"predict_failure"`
+	SmartPassed        *bool            `json:"smart_passed"`
+	ReallocatedSectors *int64           `json:"reallocated_sectors"`
+	PendingSectors     *int64           `json:"pending_sectors"`
+	WearPercent        *int64           `json:"wear_percent"`
+	PowerOnHours       *int64           `json:"power_on_hours"`
+	TemperatureCelsius *int64           `json:"temperature_celsius"`
+	MediaErrors        *int64           `json:"media_errors"`
+	Attributes         []SmartAttribute `json:"attributes,omitempty"`
+}
+
+type SmartAttribute struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Value     int    `json:"value"`
+	Worst     int    `json:"worst"`
+	Threshold int    `json:"threshold"`
+	Raw       int64  `json:"raw"`
+}
+
+// Atributos ATA mais relevantes (nomes iguais aos do smartctl)
+var ataAttributeNames = map[int]string{
+	1:   "Raw_Read_Error_Rate",
+	5:   "Reallocated_Sector_Ct",
+	9:   "Power_On_Hours",
+	12:  "Power_Cycle_Count",
+	177: "Wear_Leveling_Count",
+	187: "Reported_Uncorrect",
+	194: "Temperature_Celsius",
+	196: "Reallocated_Event_Count",
+	197: "Current_Pending_Sector",
+	198: "Offline_Uncorrectable",
+	199: "UDMA_CRC_Error_Count",
+	231: "SSD_Life_Left",
+	233: "Media_Wearout_Indicator",
+}
+
+// --- Classes WMI (root\WMI e root\Microsoft\Windows\Storage, as mesmas do Get-PhysicalDisk) ---
+
+type MSStorageDriver_FailurePredictStatus struct {
+	InstanceName   string
+	PredictFailure bool
+	Reason         uint32
+}
+
+type MSStorageDriver_FailurePredictData struct {
+	InstanceName   string
+	VendorSpecific []uint8
+}
+
+type MSFT_PhysicalDisk struct {
+	DeviceId     string
+	FriendlyName string
+	SerialNumber string
+	MediaType    uint16
+	BusType      uint16
+	HealthStatus uint16
+	Size         uint64
+}
+
+type MSFT_StorageReliabilityCounter struct {
+	DeviceId               string
+	Temperature            uint8
+	Wear                   uint8
+	PowerOnHours           uint32
+	ReadErrorsUncorrected  uint64
+	WriteErrorsUncorrected uint64
+}
+
+func collectDiskHealth(ctx context.Context) (interface{}, error) {
+	var disks []DiskHealth
+	var errs []error
+	if runtime.GOOS == "windows" {
+		disks, errs = windowsDiskHealth()
+	} else {
+		disks = linuxDiskHealth()
+	}
+	if len(disks) == 0 && len(errs) > 0 { return nil, errors.Join(errs...) }
+	for i := range disks { applySmartThresholds(&disks[i]) }
+	if disks == nil { disks = []DiskHealth{} }
+	return disks, partialFailure(errs)
+}
+
+// Pior status entre os discos (o valor que vai em disk_smart_status)
+func overallDiskHealth(disks []DiskHealth) string {
+	status := SMART_STATUS_UNKNOWN
+	for _, d := range disks {
+		if status == SMART_STATUS_UNKNOWN || smartSeverity(d.Status) > smartSeverity(status) { status = d.Status }
+	}
+	return status
+}
+
+func smartSeverity(status string) int {
+	switch status {
+	case SMART_STATUS_OK:
+		return 1
+	case SMART_STATUS_WARNING:
+		return 2
+	case SMART_STATUS_FAILING:
+		return 3
+	}
+	return 0
+}
+
+func (d *DiskHealth) escalate(status string, reason string) {
+	if smartSeverity(status) > smartSeverity(d.Status) { d.Status = status }
+	d.Reasons = append(d.Reasons, reason)
+}
+
+func applySmartThresholds(d *DiskHealth) {
+	if d.PredictFailure { d.escalate(SMART_STATUS_FAILING, "Falha prevista pelo SMART") }
+	if d.SmartPassed != nil && !*d.SmartPassed { d.escalate(SMART_STATUS_FAILING, "Autoteste SMART reprovado") }
+	if d.ReallocatedSectors != nil && *d.ReallocatedSectors > 0 { d.escalate(SMART_STATUS_WARNING, fmt.Sprintf("%d setores realocados", *d.ReallocatedSectors)) }
+	if d.PendingSectors != nil && *d.PendingSectors > 0 { d.escalate(SMART_STATUS_WARNING, fmt.Sprintf("%d setores pendentes", *d.PendingSectors)) }
+	if d.MediaErrors != nil && *d.MediaErrors > 0 { d.escalate(SMART_STATUS_WARNING, fmt.Sprintf("%d erros de mídia", *d.MediaErrors)) }
+	if d.WearPercent != nil && *d.WearPercent >= SMART_WEAR_WARNING_PERCENT { d.escalate(SMART_STATUS_WARNING, fmt.Sprintf("Desgaste em %d%%", *d.WearPercent)) }
+	if d.TemperatureCelsius != nil && *d.TemperatureCelsius >= SMART_TEMPERATURE_WARNING { d.escalate(SMART_STATUS_WARNING, fmt.Sprintf("Temperatura de %d°C", *d.TemperatureCelsius)) }
+	if d.Reasons == nil { d.Reasons = []string{} }
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+// Preenche os contadores a partir dos atributos ATA, sem sobrescrever o que outra fonte já informou
+func fillFromATAAttributes(d *DiskHealth) {
+	for _, a := range d.Attributes {
+		switch a.ID {
+		case 5:
+			if d.ReallocatedSectors == nil { d.ReallocatedSectors = int64Ptr(a.Raw) }
+		case 197:
+			if d.PendingSectors == nil { d.PendingSectors = int64Ptr(a.Raw) }
+		case 9:
+			if d.PowerOnHours == nil { d.PowerOnHours = int64Ptr(a.Raw) }
+		case 194:
+			// Só o byte baixo é a temperatura atual; os demais guardam mínimo e máximo
+			if d.TemperatureCelsius == nil { d.TemperatureCelsius = int64Ptr(a.Raw & 0xFF) }
+		case 177, 231, 233:
+			// Valor normalizado = vida restante (100 = novo)
+			if d.WearPercent == nil && a.Value > 0 && a.Value <= 100 { d.WearPercent = int64Ptr(int64(100 - a.Value)) }
+		}
+	}
+}
+
+// Bloco de 512 bytes do IOCTL SMART: 2 bytes de versão e 30 atributos de 12 bytes
+func parseATASmartData(data []uint8) []SmartAttribute {
+	var attrs []SmartAttribute
+	for offset := 2; offset+12 <= len(data) && offset < 2+30*12; offset += 12 {
+		id := int(data[offset])
+		if id == 0 { continue }
+		var raw int64
+		for i := 5; i >= 0; i-- { raw = raw<<8 | int64(data[offset+5+i]) }
+		attrs = append(attrs, SmartAttribute{
+			ID:    id,
+			Name:  ataAttributeNames[id],
+			Value: int(data[offset+3]),
+			Worst: int(data[offset+4]),
+			Raw:   raw,
+		})
+	}
+	return attrs
+}
+
+func windowsDiskHealth() ([]DiskHealth, []error) {
+	var disks []DiskHealth
+	var errs []error
+
+	var physical []MSFT_PhysicalDisk
+	if err := queryWMINamespace(&physical, "", STORAGE_WMI_NAMESPACE); err != nil { errs = append(errs, fmt.Errorf("MSFT_PhysicalDisk: %w", err)) }
+	var counters []MSFT_StorageReliabilityCounter
+	if err := queryWMINamespace(&counters, "", STORAGE_WMI_NAMESPACE); err != nil && len(physical) > 0 { errs = append(errs, fmt.Errorf("MSFT_StorageReliabilityCounter: %w", err)) }
+	countersByDevice := map[string]MSFT_StorageReliabilityCounter{}
+	for _, c := range counters { countersByDevice[c.DeviceId] = c }
+
+	for _, p := range physical {
+		d := DiskHealth{
+			Device:       "PhysicalDrive" + p.DeviceId,
+			Model:        strings.TrimSpace(p.FriendlyName),
+			SerialNumber: strings.TrimSpace(p.SerialNumber),
+			Source:       "storage_wmi",
+			Status:       SMART_STATUS_OK,
+		}
+		// HealthStatus: 0 = Healthy, 1 = Warning, 2 = Unhealthy
+		switch p.HealthStatus {
+		case 1:
+			d.escalate(SMART_STATUS_WARNING, "Windows reporta o disco em alerta")
+		case 2:
+			d.escalate(SMART_STATUS_FAILING, "Windows reporta o disco com falha")
+		case 0:
+		default:
+			d.Status = SMART_STATUS_UNKNOWN
+		}
+		if c, ok := countersByDevice[p.DeviceId]; ok {
+			if c.Temperature > 0 { d.TemperatureCelsius = int64Ptr(int64(c.Temperature)) }
+			if c.Wear > 0 { d.WearPercent = int64Ptr(int64(c.Wear)) }
+			if c.PowerOnHours > 0 { d.PowerOnHours = int64Ptr(int64(c.PowerOnHours)) }
+			d.MediaErrors = int64Ptr(int64(c.ReadErrorsUncorrected + c.WriteErrorsUncorrected))
+		}
+		disks = append(disks, d)
+	}
+
+	// Predição de falha e atributos brutos do driver (somente discos ATA/SATA). Sem disco desse tipo
+	// o driver responde "não suportado", o que não é falha da coleta.
+	hasATA := len(physical) == 0
+	for _, p := range physical {
+		if p.BusType == STORAGE_BUS_ATA || p.BusType == STORAGE_BUS_SATA { hasATA = true }
+	}
+	var predictions []MSStorageDriver_FailurePredictStatus
+	if err := queryWMINamespace(&predictions, "", `root\WMI`); err != nil && hasATA { errs = append(errs, fmt.Errorf("MSStorageDriver_FailurePredictStatus: %w", err)) }
+	var rawData []MSStorageDriver_FailurePredictData
+	if err := queryWMINamespace(&rawData, "", `root\WMI`); err != nil && hasATA { errs = append(errs, fmt.Errorf("MSStorageDriver_FailurePredictData: %w", err)) }
+	rawByInstance := map[string][]uint8{}
+	for _, r := range rawData { rawByInstance[r.InstanceName] = r.VendorSpecific }
+
+	for _, p := range predictions {
+		idx := matchDiskByInstance(disks, p.InstanceName)
+		// Sem o Storage WMI (Windows 7) o driver é a única fonte e cada instância é um disco
+		if idx < 0 && len(physical) == 0 {
+			disks = append(disks, DiskHealth{Device: p.InstanceName, Model: "N/A", Source: "smart_wmi", Status: SMART_STATUS_OK})
+			idx = len(disks) - 1
+		}
+		if idx < 0 {
+			log.Printf("⚠️ SMART do driver sem disco correspondente: %s", p.InstanceName)
+			continue
+		}
+		disks[idx].PredictFailure = disks[idx].PredictFailure || p.PredictFailure
+		passed := !p.PredictFailure
+		disks[idx].SmartPassed = &passed
+		if raw, ok := rawByInstance[p.InstanceName]; ok {
+			disks[idx].Attributes = parseATASmartData(raw)
+			fillFromATAAttributes(&disks[idx])
+		}
+	}
+	return disks, errs
+}
+
+// O InstanceName do driver traz o modelo no formato do PnP (ex: "...Prod_Samsung_SSD_860...")
+func matchDiskByInstance(disks []DiskHealth, instance string) int {
+	instance = strings.ToUpper(instance)
+	for i, d := range disks {
+		model := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(d.Model), " ", "_"))
+		// O campo Prod do PnP tem 16 caracteres; um "_" no corte não aparece no InstanceName
+		if len(model) > 16 { model = strings.TrimRight(model[:16], "_") }
+		if model != "" && strings.Contains(instance, model) { return i }
+	}
+	return -1
+}
+
+// --- Linux ---
+
+type smartctlScan struct {
+	Devices []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"devices"`
+}
+
+type smartctlReport struct {
+	ModelName    string `json:"model_name"`
+	SerialNumber string `json:"serial_number"`
+	SmartStatus  *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	PowerOnTime *struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	Temperature *struct {
+		Current int64 `json:"current"`
+	} `json:"temperature"`
+	ATASmartAttributes struct {
+		Table []struct {
+			ID     int    `json:"id"`
+			Name   string `json:"name"`
+			Value  int    `json:"value"`
+			Worst  int    `json:"worst"`
+			Thresh int    `json:"thresh"`
+			Raw    struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		CriticalWarning int   `json:"critical_warning"`
+		PercentageUsed  int64 `json:"percentage_used"`
+		PowerOnHours    int64 `json:"power_on_hours"`
+		MediaErrors     int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+func linuxDiskHealth() []DiskHealth {
+	if _, err := exec.LookPath("smartctl"); err == nil {
+		if disks := smartctlDiskHealth(); len(disks) > 0 { return disks }
+	}
+	return sysfsDiskHealth()
+}
+
+func smartctlDiskHealth() []DiskHealth {
+	// O código de saída do smartctl é uma máscara de bits; o JSON vem mesmo quando ele não é zero
+	output, _, _ := runCommandWithExitCode(SMARTCTL_TIMEOUT, "smartctl", "--scan", "-j")
+	var scan smartctlScan
+	if err := json.Unmarshal([]byte(output), &scan); err != nil { return nil }
+
+	var disks []DiskHealth
+	for _, dev := range scan.Devices {
+		out, _, _ := runCommandWithExitCode(SMARTCTL_TIMEOUT, "smartctl", "-a", "-j", "-d", dev.Type, dev.Name)
+		var rep smartctlReport
+		if err := json.Unmarshal([]byte(out), &rep); err != nil { continue }
+
+		d := DiskHealth{
+			Device:       dev.Name,
+			Model:        rep.ModelName,
+			SerialNumber: rep.SerialNumber,
+			Source:       "smartctl",
+			Status:       SMART_STATUS_UNKNOWN,
+		}
+		if rep.SmartStatus != nil {
+			passed := rep.SmartStatus.Passed
+			d.SmartPassed = &passed
+			d.Status = SMART_STATUS_OK
+		}
+		if rep.PowerOnTime != nil { d.PowerOnHours = int64Ptr(rep.PowerOnTime.Hours) }
+		if rep.Temperature != nil { d.TemperatureCelsius = int64Ptr(rep.Temperature.Current) }
+		if rep.NVMeLog != nil {
+			d.WearPercent = int64Ptr(rep.NVMeLog.PercentageUsed)
+			d.MediaErrors = int64Ptr(rep.NVMeLog.MediaErrors)
+			if d.PowerOnHours == nil { d.PowerOnHours = int64Ptr(rep.NVMeLog.PowerOnHours) }
+			if rep.NVMeLog.CriticalWarning != 0 { d.escalate(SMART_STATUS_FAILING, fmt.Sprintf("Aviso crítico NVMe 0x%02x", rep.NVMeLog.CriticalWarning)) }
+		}
+		for _, a := range rep.ATASmartAttributes.Table {
+			d.Attributes = append(d.Attributes, SmartAttribute{ID: a.ID, Name: a.Name, Value: a.Value, Worst: a.Worst, Threshold: a.Thresh, Raw: a.Raw.Value})
+		}
+		fillFromATAAttributes(&d)
+		disks = append(disks, d)
+	}
+	return disks
+}
+
+// Sem smartctl: identifica os discos pelo sysfs e lê a temperatura dos NVMe via hwmon
+func sysfsDiskHealth() []DiskHealth {
+	var disks []DiskHealth
+	entries, _ := os.ReadDir("/sys/block")
+	for _, e := range entries {
+		name := e.Name()
+		if isVirtualBlockDevice(name) { continue }
+		base := filepath.Join("/sys/block", name, "device")
+		d := DiskHealth{
+			Device:       "/dev/" + name,
+			Model:        readSysfsString(filepath.Join(base, "model")),
+			SerialNumber: readSysfsString(filepath.Join(base, "serial")),
+			Source:       "sysfs",
+			Status:       SMART_STATUS_UNKNOWN,
+		}
+		if hwmons, _ := filepath.Glob(filepath.Join(base, "hwmon*", "temp1_input")); len(hwmons) > 0 {
+			if milli, err := strconv.ParseInt(readSysfsString(hwmons[0]), 10, 64); err == nil { d.TemperatureCelsius = int64Ptr(milli / 1000) }
+		}
+		disks = append(disks, d)
+	}
+	return disks
+}
+
+func isVirtualBlockDevice(name string) bool {
+	for _, prefix := range []string{"loop", "ram", "zram", "dm-", "sr", "md", "nbd", "fd"} {
+		if strings.HasPrefix(name, prefix) { return true }
+	}
+	return false
+}
+
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil { return "" }
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWindowsDiskHealthSkipsUnmatchedSmartInstances(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"MSFT_PhysicalDisk": []MSFT_PhysicalDisk{
+			{DeviceId: "0", FriendlyName: "Samsung SSD 860 EVO 500GB", SerialNumber: "S3Z1NB0K123", BusType: STORAGE_BUS_SATA},
+			{DeviceId: "1", FriendlyName: "WDC WD10EZEX-08WN4A0", BusType: STORAGE_BUS_SATA, HealthStatus: 1},
+		},
+		"MSStorageDriver_FailurePredictStatus": []MSStorageDriver_FailurePredictStatus{
+			{InstanceName: `SCSI\Disk&Ven_&Prod_Samsung_SSD_860\4&1a2b3c&0&000000_0`, PredictFailure: true},
+			{InstanceName: `SCSI\Disk&Ven_&Prod_ST1000DM010-2EP1\4&1a2b3c&0&010000_0`},
+		},
+	}})
+
+	disks, errs := windowsDiskHealth()
+	if len(errs) != 0 { t.Fatalf("erros inesperados: %v", errs) }
+	if len(disks) != 2 { t.Fatalf("%d discos, esperado 2 (instância sem disco não pode virar disco novo): %+v", len(disks), disks) }
+	if !disks[0].PredictFailure || disks[0].SmartPassed == nil || *disks[0].SmartPassed { t.Errorf("disco 0 = %+v", disks[0]) }
+	if disks[1].PredictFailure || disks[1].SmartPassed != nil || disks[1].Status != SMART_STATUS_WARNING { t.Errorf("disco 1 = %+v", disks[1]) }
+}
+
+func TestWindowsDiskHealthDriverOnly(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"MSStorageDriver_FailurePredictStatus": []MSStorageDriver_FailurePredictStatus{{InstanceName: `IDE\DiskST500DM002\5&2f3c&0&0.0.0_0`}},
+	}})
+	disks, _ := windowsDiskHealth()
+	if len(disks) != 1 || disks[0].Source != "smart_wmi" { t.Fatalf("discos = %+v", disks) }
+}
+
+func TestWindowsDiskHealthReportsQueryErrors(t *testing.T) {
+	denied := errors.New("acesso negado")
+	cases := []struct {
+		name     string
+		physical []MSFT_PhysicalDisk
+		errs     map[string]error
+		want     []string
+	}{
+		{"storage WMI indisponível", nil, map[string]error{"MSFT_PhysicalDisk": denied, "MSStorageDriver_FailurePredictStatus": denied, "MSStorageDriver_FailurePredictData": denied}, []string{"MSFT_PhysicalDisk", "MSStorageDriver_FailurePredictStatus", "MSStorageDriver_FailurePredictData"}},
+		{"contadores sem elevação", []MSFT_PhysicalDisk{{DeviceId: "0", FriendlyName: "KINGSTON SA400", BusType: STORAGE_BUS_SATA}}, map[string]error{"MSFT_StorageReliabilityCounter": denied}, []string{"MSFT_StorageReliabilityCounter"}},
+		// Só NVMe: o driver não suporta a predição e isso não é erro de coleta
+		{"NVMe sem predição", []MSFT_PhysicalDisk{{DeviceId: "0", FriendlyName: "WD PC SN530", BusType: 17}}, map[string]error{"MSStorageDriver_FailurePredictStatus": errors.New("Not supported"), "MSStorageDriver_FailurePredictData": errors.New("Not supported")}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			results := map[string]interface{}{}
+			if c.physical != nil { results["MSFT_PhysicalDisk"] = c.physical }
+			useFakeWMI(t, &fakeWMI{Results: results, Errors: c.errs})
+			_, errs := windowsDiskHealth()
+			var got []string
+			for _, err := range errs { got = append(got, strings.SplitN(err.Error(), ":", 2)[0]) }
+			if !reflect.DeepEqual(got, c.want) { t.Errorf("erros = %v, esperado %v", got, c.want) }
+		})
+	}
+}
+
+func TestParseATASmartData(t *testing.T) {
+	data := make([]uint8, 512)
+	// Atributo 5: valor 100, pior 100, bruto 8
+	copy(data[2:], []uint8{5, 0x33, 0, 100, 100, 8, 0, 0, 0, 0, 0, 0})
+	// Atributo 194: bruto com mínimo/máximo nos bytes altos (0x2D = 45°C atual)
+	copy(data[14:], []uint8{194, 0x22, 0, 55, 40, 0x2D, 0x00, 0x12, 0x00, 0x3C, 0x00, 0})
+	copy(data[26:], []uint8{177, 0x13, 0, 93, 93, 0x20, 0x01, 0, 0, 0, 0, 0})
+
+	attrs := parseATASmartData(data)
+	want := []SmartAttribute{
+		{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, Raw: 8},
+		{ID: 194, Name: "Temperature_Celsius", Value: 55, Worst: 40, Raw: 0x3C0012002D},
+		{ID: 177, Name: "Wear_Leveling_Count", Value: 93, Worst: 93, Raw: 0x120},
+	}
+	if !reflect.DeepEqual(attrs, want) { t.Fatalf("atributos = %+v\nesperado %+v", attrs, want) }
+
+	d := DiskHealth{Status: SMART_STATUS_OK, Attributes: attrs}
+	fillFromATAAttributes(&d)
+	if *d.ReallocatedSectors != 8 || *d.TemperatureCelsius != 45 || *d.WearPercent != 7 || d.PendingSectors != nil { t.Errorf("contadores = %+v", d) }
+}
+
+func TestApplySmartThresholds(t *testing.T) {
+	failed := false
+	cases := []struct {
+		name string
+		disk DiskHealth
+		want string
+	}{
+		{"saudável", DiskHealth{Status: SMART_STATUS_OK, ReallocatedSectors: int64Ptr(0), TemperatureCelsius: int64Ptr(38)}, SMART_STATUS_OK},
+		{"setores realocados", DiskHealth{Status: SMART_STATUS_OK, ReallocatedSectors: int64Ptr(12)}, SMART_STATUS_WARNING},
+		{"desgaste", DiskHealth{Status: SMART_STATUS_OK, WearPercent: int64Ptr(SMART_WEAR_WARNING_PERCENT)}, SMART_STATUS_WARNING},
+		{"autoteste reprovado", DiskHealth{Status: SMART_STATUS_OK, SmartPassed: &failed, PendingSectors: int64Ptr(3)}, SMART_STATUS_FAILING},
+		{"desconhecido com aviso", DiskHealth{Status: SMART_STATUS_UNKNOWN, MediaErrors: int64Ptr(1)}, SMART_STATUS_WARNING},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			applySmartThresholds(&c.disk)
+			if c.disk.Status != c.want { t.Errorf("status = %s, esperado %s (%v)", c.disk.Status, c.want, c.disk.Reasons) }
+			if c.disk.Reasons == nil { t.Error("reasons nil") }
+		})
+	}
+}
+
+func TestOverallDiskHealth(t *testing.T) {
+	cases := []struct {
+		statuses []string
+		want     string
+	}{
+		{nil, SMART_STATUS_UNKNOWN},
+		{[]string{SMART_STATUS_OK, SMART_STATUS_WARNING}, SMART_STATUS_WARNING},
+		{[]string{SMART_STATUS_UNKNOWN, SMART_STATUS_OK}, SMART_STATUS_OK},
+		{[]string{SMART_STATUS_FAILING, SMART_STATUS_WARNING, SMART_STATUS_OK}, SMART_STATUS_FAILING},
+	}
+	for _, c := range cases {
+		var disks []DiskHealth
+		for _, s := range c.statuses { disks = append(disks, DiskHealth{Status: s}) }
+		if got := overallDiskHealth(disks); got != c.want { t.Errorf("overallDiskHealth(%v) = %s, esperado %s", c.statuses, got, c.want) }
+	}
+}
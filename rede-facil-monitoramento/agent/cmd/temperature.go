@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Faixa aceitável; fora dela o valor é leitura inválida do sensor (0, -273, 255...)
+const TEMPERATURE_MIN_VALID = 1.0
+const TEMPERATURE_MAX_VALID = 125.0
+
+const TEMPERATURE_STATUS_OK = "ok"
+const TEMPERATURE_STATUS_UNAVAILABLE = "unavailable"
+
+type TemperatureSensor struct {
+	Name            string  `json:"name"`
+	Source          string  `json:"source"`
+	Celsius         float64 `json:"celsius"`
+	HighCelsius     float64 `json:"high_celsius,omitempty"`
+	CriticalCelsius float64 `json:"critical_celsius,omitempty"`
+	IsCPU           bool    `json:"is_cpu"`
+}
+
+// Leituras de todos os sensores. CPUCelsius é nil quando o hardware não expõe temperatura.
+type TemperatureReport struct {
+	Status     string              `json:"status"`
+	CPUCelsius *float64            `json:"cpu_celsius"`
+	Sensors    []TemperatureSensor `json:"sensors"`
+}
+
+// Zonas térmicas ACPI (namespace root\WMI), em décimos de Kelvin
+type MSAcpi_ThermalZoneTemperature struct {
+	InstanceName       string
+	CurrentTemperature uint32
+	CriticalTripPoint  uint32
+}
+
+// Classe "Sensor" publicada pelo OpenHardwareMonitor e pelo LibreHardwareMonitor quando estão em execução
+type HardwareMonitorSensor struct {
+	Identifier string
+	Name       string
+	Parent     string
+	SensorType string
+	Value      float32
+	Max        float32
+}
+
+func (HardwareMonitorSensor) WMIClassName() string { return "Sensor" }
+
+// Em ordem de preferência: o LibreHardwareMonitor é o fork mantido do OpenHardwareMonitor
+var hardwareMonitorNamespaces = []struct {
+	source    string
+	namespace string
+}{
+	{"librehardwaremonitor", `root\LibreHardwareMonitor`},
+	{"openhardwaremonitor", `root\OpenHardwareMonitor`},
+}
+
+func collectTemperatures(ctx context.Context) (interface{}, error) {
+	var sensors []TemperatureSensor
+	if runtime.GOOS == "windows" {
+		sensors = windowsTemperatureSensors()
+	} else {
+		sensors = linuxTemperatureSensors(ctx)
+	}
+
+	report := TemperatureReport{Status: TEMPERATURE_STATUS_UNAVAILABLE, Sensors: []TemperatureSensor{}}
+	for _, s := range sensors {
+		if s.Celsius < TEMPERATURE_MIN_VALID || s.Celsius > TEMPERATURE_MAX_VALID { continue }
+		s.Celsius = math.Round(s.Celsius*10) / 10
+		report.Sensors = append(report.Sensors, s)
+	}
+	if len(report.Sensors) == 0 { return report, nil }
+
+	sort.SliceStable(report.Sensors, func(i, j int) bool { return report.Sensors[i].Name < report.Sensors[j].Name })
+	report.Status = TEMPERATURE_STATUS_OK
+	report.CPUCelsius = headlineTemperature(report.Sensors)
+	return report, nil
+}
+
+// Temperatura principal do painel: a maior leitura da CPU ou, sem sensor de CPU, a maior de todas
+func headlineTemperature(sensors []TemperatureSensor) *float64 {
+	var best *float64
+	for _, cpuOnly := range []bool{true, false} {
+		for i := range sensors {
+			if cpuOnly && !sensors[i].IsCPU { continue }
+			if best == nil || sensors[i].Celsius > *best { best = &sensors[i].Celsius }
+		}
+		if best != nil { break }
+	}
+	if best == nil { return nil }
+	value := *best
+	return &value
+}
+
+func looksLikeCPUSensor(name string) bool {
+	name = strings.ToLower(name)
+	for _, hint := range []string{"cpu", "core", "package", "tctl", "tdie", "k10temp", "coretemp", "x86_pkg_temp"} {
+		if strings.Contains(name, hint) { return true }
+	}
+	return false
+}
+
+func windowsTemperatureSensors() []TemperatureSensor {
+	var sensors []TemperatureSensor
+
+	// Os monitores de hardware leem os sensores da placa e da CPU diretamente; têm prioridade sobre o ACPI.
+	// Com os dois instalados vale só o primeiro que responder, para não duplicar os mesmos sensores.
+	for _, monitor := range hardwareMonitorNamespaces {
+		var items []HardwareMonitorSensor
+		if err := queryWMINamespace(&items, "SensorType = 'Temperature'", monitor.namespace); err != nil { continue }
+		for _, item := range items {
+			sensors = append(sensors, TemperatureSensor{
+				Name:    item.Name,
+				Source:  monitor.source,
+				Celsius: float64(item.Value),
+				IsCPU:   strings.Contains(strings.ToLower(item.Parent), "cpu") || looksLikeCPUSensor(item.Name),
+			})
+		}
+		if len(sensors) > 0 { return sensors }
+	}
+
+	var zones []MSAcpi_ThermalZoneTemperature
+	if err := queryWMINamespace(&zones, "", `root\WMI`); err != nil { return nil }
+	for _, z := range zones {
+		sensor := TemperatureSensor{
+			Name:    z.InstanceName,
+			Source:  "acpi",
+			Celsius: decikelvinToCelsius(z.CurrentTemperature),
+			IsCPU:   looksLikeCPUSensor(z.InstanceName),
+		}
+		if z.CriticalTripPoint > 0 { sensor.CriticalCelsius = decikelvinToCelsius(z.CriticalTripPoint) }
+		sensors = append(sensors, sensor)
+	}
+	return sensors
+}
+
+func decikelvinToCelsius(value uint32) float64 {
+	return math.Round((float64(value)/10-273.15)*10) / 10
+}
+
+func linuxTemperatureSensors(ctx context.Context) []TemperatureSensor {
+	var sensors []TemperatureSensor
+
+	// O gopsutil lê /sys/class/hwmon (coretemp, k10temp, nvme, acpitz...)
+	temps, _ := host.SensorsTemperaturesWithContext(ctx)
+	for _, t := range temps {
+		sensors = append(sensors, TemperatureSensor{
+			Name:            t.SensorKey,
+			Source:          "hwmon",
+			Celsius:         t.Temperature,
+			HighCelsius:     t.High,
+			CriticalCelsius: t.Critical,
+			IsCPU:           looksLikeCPUSensor(t.SensorKey),
+		})
+	}
+	if len(sensors) > 0 { return sensors }
+
+	// Sem hwmon (alguns ARM e VMs): zonas térmicas do kernel
+	zones, _ := filepath.Glob("/sys/class/thermal/thermal_zone*")
+	for _, zone := range zones {
+		raw, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil { continue }
+		milli, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+		if err != nil { continue }
+
+		name := filepath.Base(zone)
+		if kind, err := os.ReadFile(filepath.Join(zone, "type")); err == nil {
+			name = fmt.Sprintf("%s_%s", strings.TrimSpace(string(kind)), strings.TrimPrefix(name, "thermal_zone"))
+		}
+		sensors = append(sensors, TemperatureSensor{
+			Name:    name,
+			Source:  "thermal_zone",
+			Celsius: float64(milli) / 1000,
+			IsCPU:   looksLikeCPUSensor(name),
+		})
+	}
+	return sensors
+}
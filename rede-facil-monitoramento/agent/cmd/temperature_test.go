@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWindowsTemperatureSensors(t *testing.T) {
+	cases := []struct {
+		name    string
+		results map[string]interface{}
+		errs    map[string]error
+		want    []TemperatureSensor
+	}{
+		{
+			"monitor de hardware",
+			map[string]interface{}{
+				"Sensor": []HardwareMonitorSensor{
+					{Name: "CPU Package", Parent: "/intelcpu/0", SensorType: "Temperature", Value: 54},
+					{Name: "Temperature #1", Parent: "/intelcpu/0", SensorType: "Temperature", Value: 51},
+					{Name: "Temperature", Parent: "/hdd/0", SensorType: "Temperature", Value: 38},
+				},
+				"MSAcpi_ThermalZoneTemperature": []MSAcpi_ThermalZoneTemperature{{InstanceName: "ACPI\\ThermalZone\\TZ00_0", CurrentTemperature: 3032}},
+			},
+			nil,
+			[]TemperatureSensor{
+				{Name: "CPU Package", Source: "librehardwaremonitor", Celsius: 54, IsCPU: true},
+				{Name: "Temperature #1", Source: "librehardwaremonitor", Celsius: 51, IsCPU: true},
+				{Name: "Temperature", Source: "librehardwaremonitor", Celsius: 38},
+			},
+		},
+		{
+			"sem monitor: zonas ACPI",
+			map[string]interface{}{
+				"MSAcpi_ThermalZoneTemperature": []MSAcpi_ThermalZoneTemperature{
+					{InstanceName: "ACPI\\ThermalZone\\CPUZ_0", CurrentTemperature: 3182, CriticalTripPoint: 3732},
+					{InstanceName: "ACPI\\ThermalZone\\TZ01_0", CurrentTemperature: 3002},
+				},
+			},
+			map[string]error{"Sensor": errors.New("namespace inválido")},
+			[]TemperatureSensor{
+				{Name: "ACPI\\ThermalZone\\CPUZ_0", Source: "acpi", Celsius: 45.1, CriticalCelsius: 100.1, IsCPU: true},
+				{Name: "ACPI\\ThermalZone\\TZ01_0", Source: "acpi", Celsius: 27.1},
+			},
+		},
+		{"nada disponível", nil, map[string]error{"Sensor": errors.New("namespace inválido"), "MSAcpi_ThermalZoneTemperature": errors.New("não suportado")}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			useFakeWMI(t, &fakeWMI{Results: c.results, Errors: c.errs})
+			if got := windowsTemperatureSensors(); !reflect.DeepEqual(got, c.want) { t.Errorf("sensores = %+v\nesperado %+v", got, c.want) }
+		})
+	}
+}
+
+func TestHeadlineTemperature(t *testing.T) {
+	cases := []struct {
+		name    string
+		sensors []TemperatureSensor
+		want    float64
+		wantNil bool
+	}{
+		{"maior da CPU", []TemperatureSensor{{Name: "Core 0", Celsius: 61, IsCPU: true}, {Name: "Core 1", Celsius: 66, IsCPU: true}, {Name: "nvme", Celsius: 70}}, 66, false},
+		{"sem CPU: maior de todas", []TemperatureSensor{{Name: "acpitz", Celsius: 40}, {Name: "nvme", Celsius: 48}}, 48, false},
+		{"sem sensores", nil, 0, true},
+	}
+	for _, c := range cases {
+		got := headlineTemperature(c.sensors)
+		if c.wantNil != (got == nil) || (got != nil && *got != c.want) { t.Errorf("%s: headlineTemperature = %v, esperado %v", c.name, got, c.want) }
+	}
+}
+
+func TestDecikelvinToCelsius(t *testing.T) {
+	cases := map[uint32]float64{2731: 0, 2732: 0.1, 3032: 30.1, 3182: 45.1, 3001: 27}
+	for value, want := range cases {
+		if got := decikelvinToCelsius(value); got != want { t.Errorf("decikelvinToCelsius(%d) = %v, esperado %v", value, got, want) }
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gonet "github.com/shirou/gopsutil/v3/net"
+)
+
+const LINK_SPEED_CACHE_TTL = 5 * time.Minute
+
+// Vazão e contadores de erro de uma interface, calculados como delta desde a amostra anterior
+type InterfaceTraffic struct {
+	InterfaceName      string  `json:"interface_name"`
+	SpeedMbps          int     `json:"speed_mbps"`
+	BytesSentPerSec    float64 `json:"bytes_sent_per_sec"`
+	BytesRecvPerSec    float64 `json:"bytes_recv_per_sec"`
+	PacketsSentPerSec  float64 `json:"packets_sent_per_sec"`
+	PacketsRecvPerSec  float64 `json:"packets_recv_per_sec"`
+	ErrorsIn           uint64  `json:"errors_in"`
+	ErrorsOut          uint64  `json:"errors_out"`
+	DropsIn            uint64  `json:"drops_in"`
+	DropsOut           uint64  `json:"drops_out"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+}
+
+var lastIOCounters map[string]gonet.IOCountersStat
+var lastIOSample time.Time
+var trafficMutex sync.Mutex
+
+var linkSpeedCache map[string]int
+var linkSpeedCachedAt time.Time
+var linkSpeedMutex sync.Mutex
+
+// Primeira chamada só guarda a amostra base e retorna vazio
+func collectInterfaceTraffic() []InterfaceTraffic {
+	counters, err := gonet.IOCounters(true)
+	if err != nil { return nil }
+
+	current := map[string]gonet.IOCountersStat{}
+	for _, c := range counters { current[c.Name] = c }
+	now := time.Now()
+
+	trafficMutex.Lock()
+	previous, prevTime := lastIOCounters, lastIOSample
+	lastIOCounters, lastIOSample = current, now
+	trafficMutex.Unlock()
+
+	if previous == nil { return nil }
+	elapsed := now.Sub(prevTime).Seconds()
+	if elapsed <= 0 { return nil }
+
+	speeds := getLinkSpeeds()
+	var traffic []InterfaceTraffic
+	for _, nic := range collectNetworkInterfaces() {
+		cur, ok := current[nic.InterfaceName]
+		if !ok { continue }
+		prev, ok := previous[nic.InterfaceName]
+		if !ok { continue }
+
+		t := InterfaceTraffic{
+			InterfaceName:     nic.InterfaceName,
+			SpeedMbps:         speeds[nic.InterfaceName],
+			BytesSentPerSec:   rate(cur.BytesSent, prev.BytesSent, elapsed),
+			BytesRecvPerSec:   rate(cur.BytesRecv, prev.BytesRecv, elapsed),
+			PacketsSentPerSec: rate(cur.PacketsSent, prev.PacketsSent, elapsed),
+			PacketsRecvPerSec: rate(cur.PacketsRecv, prev.PacketsRecv, elapsed),
+			ErrorsIn:          delta(cur.Errin, prev.Errin),
+			ErrorsOut:         delta(cur.Errout, prev.Errout),
+			DropsIn:           delta(cur.Dropin, prev.Dropin),
+			DropsOut:          delta(cur.Dropout, prev.Dropout),
+		}
+		if t.SpeedMbps > 0 {
+			busiest := math.Max(t.BytesSentPerSec, t.BytesRecvPerSec) * 8
+			t.UtilizationPercent = math.Round(busiest/(float64(t.SpeedMbps)*1e6)*1000) / 10
+		}
+		traffic = append(traffic, t)
+	}
+	return traffic
+}
+
+// Contadores zerados (reinício do driver) não geram delta negativo
+func delta(cur, prev uint64) uint64 {
+	if cur < prev { return 0 }
+	return cur - prev
+}
+
+func rate(cur, prev uint64, elapsed float64) float64 {
+	return math.Round(float64(delta(cur, prev))/elapsed*10) / 10
+}
+
+// Velocidade de link por interface, em Mbps (em cache, pois a consulta no Windows é lenta)
+func getLinkSpeeds() map[string]int {
+	linkSpeedMutex.Lock()
+	defer linkSpeedMutex.Unlock()
+	if linkSpeedCache != nil && time.Since(linkSpeedCachedAt) < LINK_SPEED_CACHE_TTL { return linkSpeedCache }
+
+	speeds := map[string]int{}
+	if runtime.GOOS == "windows" {
+		psCommand := `Get-NetAdapter | ForEach-Object { $_.Name + "|||" + $_.ReceiveLinkSpeed }`
+		output, err := runCommandHidden("powershell", "-NoProfile", "-Command", psCommand)
+		if err == nil {
+			for _, line := range strings.Split(output, "\n") {
+				parts := strings.Split(strings.TrimSpace(line), "|||")
+				if len(parts) != 2 { continue }
+				bps, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+				if err == nil { speeds[parts[0]] = int(bps / 1000000) }
+			}
+		}
+	} else {
+		paths, _ := filepath.Glob("/sys/class/net/*/speed")
+		for _, p := range paths {
+			data, err := os.ReadFile(p)
+			if err != nil { continue }
+			mbps, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			if err == nil && mbps > 0 { speeds[filepath.Base(filepath.Dir(p))] = mbps }
+		}
+	}
+
+	linkSpeedCache, linkSpeedCachedAt = speeds, time.Now()
+	return speeds
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestTrafficRate(t *testing.T) {
+	cases := []struct {
+		cur, prev uint64
+		elapsed   float64
+		wantDelta uint64
+		wantRate  float64
+	}{
+		{1500, 500, 10, 1000, 100},
+		{1000, 1000, 10, 0, 0},
+		{100, 5000, 10, 0, 0}, // contador zerado pelo driver
+		{1000, 0, 3, 1000, 333.3},
+	}
+	for _, c := range cases {
+		if got := delta(c.cur, c.prev); got != c.wantDelta { t.Errorf("delta(%d, %d) = %d, esperado %d", c.cur, c.prev, got, c.wantDelta) }
+		if got := rate(c.cur, c.prev, c.elapsed); got != c.wantRate { t.Errorf("rate(%d, %d, %v) = %v, esperado %v", c.cur, c.prev, c.elapsed, got, c.wantRate) }
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const DOWNLOAD_TIMEOUT = 15 * time.Minute
+
+// Baixa um arquivo para a pasta temporária e confere o SHA-256 antes de liberar o uso.
+// O chamador é responsável por remover o arquivo retornado.
+func downloadVerifiedFile(url string, expectedSHA256 string, fileName string) (string, error) {
+	expected := strings.ToLower(strings.TrimSpace(expectedSHA256))
+	if expected == "" { return "", fmt.Errorf("hash SHA-256 não informado para %s", url) }
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil { return "", err }
+	if isBackendURL(req.URL) { req.Header.Set("x-agent-secret", AgentSecret) }
+
+	client := *httpClient
+	client.Timeout = DOWNLOAD_TIMEOUT
+	resp, err := client.Do(req)
+	if err != nil { return "", fmt.Errorf("falha no download: %v", err) }
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("falha no download: HTTP %d", resp.StatusCode)
+	}
+
+	if fileName == "" { fileName = filepath.Base(req.URL.Path) }
+	tmpDir, err := os.MkdirTemp("", "agent_download_*")
+	if err != nil { return "", err }
+	destPath := filepath.Join(tmpDir, filepath.Base(fileName))
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hasher), resp.Body)
+	out.Close()
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", fmt.Errorf("falha ao gravar download: %v", err)
+	}
+
+	got := hex.EncodeToString(hasher.Sum(nil))
+	if got != expected {
+		os.RemoveAll(tmpDir)
+		log.Printf("❌ Hash inválido para %s: esperado %s, recebido %s", url, expected, got)
+		return "", fmt.Errorf("hash SHA-256 não confere (esperado %s, recebido %s)", expected, got)
+	}
+
+	return destPath, nil
+}
+
+// O segredo do agente só vai para o próprio backend; pacotes hospedados em terceiros não o recebem
+func isBackendURL(u *url.URL) bool {
+	host, port := backendHostPort()
+	if host == "" || !strings.EqualFold(u.Hostname(), host) { return false }
+	urlPort := u.Port()
+	if urlPort == "" {
+		urlPort = "80"
+		if u.Scheme == "https" { urlPort = "443" }
+	}
+	return urlPort == port
+}
+
+// Remove o arquivo baixado e a pasta temporária criada para ele
+func removeDownloadedFile(path string) {
+	if path == "" { return }
+	os.RemoveAll(filepath.Dir(path))
+}
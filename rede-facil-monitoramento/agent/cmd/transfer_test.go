@@ -0,0 +1,69 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadVerifiedFile(t *testing.T) {
+	content := []byte("instalador de teste")
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	secret := "não enviado"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if values, ok := r.Header["X-Agent-Secret"]; ok { secret = values[0] }
+		if r.URL.Path != "/files/setup.msi" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	path, err := downloadVerifiedFile(server.URL+"/files/setup.msi", " "+strings.ToUpper(hash)+"\n", "")
+	if err != nil { t.Fatal(err) }
+	defer removeDownloadedFile(path)
+	if filepath.Base(path) != "setup.msi" { t.Errorf("arquivo = %s", path) }
+	if data, _ := os.ReadFile(path); string(data) != string(content) { t.Errorf("conteúdo = %q", data) }
+	if secret != "não enviado" { t.Errorf("segredo do agente enviado a um host de terceiros: %q", secret) }
+
+	renamed, err := downloadVerifiedFile(server.URL+"/files/setup.msi", hash, "../instalador.msi")
+	if err != nil { t.Fatal(err) }
+	defer removeDownloadedFile(renamed)
+	if filepath.Base(renamed) != "instalador.msi" || filepath.Dir(renamed) == filepath.Dir(path) { t.Errorf("arquivo renomeado = %s", renamed) }
+
+	failures := []struct{ name, url, hash string }{
+		{"hash não informado", server.URL + "/files/setup.msi", ""},
+		{"hash diferente", server.URL + "/files/setup.msi", strings.Repeat("0", 64)},
+		{"arquivo inexistente", server.URL + "/files/outro.msi", hash},
+	}
+	for _, c := range failures {
+		if got, err := downloadVerifiedFile(c.url, c.hash, ""); err == nil || got != "" { t.Errorf("%s: downloadVerifiedFile = %q, %v", c.name, got, err) }
+	}
+}
+
+func TestIsBackendURL(t *testing.T) {
+	backend, _ := url.Parse(API_BASE_URL)
+	cases := []struct {
+		rawURL string
+		want   bool
+	}{
+		{API_BASE_URL + "/updates/agente.exe", true},
+		{strings.Replace(API_BASE_URL, backend.Hostname(), strings.ToUpper(backend.Hostname()), 1) + "/files/setup.msi", true},
+		{"https://" + backend.Hostname() + ":8443/setup.msi", false},
+		{"https://downloads.fornecedor.com.br/setup.msi", false},
+		{"http://127.0.0.1:3001/setup.msi", false},
+	}
+	for _, c := range cases {
+		u, _ := url.Parse(c.rawURL)
+		if got := isBackendURL(u); got != c.want { t.Errorf("isBackendURL(%s) = %v, esperado %v", c.rawURL, got, c.want) }
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+// O systray exige cgo e um indicador de bandeja fora do Windows; nos demais sistemas
+// o agente roda sem ícone e a contagem de reinício/desligamento só aparece no log.
+
+func runTray() {
+	select {}
+}
+
+func hidePowerTrayItems() {}
+
+func showPowerTrayItems(postponeTitle string) {}
+
+func setPowerTrayStatus(status string) {}
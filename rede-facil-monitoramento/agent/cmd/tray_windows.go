@@ -0,0 +1,100 @@
+//go:build windows
+
+package main
+
+import (
+	"log"
+
+	"github.com/getlantern/systray"
+)
+
+// --- SYSTEM TRAY ---
+
+func runTray() {
+	systray.Run(onReady, onExit)
+}
+
+func onReady() {
+	// Verifica se o ícone foi carregado pelo embed
+	if len(iconData) > 0 {
+		systray.SetIcon(iconData)
+		log.Println("✅ Ícone definido na bandeja com sucesso.")
+	} else {
+		log.Println("❌ ERRO: Ícone não encontrado ou vazio!")
+	}
+	
+	systray.SetTitle("Rede Fácil Monitoramento")
+	systray.SetTooltip("Agente Ativo - Monitoramento e Suporte")
+
+	mRequestHelp := systray.AddMenuItem("🆘 Solicitar Suporte TI", "Chamar técnico imediatamente")
+	systray.AddSeparator()
+	mInfo := systray.AddMenuItem("✅ Monitoramento Ativo", "Sistema protegido e monitorado")
+	mInfo.Disable()
+	setupPowerTrayItems()
+	
+	go func() {
+		for {
+			select {
+			case <-mRequestHelp.ClickedCh:
+				log.Println("🆘 Usuário clicou em Solicitar Suporte")
+				// Roda em goroutine para não travar a interface
+				go showNativeMessage("Aguarde", "Enviando solicitação para a central de TI...", MB_ICONASTERISK)
+				sendHelpRequest()
+			}
+		}
+	}()
+}
+
+func onExit() {
+	// Limpeza
+}
+
+var mPowerStatus, mPowerNow, mPowerPostpone *systray.MenuItem
+
+// Cria os itens da bandeja usados durante a contagem regressiva (ficam ocultos até haver uma ação)
+func setupPowerTrayItems() {
+	systray.AddSeparator()
+	mPowerStatus = systray.AddMenuItem("", "Ação agendada pela TI")
+	mPowerStatus.Disable()
+	mPowerNow = systray.AddMenuItem("⏻ Executar agora", "Executar a ação agendada imediatamente")
+	mPowerPostpone = systray.AddMenuItem("⏰ Adiar", "Adiar a ação agendada")
+	hidePowerTrayItems()
+
+	go func() {
+		for {
+			select {
+			case <-mPowerNow.ClickedCh:
+				signalPowerAction(func(pa *powerAction) chan bool { return pa.nowCh })
+			case <-mPowerPostpone.ClickedCh:
+				signalPowerAction(func(pa *powerAction) chan bool { return pa.postpone })
+			}
+		}
+	}()
+}
+
+func hidePowerTrayItems() {
+	if mPowerStatus == nil { return }
+	mPowerStatus.Hide()
+	mPowerNow.Hide()
+	mPowerPostpone.Hide()
+	systray.SetTooltip("Agente Ativo - Monitoramento e Suporte")
+}
+
+// postponeTitle vazio esconde o botão de adiar
+func showPowerTrayItems(postponeTitle string) {
+	if mPowerStatus == nil { return }
+	mPowerStatus.Show()
+	mPowerNow.Show()
+	if postponeTitle != "" {
+		mPowerPostpone.SetTitle(postponeTitle)
+		mPowerPostpone.Show()
+	} else {
+		mPowerPostpone.Hide()
+	}
+}
+
+func setPowerTrayStatus(status string) {
+	if mPowerStatus == nil { return }
+	mPowerStatus.SetTitle(status)
+	systray.SetTooltip(status)
+}
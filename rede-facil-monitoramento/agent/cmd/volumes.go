@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+const VOLUME_KIND_FIXED = "fixed"
+const VOLUME_KIND_REMOVABLE = "removable"
+const VOLUME_KIND_NETWORK = "network"
+const VOLUME_KIND_OPTICAL = "optical"
+
+// Sistemas de arquivos de rede montados no Linux
+var networkFileSystems = map[string]bool{"nfs": true, "nfs4": true, "cifs": true, "smbfs": true, "smb3": true, "sshfs": true, "fuse.sshfs": true, "davfs": true}
+
+// Uso de um volume montado (letra de unidade no Windows, ponto de montagem no Linux)
+type VolumeUsage struct {
+	Mountpoint  string  `json:"mountpoint"`
+	Device      string  `json:"device"`
+	Label       string  `json:"label"`
+	FileSystem  string  `json:"file_system"`
+	Kind        string  `json:"kind"`
+	IsSystem    bool    `json:"is_system"`
+	TotalGB     float64 `json:"total_gb"`
+	FreeGB      float64 `json:"free_gb"`
+	FreePercent float64 `json:"free_percent"`
+}
+
+type PhysicalDisk struct {
+	Device       string  `json:"device"`
+	Model        string  `json:"model"`
+	SerialNumber string  `json:"serial_number"`
+	MediaType    string  `json:"media_type"`
+	BusType      string  `json:"bus_type"`
+	SizeGB       float64 `json:"size_gb"`
+	Removable    bool    `json:"removable"`
+}
+
+type Win32_LogicalDisk struct {
+	DeviceID   string
+	DriveType  uint32
+	FileSystem string
+	VolumeName string
+	Size       uint64
+	FreeSpace  uint64
+}
+
+// Barramentos do MSFT_PhysicalDisk.BusType
+var storageBusTypes = map[uint16]string{1: "SCSI", 3: "ATA", 7: "USB", 8: "RAID", 10: "SAS", 11: "SATA", 12: "SD", 13: "MMC", 15: "File Backed Virtual", 16: "Storage Spaces", 17: "NVMe"}
+
+func bytesToGB(value uint64) float64 {
+	return math.Round(float64(value)/(1024*1024*1024)*10) / 10
+}
+
+func systemMountpoint() string {
+	if runtime.GOOS != "windows" { return "/" }
+	drive := os.Getenv("SystemDrive")
+	if drive == "" { drive = "C:" }
+	return strings.ToUpper(drive)
+}
+
+// Volume do sistema, usado nos campos antigos disk_total_gb e disk_free_percent
+func systemVolume(volumes []VolumeUsage) *VolumeUsage {
+	for i := range volumes {
+		if volumes[i].IsSystem { return &volumes[i] }
+	}
+	return nil
+}
+
+func collectVolumes(ctx context.Context) (interface{}, error) {
+	var volumes []VolumeUsage
+	var err error
+	if runtime.GOOS == "windows" {
+		volumes, err = windowsVolumes()
+	} else {
+		volumes, err = linuxVolumes(ctx)
+	}
+	if err != nil { return nil, err }
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Mountpoint < volumes[j].Mountpoint })
+	return volumes, nil
+}
+
+func windowsVolumes() ([]VolumeUsage, error) {
+	var disks []Win32_LogicalDisk
+	if err := queryWMI(&disks, "DriveType = 2 OR DriveType = 3 OR DriveType = 4 OR DriveType = 5"); err != nil { return nil, err }
+
+	system := systemMountpoint()
+	volumes := []VolumeUsage{}
+	for _, d := range disks {
+		// Leitor de cartão ou DVD vazio aparece com tamanho zero
+		if d.Size == 0 { continue }
+		kind := VOLUME_KIND_FIXED
+		switch d.DriveType {
+		case 2:
+			kind = VOLUME_KIND_REMOVABLE
+		case 4:
+			kind = VOLUME_KIND_NETWORK
+		case 5:
+			kind = VOLUME_KIND_OPTICAL
+		}
+		volumes = append(volumes, VolumeUsage{
+			Mountpoint:  d.DeviceID,
+			Device:      d.DeviceID,
+			Label:       d.VolumeName,
+			FileSystem:  d.FileSystem,
+			Kind:        kind,
+			IsSystem:    strings.EqualFold(d.DeviceID, system),
+			TotalGB:     bytesToGB(d.Size),
+			FreeGB:      bytesToGB(d.FreeSpace),
+			FreePercent: math.Round(float64(d.FreeSpace)/float64(d.Size)*1000) / 10,
+		})
+	}
+	return volumes, nil
+}
+
+func linuxVolumes(ctx context.Context) ([]VolumeUsage, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, true)
+	if err != nil && len(partitions) == 0 { return nil, err }
+
+	seen := map[string]bool{}
+	volumes := []VolumeUsage{}
+	for _, p := range partitions {
+		kind := VOLUME_KIND_FIXED
+		switch {
+		case networkFileSystems[p.Fstype]:
+			kind = VOLUME_KIND_NETWORK
+		case p.Fstype == "iso9660" || p.Fstype == "udf":
+			kind = VOLUME_KIND_OPTICAL
+		case !strings.HasPrefix(p.Device, "/dev/"):
+			// tmpfs, proc, cgroup, overlay e afins
+			continue
+		case strings.HasPrefix(p.Device, "/dev/loop"):
+			// Pacotes snap montados em loop
+			continue
+		case isRemovableBlockDevice(p.Device):
+			kind = VOLUME_KIND_REMOVABLE
+		}
+		// Bind mounts repetem o mesmo dispositivo; fica o primeiro ponto de montagem
+		if seen[p.Device] { continue }
+		seen[p.Device] = true
+
+		usage, err := disk.UsageWithContext(ctx, p.Mountpoint)
+		if err != nil || usage.Total == 0 { continue }
+		volumes = append(volumes, VolumeUsage{
+			Mountpoint:  p.Mountpoint,
+			Device:      p.Device,
+			FileSystem:  p.Fstype,
+			Kind:        kind,
+			IsSystem:    p.Mountpoint == "/",
+			TotalGB:     bytesToGB(usage.Total),
+			FreeGB:      bytesToGB(usage.Free),
+			FreePercent: math.Round(float64(usage.Free)/float64(usage.Total)*1000) / 10,
+		})
+	}
+	return volumes, nil
+}
+
+func isRemovableBlockDevice(device string) bool {
+	return isRemovableBlockDeviceIn("/sys/class/block", device)
+}
+
+// /dev/sdb1 -> /sys/class/block/sdb1/../removable (o atributo fica no disco, não na partição).
+// /sys/class/block/sdb1 é um link para .../block/sdb/sdb1: o ".." precisa ser resolvido depois do link,
+// e não como texto (filepath.Join("sdb1", "..") volta para /sys/class/block).
+func isRemovableBlockDeviceIn(classDir string, device string) bool {
+	dir, err := filepath.EvalSymlinks(filepath.Join(classDir, filepath.Base(device)))
+	if err != nil { return false }
+	for _, path := range []string{filepath.Join(dir, "removable"), filepath.Join(filepath.Dir(dir), "removable")} {
+		if value := readSysfsString(path); value != "" { return value == "1" }
+	}
+	return false
+}
+
+func collectPhysicalDisks(ctx context.Context) (interface{}, error) {
+	if runtime.GOOS == "windows" { return windowsPhysicalDisks() }
+	return linuxPhysicalDisks(), nil
+}
+
+func windowsPhysicalDisks() ([]PhysicalDisk, error) {
+	var physical []MSFT_PhysicalDisk
+	if err := queryWMINamespace(&physical, "", STORAGE_WMI_NAMESPACE); err != nil { return nil, err }
+
+	disks := []PhysicalDisk{}
+	for _, p := range physical {
+		bus := storageBusTypes[p.BusType]
+		if bus == "" { bus = "Desconhecido" }
+		// MediaType: 3 = HDD, 4 = SSD, 5 = SCM
+		media := "Desconhecido"
+		switch {
+		case p.BusType == 17:
+			media = "NVMe"
+		case p.MediaType == 3:
+			media = "HDD"
+		case p.MediaType == 4 || p.MediaType == 5:
+			media = "SSD"
+		}
+		disks = append(disks, PhysicalDisk{
+			Device:       "PhysicalDrive" + p.DeviceId,
+			Model:        strings.TrimSpace(p.FriendlyName),
+			SerialNumber: strings.TrimSpace(p.SerialNumber),
+			MediaType:    media,
+			BusType:      bus,
+			SizeGB:       bytesToGB(p.Size),
+			Removable:    p.BusType == 7 || p.BusType == 12 || p.BusType == 13,
+		})
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Device < disks[j].Device })
+	return disks, nil
+}
+
+func linuxPhysicalDisks() []PhysicalDisk {
+	disks := []PhysicalDisk{}
+	entries, _ := os.ReadDir("/sys/block")
+	for _, e := range entries {
+		name := e.Name()
+		if isVirtualBlockDevice(name) { continue }
+		base := filepath.Join("/sys/block", name)
+
+		// "size" é sempre em setores de 512 bytes, independente do setor físico
+		sectors, _ := strconv.ParseUint(readSysfsString(filepath.Join(base, "size")), 10, 64)
+		if sectors == 0 { continue }
+
+		media := "SSD"
+		if readSysfsString(filepath.Join(base, "queue", "rotational")) == "1" { media = "HDD" }
+		bus := "SATA"
+		switch {
+		case strings.HasPrefix(name, "nvme"):
+			media, bus = "NVMe", "NVMe"
+		case strings.HasPrefix(name, "mmcblk"):
+			bus = "MMC"
+		case strings.HasPrefix(name, "vd"), strings.HasPrefix(name, "xvd"):
+			bus = "Virtual"
+		}
+		if link, err := filepath.EvalSymlinks(base); err == nil && strings.Contains(link, "/usb") { bus = "USB" }
+
+		disks = append(disks, PhysicalDisk{
+			Device:       "/dev/" + name,
+			Model:        readSysfsString(filepath.Join(base, "device", "model")),
+			SerialNumber: readSysfsString(filepath.Join(base, "device", "serial")),
+			MediaType:    media,
+			BusType:      bus,
+			SizeGB:       bytesToGB(sectors * 512),
+			Removable:    readSysfsString(filepath.Join(base, "removable")) == "1" || bus == "USB",
+		})
+	}
+	return disks
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWindowsVolumes(t *testing.T) {
+	system := systemMountpoint()
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"Win32_LogicalDisk": []Win32_LogicalDisk{
+			{DeviceID: system, DriveType: 3, FileSystem: "NTFS", VolumeName: "Sistema", Size: 256 << 30, FreeSpace: 64 << 30},
+			{DeviceID: "E:", DriveType: 2, FileSystem: "FAT32", Size: 16 << 30, FreeSpace: 12 << 30},
+			{DeviceID: "F:", DriveType: 2}, // leitor de cartão vazio
+			{DeviceID: "Z:", DriveType: 4, FileSystem: "NTFS", Size: 1 << 40, FreeSpace: 1 << 38},
+			{DeviceID: "D:", DriveType: 5, FileSystem: "CDFS", Size: 700 << 20},
+		},
+	}})
+
+	volumes, err := windowsVolumes()
+	if err != nil { t.Fatal(err) }
+	if len(volumes) != 4 { t.Fatalf("volumes = %+v, esperado 4 (sem a unidade vazia)", volumes) }
+
+	want := []VolumeUsage{
+		{Mountpoint: system, Device: system, Label: "Sistema", FileSystem: "NTFS", Kind: VOLUME_KIND_FIXED, IsSystem: true, TotalGB: 256, FreeGB: 64, FreePercent: 25},
+		{Mountpoint: "E:", Device: "E:", FileSystem: "FAT32", Kind: VOLUME_KIND_REMOVABLE, TotalGB: 16, FreeGB: 12, FreePercent: 75},
+		{Mountpoint: "Z:", Device: "Z:", FileSystem: "NTFS", Kind: VOLUME_KIND_NETWORK, TotalGB: 1024, FreeGB: 256, FreePercent: 25},
+		{Mountpoint: "D:", Device: "D:", FileSystem: "CDFS", Kind: VOLUME_KIND_OPTICAL, TotalGB: 0.7, FreeGB: 0, FreePercent: 0},
+	}
+	for i := range want {
+		if volumes[i] != want[i] { t.Errorf("volume %d = %+v, esperado %+v", i, volumes[i], want[i]) }
+	}
+	if sys := systemVolume(volumes); sys == nil || sys.Mountpoint != system { t.Errorf("systemVolume = %+v", sys) }
+	if sys := systemVolume(volumes[1:]); sys != nil { t.Errorf("systemVolume sem o volume do sistema = %+v", sys) }
+}
+
+func TestWindowsVolumesError(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Errors: map[string]error{"Win32_LogicalDisk": errors.New("acesso negado")}})
+	if volumes, err := windowsVolumes(); err == nil || volumes != nil { t.Errorf("windowsVolumes = %+v, %v", volumes, err) }
+}
+
+func TestWindowsPhysicalDisks(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Results: map[string]interface{}{
+		"MSFT_PhysicalDisk": []MSFT_PhysicalDisk{
+			{DeviceId: "1", FriendlyName: "WD Elements ", SerialNumber: " WX12", MediaType: 3, BusType: 7, Size: 1 << 40},
+			{DeviceId: "0", FriendlyName: "Samsung SSD 980", MediaType: 4, BusType: 17, Size: 500 << 30},
+			{DeviceId: "2", FriendlyName: "Disco virtual", BusType: 99, Size: 32 << 30},
+		},
+	}})
+
+	disks, err := windowsPhysicalDisks()
+	if err != nil { t.Fatal(err) }
+	want := []PhysicalDisk{
+		{Device: "PhysicalDrive0", Model: "Samsung SSD 980", MediaType: "NVMe", BusType: "NVMe", SizeGB: 500},
+		{Device: "PhysicalDrive1", Model: "WD Elements", SerialNumber: "WX12", MediaType: "HDD", BusType: "USB", SizeGB: 1024, Removable: true},
+		{Device: "PhysicalDrive2", Model: "Disco virtual", MediaType: "Desconhecido", BusType: "Desconhecido", SizeGB: 32},
+	}
+	if len(disks) != len(want) { t.Fatalf("discos = %+v", disks) }
+	for i := range want {
+		if disks[i] != want[i] { t.Errorf("disco %d = %+v, esperado %+v", i, disks[i], want[i]) }
+	}
+}
+
+func TestIsRemovableBlockDevice(t *testing.T) {
+	// Mesma estrutura do sysfs: /sys/class/block/<nome> aponta para o dispositivo, partições ficam dentro do disco
+	root := t.TempDir()
+	devices := filepath.Join(root, "devices")
+	classDir := filepath.Join(root, "class", "block")
+	disks := map[string]string{"sda": "0", "sdb": "1"}
+	if err := os.MkdirAll(classDir, 0755); err != nil { t.Fatal(err) }
+	for disk, removable := range disks {
+		diskDir := filepath.Join(devices, disk)
+		if err := os.MkdirAll(filepath.Join(diskDir, disk+"1"), 0755); err != nil { t.Fatal(err) }
+		os.WriteFile(filepath.Join(diskDir, "removable"), []byte(removable+"\n"), 0644)
+		if err := os.Symlink(diskDir, filepath.Join(classDir, disk)); err != nil { t.Skip("symlink indisponível:", err) }
+		os.Symlink(filepath.Join(diskDir, disk+"1"), filepath.Join(classDir, disk+"1"))
+	}
+	os.WriteFile(filepath.Join(classDir, "removable"), []byte("1\n"), 0644) // armadilha do ".." resolvido como texto
+
+	cases := []struct {
+		device string
+		want   bool
+	}{
+		{"/dev/sdb", true},
+		{"/dev/sdb1", true},
+		{"/dev/sda", false},
+		{"/dev/sda1", false},
+		{"/dev/sdz1", false},
+	}
+	for _, c := range cases {
+		if got := isRemovableBlockDeviceIn(classDir, c.device); got != c.want { t.Errorf("isRemovableBlockDeviceIn(%s) = %v, esperado %v", c.device, got, c.want) }
+	}
+}
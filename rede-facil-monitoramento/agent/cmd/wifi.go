@@ -0,0 +1,232 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Conexão sem fio ativa. No Windows o RSSI é estimado a partir da qualidade (%) informada pelo netsh.
+type WiFiInfo struct {
+	InterfaceName  string  `json:"interface_name"`
+	SSID           string  `json:"ssid"`
+	BSSID          string  `json:"bssid"`
+	SignalPercent  int     `json:"signal_percent"`
+	RSSIdBm        int     `json:"rssi_dbm"`
+	Channel        int     `json:"channel"`
+	Band           string  `json:"band"`
+	RadioType      string  `json:"radio_type"`
+	RxRateMbps     float64 `json:"rx_rate_mbps"`
+	TxRateMbps     float64 `json:"tx_rate_mbps"`
+	Authentication string  `json:"authentication"`
+}
+
+// Rótulos do "netsh wlan show interfaces" em inglês e português
+var netshWlanKeys = map[string]string{
+	"name":                       "name",
+	"nome":                       "name",
+	"ssid":                       "ssid",
+	"bssid":                      "bssid",
+	"ap bssid":                   "bssid",
+	"radio type":                 "radio",
+	"tipo de rádio":              "radio",
+	"band":                       "band",
+	"banda":                      "band",
+	"channel":                    "channel",
+	"canal":                      "channel",
+	"authentication":             "auth",
+	"autenticação":               "auth",
+	"receive rate (mbps)":        "rx",
+	"taxa de recepção (mbps)":    "rx",
+	"transmit rate (mbps)":       "tx",
+	"taxa de transmissão (mbps)": "tx",
+	"signal":                     "signal",
+	"sinal":                      "signal",
+}
+
+var lastWiFiInfo *WiFiInfo
+var wifiMutex sync.Mutex
+
+// Coleta a conexão Wi-Fi ativa (nil em máquinas cabeadas) e guarda para os envios de rede
+func collectWiFiInfo() *WiFiInfo {
+	var info *WiFiInfo
+	if runtime.GOOS == "windows" {
+		info = collectWindowsWiFi()
+	} else {
+		info = collectLinuxWiFi()
+	}
+	wifiMutex.Lock()
+	lastWiFiInfo = info
+	wifiMutex.Unlock()
+	return info
+}
+
+// Última amostra coletada junto com a telemetria
+func cachedWiFiInfo() *WiFiInfo {
+	wifiMutex.Lock()
+	defer wifiMutex.Unlock()
+	return lastWiFiInfo
+}
+
+func collectWindowsWiFi() *WiFiInfo {
+	output, err := runCommandHidden("netsh", "wlan", "show", "interfaces")
+	if err != nil { return nil }
+	return parseNetshWlanInterfaces(decodeOEMOutput(output, oemCodePage()))
+}
+
+// Code pages OEM dos consoles em português, espanhol e inglês
+var oemCharmaps = map[uint32]*charmap.Charmap{
+	437: charmap.CodePage437,
+	850: charmap.CodePage850,
+	858: charmap.CodePage858,
+	860: charmap.CodePage860,
+}
+
+// Ferramentas de console como o netsh escrevem no code page OEM (850 no Windows em pt-BR),
+// então "Autenticação" chega como bytes CP850 e não casaria com os rótulos em UTF-8.
+func decodeOEMOutput(output string, codePage uint32) string {
+	if codePage == 65001 || utf8.ValidString(output) { return output }
+	cm, ok := oemCharmaps[codePage]
+	if !ok { cm = charmap.CodePage850 }
+	decoded, err := cm.NewDecoder().String(output)
+	if err != nil { return output }
+	return decoded
+}
+
+func parseNetshWlanInterfaces(output string) *WiFiInfo {
+	var current *WiFiInfo
+	var found *WiFiInfo
+	for _, line := range strings.Split(output, "\n") {
+		idx := strings.Index(line, ":")
+		if idx < 0 { continue }
+		key := netshWlanKeys[strings.ToLower(strings.TrimSpace(line[:idx]))]
+		value := strings.TrimSpace(line[idx+1:])
+
+		switch key {
+		case "name":
+			if current != nil && current.SSID != "" && found == nil { found = current }
+			current = &WiFiInfo{InterfaceName: value}
+		case "":
+			continue
+		}
+		if current == nil { continue }
+
+		switch key {
+		case "ssid":
+			current.SSID = value
+		case "bssid":
+			current.BSSID = strings.ToUpper(value)
+		case "radio":
+			current.RadioType = value
+		case "band":
+			current.Band = value
+		case "channel":
+			current.Channel, _ = strconv.Atoi(value)
+		case "auth":
+			current.Authentication = value
+		case "rx":
+			current.RxRateMbps, _ = strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		case "tx":
+			current.TxRateMbps, _ = strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		case "signal":
+			current.SignalPercent, _ = strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(value, "%")))
+			current.RSSIdBm = current.SignalPercent/2 - 100
+		}
+	}
+	if found == nil && current != nil && current.SSID != "" { found = current }
+	if found != nil && found.Band == "" { found.Band = bandFromChannel(found.Channel) }
+	return found
+}
+
+func collectLinuxWiFi() *WiFiInfo {
+	if _, err := exec.LookPath("iw"); err != nil { return nil }
+	devOut, err := runCommandHidden("iw", "dev")
+	if err != nil { return nil }
+
+	for _, line := range strings.Split(devOut, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "Interface" { continue }
+		if info := linuxWiFiLink(fields[1]); info != nil { return info }
+	}
+	return nil
+}
+
+// Interpreta "iw dev <if> link" (nl80211)
+func linuxWiFiLink(iface string) *WiFiInfo {
+	output, err := runCommandHidden("iw", "dev", iface, "link")
+	if err != nil || strings.HasPrefix(strings.TrimSpace(output), "Not connected") { return nil }
+
+	info := &WiFiInfo{InterfaceName: iface}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "Connected to ") && len(fields) >= 3:
+			info.BSSID = strings.ToUpper(fields[2])
+		case strings.HasPrefix(line, "SSID:"):
+			info.SSID = strings.TrimSpace(strings.TrimPrefix(line, "SSID:"))
+		case strings.HasPrefix(line, "freq:") && len(fields) >= 2:
+			freq, _ := strconv.ParseFloat(fields[1], 64)
+			info.Channel, info.Band = channelFromFrequency(int(freq))
+		case strings.HasPrefix(line, "signal:") && len(fields) >= 2:
+			info.RSSIdBm, _ = strconv.Atoi(fields[1])
+			info.SignalPercent = rssiToPercent(info.RSSIdBm)
+		case strings.HasPrefix(line, "rx bitrate:") && len(fields) >= 3:
+			info.RxRateMbps, _ = strconv.ParseFloat(fields[2], 64)
+		case strings.HasPrefix(line, "tx bitrate:") && len(fields) >= 3:
+			info.TxRateMbps, _ = strconv.ParseFloat(fields[2], 64)
+		}
+	}
+	if info.SSID == "" { return nil }
+
+	// O tipo de autenticação não vem do nl80211; o NetworkManager informa quando disponível
+	if _, err := exec.LookPath("nmcli"); err == nil {
+		nmOut, err := runCommandHidden("nmcli", "-t", "-f", "ACTIVE,BSSID,SECURITY", "dev", "wifi")
+		if err == nil {
+			for _, line := range strings.Split(nmOut, "\n") {
+				// BSSID vem com ":" escapado como "\:"
+				line = strings.ReplaceAll(line, "\\:", "-")
+				parts := strings.Split(line, ":")
+				if len(parts) >= 3 && (parts[0] == "yes" || parts[0] == "sim") {
+					info.Authentication = parts[2]
+				}
+			}
+		}
+	}
+	return info
+}
+
+func channelFromFrequency(freq int) (int, string) {
+	switch {
+	case freq == 2484:
+		return 14, "2.4 GHz"
+	case freq >= 2412 && freq < 2484:
+		return (freq - 2407) / 5, "2.4 GHz"
+	case freq >= 5955 && freq <= 7115:
+		return (freq - 5950) / 5, "6 GHz"
+	case freq >= 5000 && freq < 5955:
+		return (freq - 5000) / 5, "5 GHz"
+	}
+	return 0, ""
+}
+
+func bandFromChannel(channel int) string {
+	switch {
+	case channel >= 1 && channel <= 14:
+		return "2.4 GHz"
+	case channel >= 32 && channel <= 177:
+		return "5 GHz"
+	}
+	return ""
+}
+
+func rssiToPercent(rssi int) int {
+	if rssi >= -50 { return 100 }
+	if rssi <= -100 { return 0 }
+	return 2 * (rssi + 100)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const netshInterfacesPtBR = `
+Há 1 interface no sistema:
+
+    Nome                   : Wi-Fi
+    Descrição            : Intel(R) Wi-Fi 6 AX201 160MHz
+    GUID                   : 2f0e6d1a-8c3b-4f2e-9a51-7d0c1b2e3f40
+    Endereço físico       : 14:f6:d8:aa:bb:cc
+    Tipo de interface      : Principal
+    Estado                 : conectado
+    SSID                   : RedeFacil-Loja12
+    AP BSSID               : 60:22:32:11:22:33
+    Banda                  : 5 GHz
+    Canal                  : 44
+    Tipo de rede           : Infraestrutura
+    Tipo de rádio          : 802.11ac
+    Autenticação           : WPA2-Personal
+    Codificação            : CCMP
+    Modo de conexão        : Conexão automática
+    Taxa de recepção (Mbps)  : 866,7
+    Taxa de transmissão (Mbps) : 650
+    Sinal                  : 92%
+    Perfil                 : RedeFacil-Loja12
+
+    Status da rede hospedada  : Não disponível
+`
+
+const netshInterfacesEnUS = `
+There is 1 interface on the system:
+
+    Name                   : Wi-Fi
+    Description            : Realtek RTL8821CE 802.11ac PCIe Adapter
+    GUID                   : 5b1c2d3e-4f50-6172-8394-a5b6c7d8e9f0
+    Physical address       : 98:22:ef:01:02:03
+    State                  : connected
+    SSID                   : Matriz
+    BSSID                  : a0:b1:c2:d3:e4:f5
+    Network type           : Infrastructure
+    Radio type             : 802.11n
+    Authentication         : WPA2-Enterprise
+    Cipher                 : CCMP
+    Connection mode        : Profile
+    Channel                : 6
+    Receive rate (Mbps)    : 144.4
+    Transmit rate (Mbps)   : 72
+    Signal                 : 60%
+    Profile                : Matriz
+
+    Hosted network status  : Not available
+`
+
+func TestParseNetshWlanInterfaces(t *testing.T) {
+	ptBR, err := charmap.CodePage850.NewEncoder().String(strings.ReplaceAll(netshInterfacesPtBR, "\n", "\r\n"))
+	if err != nil { t.Fatal(err) }
+
+	cases := []struct {
+		name     string
+		output   string
+		codePage uint32
+		want     *WiFiInfo
+	}{
+		{
+			"pt-BR em CP850", ptBR, 850,
+			&WiFiInfo{InterfaceName: "Wi-Fi", SSID: "RedeFacil-Loja12", BSSID: "60:22:32:11:22:33", SignalPercent: 92, RSSIdBm: -54, Channel: 44, Band: "5 GHz", RadioType: "802.11ac", RxRateMbps: 866.7, TxRateMbps: 650, Authentication: "WPA2-Personal"},
+		},
+		{
+			"en-US em CP437", netshInterfacesEnUS, 437,
+			&WiFiInfo{InterfaceName: "Wi-Fi", SSID: "Matriz", BSSID: "A0:B1:C2:D3:E4:F5", SignalPercent: 60, RSSIdBm: -70, Channel: 6, Band: "2.4 GHz", RadioType: "802.11n", RxRateMbps: 144.4, TxRateMbps: 72, Authentication: "WPA2-Enterprise"},
+		},
+		{
+			"pt-BR já em UTF-8", netshInterfacesPtBR, 65001,
+			&WiFiInfo{InterfaceName: "Wi-Fi", SSID: "RedeFacil-Loja12", BSSID: "60:22:32:11:22:33", SignalPercent: 92, RSSIdBm: -54, Channel: 44, Band: "5 GHz", RadioType: "802.11ac", RxRateMbps: 866.7, TxRateMbps: 650, Authentication: "WPA2-Personal"},
+		},
+		{"desconectado", "\r\n    Name                   : Wi-Fi\r\n    State                  : disconnected\r\n", 437, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := parseNetshWlanInterfaces(decodeOEMOutput(c.output, c.codePage))
+			if !reflect.DeepEqual(got, c.want) { t.Errorf("parseNetshWlanInterfaces() = %+v\nesperado %+v", got, c.want) }
+		})
+	}
+}
+
+func TestDecodeOEMOutput(t *testing.T) {
+	raw, _ := charmap.CodePage850.NewEncoder().String("Autenticação")
+	if got := decodeOEMOutput(raw, 850); got != "Autenticação" { t.Errorf("CP850 = %q", got) }
+	if got := decodeOEMOutput(raw, 0); got != "Autenticação" { t.Errorf("code page desconhecido = %q", got) }
+	if got := decodeOEMOutput("Autenticação", 850); got != "Autenticação" { t.Errorf("UTF-8 decodificado de novo: %q", got) }
+}
+
+func TestChannelFromFrequency(t *testing.T) {
+	cases := []struct {
+		freq    int
+		channel int
+		band    string
+	}{
+		{2412, 1, "2.4 GHz"},
+		{2484, 14, "2.4 GHz"},
+		{5220, 44, "5 GHz"},
+		{5975, 5, "6 GHz"},
+		{900, 0, ""},
+	}
+	for _, c := range cases {
+		channel, band := channelFromFrequency(c.freq)
+		if channel != c.channel || band != c.band { t.Errorf("channelFromFrequency(%d) = %d, %q; esperado %d, %q", c.freq, channel, band, c.channel, c.band) }
+	}
+}
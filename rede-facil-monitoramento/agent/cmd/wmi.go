@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const WMI_TIMEOUT = 10 * time.Second
+const WMI_DEFAULT_NAMESPACE = `root\CIMV2`
+
+var errWMIUnavailable = errors.New("WMI indisponível neste sistema")
+
+// Camada de consulta WMI/CIM. No Windows usa COM nativo (wmi_windows.go);
+// nos demais sistemas retorna errWMIUnavailable. Os testes trocam wmiClient por um fakeWMI (wmi_test.go).
+type WMIQuerier interface {
+	Query(query string, dst interface{}, namespace string) error
+}
+
+var wmiClient WMIQuerier = newNativeWMI()
+
+// --- Classes WMI (o nome do tipo é o nome da classe; os campos, as propriedades consultadas) ---
+
+// Para classes com nome genérico demais para um tipo do pacote (ex: "Sensor"), o tipo informa a classe
+type wmiClassNamer interface {
+	WMIClassName() string
+}
+
+type Win32_BIOS struct {
+	Manufacturer      string
+	SerialNumber      string
+	SMBIOSBIOSVersion string
+}
+
+type Win32_BaseBoard struct {
+	Manufacturer string
+	Product      string
+	Version      string
+	SerialNumber string
+}
+
+type Win32_ComputerSystemProduct struct {
+	Name   string
+	Vendor string
+	UUID   string
+}
+
+type Win32_VideoController struct {
+	Name          string
+	AdapterRAM    uint32
+	DriverVersion string
+}
+
+type Win32_PhysicalMemory struct {
+	BankLabel     string
+	DeviceLocator string
+	Capacity      uint64
+	Speed         uint32
+	Manufacturer  string
+	PartNumber    string
+}
+
+type Win32_PhysicalMemoryArray struct {
+	MemoryDevices uint32
+}
+
+type Win32_SystemEnclosure struct {
+	ChassisTypes []uint16
+	SerialNumber string
+}
+
+type Win32_NetworkAdapterConfiguration struct {
+	MACAddress           string
+	IPEnabled            bool
+	DefaultIPGateway     []string
+	DNSServerSearchOrder []string
+	DHCPEnabled          bool
+	DHCPServer           string
+	DHCPLeaseObtained    time.Time
+	DHCPLeaseExpires     time.Time
+}
+
+// Monta "SELECT campos FROM Classe [WHERE ...]" a partir do tipo de elemento de dst (*[]T)
+func buildWMIQuery(dst interface{}, where string) string {
+	t := reflect.TypeOf(dst).Elem().Elem()
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() { fields = append(fields, t.Field(i).Name) }
+	}
+	class := t.Name()
+	if namer, ok := reflect.Zero(t).Interface().(wmiClassNamer); ok { class = namer.WMIClassName() }
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(fields, ", "), class)
+	if where != "" { query += " WHERE " + where }
+	return query
+}
+
+// Consulta tipada com timeout: queryWMI(&[]Win32_BIOS{}, "")
+func queryWMI(dst interface{}, where string) error {
+	return queryWMINamespace(dst, where, WMI_DEFAULT_NAMESPACE)
+}
+
+func queryWMINamespace(dst interface{}, where string, namespace string) error {
+	query := buildWMIQuery(dst, where)
+	done := make(chan error, 1)
+
+	// O resultado vai para uma cópia para não escrever em dst depois de um timeout
+	tmp := reflect.New(reflect.TypeOf(dst).Elem())
+	go func() {
+		defer func() {
+			if r := recover(); r != nil { done <- fmt.Errorf("pânico na consulta WMI: %v", r) }
+		}()
+		done <- wmiClient.Query(query, tmp.Interface(), namespace)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil { return err }
+		reflect.ValueOf(dst).Elem().Set(tmp.Elem())
+		return nil
+	case <-time.After(WMI_TIMEOUT):
+		return fmt.Errorf("timeout na consulta WMI: %s", query)
+	}
+}
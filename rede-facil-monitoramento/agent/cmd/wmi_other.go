@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+type nativeWMI struct{}
+
+func newNativeWMI() WMIQuerier { return nativeWMI{} }
+
+func (nativeWMI) Query(query string, dst interface{}, namespace string) error {
+	return errWMIUnavailable
+}
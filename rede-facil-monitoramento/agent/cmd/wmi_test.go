@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Implementação falsa, sem COM e sem parsing de texto: devolve objetos prontos por classe.
+// Os valores de Results são slices dos próprios tipos Win32_* (ou mapas equivalentes).
+type fakeWMI struct {
+	Results map[string]interface{}
+	Errors  map[string]error
+	Queries []string
+}
+
+func (f *fakeWMI) Query(query string, dst interface{}, namespace string) error {
+	f.Queries = append(f.Queries, query)
+	class := wmiClassFromQuery(query)
+	if err, ok := f.Errors[class]; ok { return err }
+	result, ok := f.Results[class]
+	if !ok { return nil }
+	data, err := json.Marshal(result)
+	if err != nil { return err }
+	return json.Unmarshal(data, dst)
+}
+
+func wmiClassFromQuery(query string) string {
+	fields := strings.Fields(query)
+	for i, f := range fields {
+		if strings.EqualFold(f, "FROM") && i+1 < len(fields) { return fields[i+1] }
+	}
+	return ""
+}
+
+func useFakeWMI(t *testing.T, fake *fakeWMI) {
+	t.Helper()
+	previous := wmiClient
+	wmiClient = fake
+	t.Cleanup(func() { wmiClient = previous })
+}
+
+type Win32_TestClass struct {
+	Name     string
+	Size     uint64
+	internal string
+}
+
+func TestBuildWMIQuery(t *testing.T) {
+	cases := []struct {
+		dst   interface{}
+		where string
+		want  string
+	}{
+		{&[]Win32_BIOS{}, "", "SELECT Manufacturer, SerialNumber, SMBIOSBIOSVersion FROM Win32_BIOS"},
+		{&[]Win32_PhysicalMemoryArray{}, "", "SELECT MemoryDevices FROM Win32_PhysicalMemoryArray"},
+		{&[]Win32_NetworkAdapterConfiguration{}, "IPEnabled = TRUE", "SELECT MACAddress, IPEnabled, DefaultIPGateway, DNSServerSearchOrder, DHCPEnabled, DHCPServer, DHCPLeaseObtained, DHCPLeaseExpires FROM Win32_NetworkAdapterConfiguration WHERE IPEnabled = TRUE"},
+		{&[]Win32_TestClass{}, "Size > 0", "SELECT Name, Size FROM Win32_TestClass WHERE Size > 0"},
+		{&[]HardwareMonitorSensor{}, "SensorType = 'Temperature'", "SELECT Identifier, Name, Parent, SensorType, Value, Max FROM Sensor WHERE SensorType = 'Temperature'"},
+	}
+	for _, c := range cases {
+		if got := buildWMIQuery(c.dst, c.where); got != c.want {
+			t.Errorf("buildWMIQuery(%T, %q) = %q, esperado %q", c.dst, c.where, got, c.want)
+		}
+	}
+}
+
+func TestQueryWMIKeepsDestinationOnError(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Errors: map[string]error{"Win32_BIOS": errors.New("acesso negado")}})
+	bios := []Win32_BIOS{{SerialNumber: "anterior"}}
+	if err := queryWMI(&bios, ""); err == nil { t.Fatal("esperado erro da consulta") }
+	if len(bios) != 1 || bios[0].SerialNumber != "anterior" { t.Errorf("destino alterado após erro: %+v", bios) }
+}
+
+func TestGetMachineType(t *testing.T) {
+	cases := []struct {
+		name    string
+		chassis []Win32_SystemEnclosure
+		err     error
+		want    string
+	}{
+		{"notebook", []Win32_SystemEnclosure{{ChassisTypes: []uint16{10}}}, nil, "Notebook/Laptop"},
+		{"convertible", []Win32_SystemEnclosure{{ChassisTypes: []uint16{31}}}, nil, "Notebook/Laptop"},
+		{"desktop", []Win32_SystemEnclosure{{ChassisTypes: []uint16{3}}}, nil, "Desktop"},
+		{"primeiro tipo conhecido", []Win32_SystemEnclosure{{ChassisTypes: []uint16{1, 2, 9}}}, nil, "Notebook/Laptop"},
+		{"tipo desconhecido", []Win32_SystemEnclosure{{ChassisTypes: []uint16{1}}}, nil, "Desktop/Genérico"},
+		{"sem gabinete", nil, nil, "Desktop/Genérico"},
+		{"falha WMI", nil, errors.New("RPC indisponível"), "Indefinido"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := &fakeWMI{Results: map[string]interface{}{}, Errors: map[string]error{}}
+			if c.chassis != nil { fake.Results["Win32_SystemEnclosure"] = c.chassis }
+			if c.err != nil { fake.Errors["Win32_SystemEnclosure"] = c.err }
+			useFakeWMI(t, fake)
+			if got := getMachineType(); got != c.want { t.Errorf("getMachineType() = %q, esperado %q", got, c.want) }
+		})
+	}
+}
+
+func TestGetGPUInfo(t *testing.T) {
+	cases := []struct {
+		name        string
+		controllers []Win32_VideoController
+		err         error
+		wantModel   string
+		wantVRAM    int
+	}{
+		{"placa dedicada vence a básica", []Win32_VideoController{{Name: "Microsoft Basic Display Adapter"}, {Name: "NVIDIA GeForce GT 730", AdapterRAM: 2 << 30}}, nil, "NVIDIA GeForce GT 730", 2048},
+		{"integrada única", []Win32_VideoController{{Name: "Intel(R) UHD Graphics 620", AdapterRAM: 1 << 30}}, nil, "Intel(R) UHD Graphics 620", 1024},
+		{"nome vazio", []Win32_VideoController{{Name: "  "}}, nil, "N/A", 0},
+		{"sem controladora", nil, nil, "N/A", 0},
+		{"falha WMI", nil, errors.New("timeout"), "N/A", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := &fakeWMI{Results: map[string]interface{}{}, Errors: map[string]error{}}
+			if c.controllers != nil { fake.Results["Win32_VideoController"] = c.controllers }
+			if c.err != nil { fake.Errors["Win32_VideoController"] = c.err }
+			useFakeWMI(t, fake)
+			model, vram := getGPUInfo()
+			if model != c.wantModel || vram != c.wantVRAM { t.Errorf("getGPUInfo() = %q, %d; esperado %q, %d", model, vram, c.wantModel, c.wantVRAM) }
+		})
+	}
+}
+
+func TestGetMemorySlotsInfo(t *testing.T) {
+	cases := []struct {
+		name      string
+		results   map[string]interface{}
+		errs      map[string]error
+		wantTotal int
+		wantUsed  int
+	}{
+		{
+			"dois arrays",
+			map[string]interface{}{
+				"Win32_PhysicalMemoryArray": []Win32_PhysicalMemoryArray{{MemoryDevices: 2}, {MemoryDevices: 2}},
+				"Win32_PhysicalMemory":      []Win32_PhysicalMemory{{DeviceLocator: "DIMM1"}, {DeviceLocator: "DIMM3"}, {DeviceLocator: "DIMM4"}},
+			},
+			nil, 4, 3,
+		},
+		{
+			"falha nos slots mantém os módulos",
+			map[string]interface{}{"Win32_PhysicalMemory": []Win32_PhysicalMemory{{DeviceLocator: "ChannelA-DIMM0"}}},
+			map[string]error{"Win32_PhysicalMemoryArray": errors.New("acesso negado")},
+			0, 1,
+		},
+		{"sem dados", map[string]interface{}{}, nil, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			useFakeWMI(t, &fakeWMI{Results: c.results, Errors: c.errs})
+			total, used := getMemorySlotsInfo()
+			if total != c.wantTotal || used != c.wantUsed { t.Errorf("getMemorySlotsInfo() = %d, %d; esperado %d, %d", total, used, c.wantTotal, c.wantUsed) }
+		})
+	}
+}
+
+func TestEnrichWindowsNetworkConfig(t *testing.T) {
+	obtained := time.Date(2026, 5, 4, 8, 30, 0, 0, time.UTC)
+	expires := obtained.Add(24 * time.Hour)
+	fake := &fakeWMI{Results: map[string]interface{}{
+		"Win32_NetworkAdapterConfiguration": []Win32_NetworkAdapterConfiguration{
+			{MACAddress: "00:1A:2B:3C:4D:5E", IPEnabled: true, DefaultIPGateway: []string{"192.168.0.1"}, DNSServerSearchOrder: []string{"192.168.0.1", "8.8.8.8"}, DHCPEnabled: true, DHCPServer: "192.168.0.1", DHCPLeaseObtained: obtained, DHCPLeaseExpires: expires},
+			{MACAddress: "00:1A:2B:3C:4D:5F", IPEnabled: true, DefaultIPGateway: []string{"10.0.0.1"}, DNSServerSearchOrder: []string{"10.0.0.2"}, DHCPServer: "10.0.0.99"},
+		},
+	}}
+	useFakeWMI(t, fake)
+
+	nics := []NetworkInterface{
+		{InterfaceName: "Ethernet", MACAddress: "00:1a:2b:3c:4d:5e"},
+		{InterfaceName: "Ethernet 2", MACAddress: "00-1A-2B-3C-4D-5F"},
+		{InterfaceName: "Wi-Fi", MACAddress: "aa:bb:cc:dd:ee:ff"},
+	}
+	enrichWindowsNetworkConfig(nics)
+
+	want := []NetworkInterface{
+		{InterfaceName: "Ethernet", MACAddress: "00:1a:2b:3c:4d:5e", Gateways: []string{"192.168.0.1"}, DNSServers: []string{"192.168.0.1", "8.8.8.8"}, DHCPEnabled: true, DHCPServer: "192.168.0.1", DHCPLeaseObtained: "2026-05-04T08:30:00", DHCPLeaseExpires: "2026-05-05T08:30:00"},
+		// IP fixo: o servidor DHCP antigo que o Windows guarda não é reportado
+		{InterfaceName: "Ethernet 2", MACAddress: "00-1A-2B-3C-4D-5F", Gateways: []string{"10.0.0.1"}, DNSServers: []string{"10.0.0.2"}},
+		{InterfaceName: "Wi-Fi", MACAddress: "aa:bb:cc:dd:ee:ff"},
+	}
+	if !reflect.DeepEqual(nics, want) { t.Errorf("interfaces = %+v\nesperado %+v", nics, want) }
+	if len(fake.Queries) != 1 || !strings.HasSuffix(fake.Queries[0], "WHERE IPEnabled = TRUE") { t.Errorf("consultas = %q", fake.Queries) }
+}
+
+func TestEnrichWindowsNetworkConfigWMIFailure(t *testing.T) {
+	useFakeWMI(t, &fakeWMI{Errors: map[string]error{"Win32_NetworkAdapterConfiguration": errors.New("acesso negado")}})
+	nics := []NetworkInterface{{InterfaceName: "Ethernet", MACAddress: "00:1a:2b:3c:4d:5e"}}
+	enrichWindowsNetworkConfig(nics)
+	if nics[0].Gateways != nil || nics[0].DHCPEnabled { t.Errorf("interface alterada sem dados do WMI: %+v", nics[0]) }
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import "github.com/yusufpapurcu/wmi"
+
+type nativeWMI struct{}
+
+func newNativeWMI() WMIQuerier { return nativeWMI{} }
+
+func (nativeWMI) Query(query string, dst interface{}, namespace string) error {
+	return wmi.QueryNamespace(query, dst, namespace)
+}
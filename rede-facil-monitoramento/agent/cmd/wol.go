@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const WOL_DEFAULT_PORT = 9
+const WOL_DEFAULT_WAIT = 180
+const WOL_REPEAT = 3
+
+// Payload do comando wake_peers
+type WakeRequest struct {
+	MACs        []string          `json:"macs"`
+	Port        int               `json:"port"`
+	WaitSeconds int               `json:"wait_seconds"`
+	Hosts       map[string]string `json:"hosts"` // MAC -> último IP conhecido pelo backend (opcional)
+}
+
+type WakeTargetResult struct {
+	MACAddress  string   `json:"mac_address"`
+	PacketsSent int      `json:"packets_sent"`
+	Interfaces  []string `json:"interfaces"`
+	BackOnline  bool     `json:"back_online"`
+	DetectedBy  string   `json:"detected_by,omitempty"`
+	IPAddress   string   `json:"ip_address"`
+	Error       string   `json:"error,omitempty"`
+}
+
+type WakeResult struct {
+	WaitedSeconds int                `json:"waited_seconds"`
+	Targets       []WakeTargetResult `json:"targets"`
+}
+
+type ArpEntry struct {
+	IPAddress  string `json:"ip_address"`
+	MACAddress string `json:"mac_address"`
+}
+
+var macPattern = regexp.MustCompile(`(?i)([0-9a-f]{2}[:-]){5}[0-9a-f]{2}`)
+var ipv4Pattern = regexp.MustCompile(`\b(\d{1,3}\.){3}\d{1,3}\b`)
+
+func handleWakePeers(payload string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Erro recuperado no wake_peers: %v", r)
+			sendCommandResult("", fmt.Sprintf("Erro interno: %v", r))
+		}
+	}()
+
+	var req WakeRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		sendCommandResult("", fmt.Sprintf("Payload inválido: %v", err))
+		return
+	}
+	if len(req.MACs) == 0 {
+		sendCommandResult("", "Nenhum MAC informado")
+		return
+	}
+	if req.Port <= 0 { req.Port = WOL_DEFAULT_PORT }
+	if req.WaitSeconds <= 0 { req.WaitSeconds = WOL_DEFAULT_WAIT }
+
+	broadcasts := localBroadcastTargets()
+	if len(broadcasts) == 0 {
+		sendCommandResult("", "Nenhuma interface ativa com IPv4 para enviar o magic packet")
+		return
+	}
+
+	hosts := map[string]string{}
+	for mac, ip := range req.Hosts {
+		if hwAddr, err := net.ParseMAC(strings.TrimSpace(mac)); err == nil { hosts[strings.ToUpper(hwAddr.String())] = ip }
+	}
+	// Entradas que já estavam no cache antes do envio não provam que o alvo acordou
+	arpBefore := map[string]string{}
+	for _, entry := range readArpTable() { arpBefore[entry.MACAddress] = entry.IPAddress }
+
+	var result WakeResult
+	for _, rawMAC := range req.MACs {
+		target := WakeTargetResult{MACAddress: rawMAC}
+		hwAddr, err := net.ParseMAC(strings.TrimSpace(rawMAC))
+		if err != nil {
+			target.Error = fmt.Sprintf("MAC inválido: %v", err)
+			result.Targets = append(result.Targets, target)
+			continue
+		}
+		target.MACAddress = strings.ToUpper(hwAddr.String())
+
+		packet := buildMagicPacket(hwAddr)
+		for _, b := range broadcasts {
+			sent := 0
+			for i := 0; i < WOL_REPEAT; i++ {
+				if err := sendMagicPacket(packet, b.localIP, b.broadcast, req.Port); err == nil { sent++ }
+			}
+			if sent > 0 {
+				target.PacketsSent += sent
+				target.Interfaces = append(target.Interfaces, b.name)
+			}
+		}
+		if target.PacketsSent == 0 { target.Error = "Falha ao enviar em todas as interfaces" }
+		result.Targets = append(result.Targets, target)
+	}
+	log.Printf("⏰ Magic packets enviados para %d alvo(s); aguardando %ds", len(result.Targets), req.WaitSeconds)
+
+	time.Sleep(time.Duration(req.WaitSeconds) * time.Second)
+	result.WaitedSeconds = req.WaitSeconds
+
+	for i := range result.Targets {
+		if result.Targets[i].Error != "" { continue }
+		mac := result.Targets[i].MACAddress
+		ip := hosts[mac]
+		if ip == "" { ip = arpBefore[mac] }
+		if ip != "" { result.Targets[i].IPAddress = ip }
+		result.Targets[i].BackOnline, result.Targets[i].DetectedBy = probeWakeTarget(mac, ip)
+	}
+
+	// Alvos sem IP conhecido: só contam entradas ARP novas, criadas depois do envio
+	arpAfter := readArpTable()
+	for i := range result.Targets {
+		t := &result.Targets[i]
+		if t.Error != "" || t.BackOnline || t.IPAddress != "" { continue }
+		for _, entry := range arpAfter {
+			if entry.MACAddress != t.MACAddress { continue }
+			if _, stale := arpBefore[t.MACAddress]; stale { continue }
+			t.BackOnline, t.DetectedBy, t.IPAddress = true, "arp", entry.IPAddress
+		}
+	}
+
+	jsonResult, _ := json.Marshal(result)
+	sendCommandResult(string(jsonResult), "")
+}
+
+// Confirma que o alvo está de pé no IP conhecido: apaga a entrada ARP antiga e pinga. Se o ICMP
+// for bloqueado pelo firewall, a resposta ao ARP do próprio ping recria a entrada com o MAC do alvo.
+func probeWakeTarget(mac string, ip string) (bool, string) {
+	if ip == "" { return false, "" }
+	cleared := deleteArpEntry(ip)
+	if measureLatency(ip, 2).Received > 0 {
+		// Outro equipamento pode ter assumido o IP; só vale se o MAC confere
+		for _, entry := range readArpTable() {
+			if entry.IPAddress == ip && entry.MACAddress != mac { return false, "" }
+		}
+		return true, "ping"
+	}
+	// Sem ter apagado a entrada ela pode ser antiga, então sem ping não há como confirmar
+	if !cleared { return false, "" }
+	for _, entry := range readArpTable() {
+		if entry.IPAddress == ip && entry.MACAddress == mac { return true, "arp" }
+	}
+	return false, ""
+}
+
+// Remove a entrada do cache ARP (exige privilégio de administrador/root)
+func deleteArpEntry(ip string) bool {
+	var err error
+	if runtime.GOOS == "windows" {
+		_, err = runCommandHidden("arp", "-d", ip)
+	} else {
+		_, err = runCommandHidden("ip", "neigh", "flush", "to", ip)
+	}
+	return err == nil
+}
+
+func buildMagicPacket(mac net.HardwareAddr) []byte {
+	packet := make([]byte, 0, 102)
+	for i := 0; i < 6; i++ { packet = append(packet, 0xFF) }
+	for i := 0; i < 16; i++ { packet = append(packet, mac...) }
+	return packet
+}
+
+type broadcastTarget struct {
+	name      string
+	localIP   net.IP
+	broadcast net.IP
+}
+
+// Endereços de broadcast IPv4 de todas as interfaces ativas reportadas em collectNetworkInterfaces
+func localBroadcastTargets() []broadcastTarget {
+	var targets []broadcastTarget
+	for _, nic := range collectNetworkInterfaces() {
+		if !nic.IsUp { continue }
+		iface, err := net.InterfaceByName(nic.InterfaceName)
+		if err != nil { continue }
+		addrs, err := iface.Addrs()
+		if err != nil { continue }
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok { continue }
+			ip4 := ipNet.IP.To4()
+			if ip4 == nil || ip4.IsLoopback() || ip4.IsLinkLocalUnicast() { continue }
+			mask := ipNet.Mask
+			if len(mask) == net.IPv6len { mask = mask[12:] }
+			bcast := make(net.IP, 4)
+			for i := 0; i < 4; i++ { bcast[i] = ip4[i] | ^mask[i] }
+			targets = append(targets, broadcastTarget{name: nic.InterfaceName, localIP: ip4, broadcast: bcast})
+		}
+	}
+	return targets
+}
+
+func sendMagicPacket(packet []byte, localIP net.IP, broadcast net.IP, port int) error {
+	conn, err := net.DialUDP("udp4", &net.UDPAddr{IP: localIP}, &net.UDPAddr{IP: broadcast, Port: port})
+	if err != nil { return err }
+	defer conn.Close()
+	_, err = conn.Write(packet)
+	return err
+}
+
+// Lê a tabela ARP do sistema (arp -a no Windows, /proc/net/arp no Linux)
+func readArpTable() []ArpEntry {
+	var output string
+	if runtime.GOOS == "windows" {
+		out, err := runCommandHidden("arp", "-a")
+		if err != nil { return nil }
+		output = out
+	} else {
+		out, err := os.ReadFile("/proc/net/arp")
+		if err != nil { return nil }
+		output = string(out)
+	}
+	return parseArpTable(output)
+}
+
+func parseArpTable(output string) []ArpEntry {
+	var entries []ArpEntry
+	for _, line := range strings.Split(output, "\n") {
+		ip := ipv4Pattern.FindString(line)
+		mac := macPattern.FindString(line)
+		if ip == "" || mac == "" { continue }
+		hwAddr, err := net.ParseMAC(strings.ReplaceAll(mac, "-", ":"))
+		if err != nil { continue }
+		normalized := strings.ToUpper(hwAddr.String())
+		if normalized == "FF:FF:FF:FF:FF:FF" || normalized == "00:00:00:00:00:00" { continue }
+		entries = append(entries, ArpEntry{IPAddress: ip, MACAddress: normalized})
+	}
+	return entries
+}
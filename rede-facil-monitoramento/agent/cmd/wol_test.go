@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestBuildMagicPacket(t *testing.T) {
+	mac, _ := net.ParseMAC("00:1a:2b:3c:4d:5e")
+	packet := buildMagicPacket(mac)
+	if len(packet) != 102 { t.Fatalf("tamanho = %d, esperado 102", len(packet)) }
+	if !bytes.Equal(packet[:6], []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}) { t.Errorf("cabeçalho = % X", packet[:6]) }
+	for i := 0; i < 16; i++ {
+		if !bytes.Equal(packet[6+i*6:12+i*6], mac) { t.Fatalf("repetição %d = % X", i, packet[6+i*6:12+i*6]) }
+	}
+}
+
+func TestParseArpTable(t *testing.T) {
+	cases := []struct {
+		name   string
+		output string
+		want   []ArpEntry
+	}{
+		{
+			"windows pt-BR",
+			"\r\nInterface: 192.168.0.15 --- 0xb\r\n  Endereço IP           Endereço físico       Tipo\r\n  192.168.0.1           a4-2b-b0-11-22-33     dinâmico  \r\n  192.168.0.40          00-1a-2b-3c-4d-5e     dinâmico  \r\n  192.168.0.255         ff-ff-ff-ff-ff-ff     estático  \r\n  224.0.0.22            01-00-5e-00-00-16     estático  \r\n",
+			[]ArpEntry{{"192.168.0.1", "A4:2B:B0:11:22:33"}, {"192.168.0.40", "00:1A:2B:3C:4D:5E"}, {"224.0.0.22", "01:00:5E:00:00:16"}},
+		},
+		{
+			"windows en-US",
+			"\r\nInterface: 10.0.0.5 --- 0x4\r\n  Internet Address      Physical Address      Type\r\n  10.0.0.1              00-50-56-c0-00-08     dynamic   \r\n",
+			[]ArpEntry{{"10.0.0.1", "00:50:56:C0:00:08"}},
+		},
+		{
+			"linux /proc/net/arp",
+			"IP address       HW type     Flags       HW address            Mask     Device\n192.168.1.1      0x1         0x2         a4:2b:b0:11:22:33     *        eth0\n192.168.1.77     0x1         0x0         00:00:00:00:00:00     *        eth0\n",
+			[]ArpEntry{{"192.168.1.1", "A4:2B:B0:11:22:33"}},
+		},
+		{"vazio", "", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseArpTable(c.output); !reflect.DeepEqual(got, c.want) { t.Errorf("parseArpTable() = %+v, esperado %+v", got, c.want) }
+		})
+	}
+}
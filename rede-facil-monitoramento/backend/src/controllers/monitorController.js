@@ -1,6 +1,8 @@
+const crypto = require('crypto');
 const monitorServices = require('../services/monitorServices');
 const socketHandler = require('../socket/socketHandler'); 
 const commandService = require('../services/commandService');
+const agentReportService = require('../services/agentReportService');
 
 exports.registerMachine = async (req, res) => {
     try {
@@ -45,11 +47,15 @@ exports.processTelemetry = async (req, res) => {
         const pendingData = commandService.getCommand(data.machine_uuid);
         let commandToSend = null;
         let payloadToSend = null;
+        let commandId = null;
+        let condition = null;
         
         if (pendingData) {
             if (typeof pendingData === 'object') {
                 commandToSend = pendingData.command;
                 payloadToSend = pendingData.payload || null;
+                commandId = pendingData.command_id || null;
+                condition = pendingData.condition || null;
             } else {
                 commandToSend = pendingData;
             }
@@ -58,7 +64,9 @@ exports.processTelemetry = async (req, res) => {
         res.status(200).json({
             message: 'Telemetria processada.',
             command: commandToSend || null,
-            payload: payloadToSend || null 
+            payload: payloadToSend || null,
+            command_id: commandId,
+            condition: condition
         });
 
     } catch (error) {
@@ -107,9 +115,38 @@ exports.getTelemetryHistory = async (req, res) => {
     }
 };
 
+exports.getMachineReport = async (req, res) => {
+    const { uuid, type } = req.params;
+
+    if (!agentReportService.REPORT_TYPES.includes(type)) {
+        return res.status(400).json({ message: 'Tipo de relatório desconhecido.' });
+    }
+
+    try {
+        const report = await agentReportService.getReport(uuid, type);
+
+        if (!report) {
+            return res.status(404).json({ message: 'Nenhum relatório recebido desta máquina.' });
+        }
+
+        res.json(report);
+    } catch (error) {
+        res.status(500).json({ message: 'Erro ao buscar relatório.', error: error.message });
+    }
+};
+
+exports.listDiscoveredDevices = async (req, res) => {
+    try {
+        const devices = await agentReportService.listDiscoveredDevices(req.query.subnet);
+        res.json(devices);
+    } catch (error) {
+        res.status(500).json({ message: 'Erro ao buscar dispositivos descobertos.', error: error.message });
+    }
+};
+
 exports.sendCommand = async (req, res) => {
     const { uuid } = req.params;
-    const { command, payload } = req.body; 
+    const { command, payload, condition } = req.body; 
 
     try {
        if (command === 'enable_auto_shutdown' || command === 'disable_auto_shutdown') {
@@ -126,8 +163,10 @@ exports.sendCommand = async (req, res) => {
     });
 }
 
-        commandService.addCommand(uuid, { command, payload });
-        res.json({ message: `Comando enviado com sucesso!` });
+        // O id acompanha o comando até o agente, que o usa ao reportar o estado da execução adiada
+        const commandId = crypto.randomUUID();
+        commandService.addCommand(uuid, { command, payload, command_id: commandId, condition: condition || null });
+        res.json({ message: `Comando enviado com sucesso!`, command_id: commandId });
     } catch (error) {
         res.status(500).json({ message: 'Erro ao processar comando.' });
     }
@@ -165,6 +204,33 @@ exports.receiveCommandResult = async (req, res) => {
     }
 };
 
+// Estado de um comando adiado pelo agente (pendente, executando, concluído, expirado)
+exports.receiveCommandState = async (req, res) => {
+    try {
+        const { uuid } = req.params;
+        const { command_id, command, mode, state, detail } = req.body;
+
+        if (!command_id || !state) {
+            return res.status(400).json({ message: 'command_id e state são obrigatórios.' });
+        }
+
+        const io = socketHandler.getIO();
+
+        io.emit('command_state', {
+            machine_uuid: uuid,
+            command_id,
+            command: command || null,
+            mode: mode || null,
+            state,
+            detail: detail || null
+        });
+
+        res.status(200).json({ message: 'Estado do comando recebido' });
+    } catch (err) {
+        console.error("Erro ao processar estado do comando:", err);
+        res.status(500).json({ message: 'Erro interno' });
+    }
+};
 
 exports.updateSector = async (req, res) => {
     const { uuid } = req.params;
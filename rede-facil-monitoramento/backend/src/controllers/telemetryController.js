@@ -1,8 +1,13 @@
 const monitorService = require('../services/monitorServices');
 const socketHandler = require('../socket/socketHandler'); 
 const commandService = require('../services/commandService');
+const agentReportService = require('../services/agentReportService');
 const { db } = require('../config/db'); 
 
+// Alvo exibido no gráfico de latência e tempo que o histórico de rede fica guardado
+const NETWORK_CHART_TARGET = '8.8.8.8';
+const NETWORK_LOG_RETENTION_MINUTES = 60;
+
 exports.receiveTelemetry = async (req, res) => {
     try {
         const data = req.body;
@@ -30,7 +35,9 @@ exports.receiveTelemetry = async (req, res) => {
         res.status(200).json({ 
             message: 'ok', 
             command: pendingCommand ? pendingCommand.command : null,
-            payload: pendingCommand ? pendingCommand.payload : null
+            payload: pendingCommand ? pendingCommand.payload : null,
+            command_id: pendingCommand ? pendingCommand.command_id || null : null,
+            condition: pendingCommand ? pendingCommand.condition || null : null
         });
 
     } catch (error) {
@@ -40,37 +47,39 @@ exports.receiveTelemetry = async (req, res) => {
 };
 
 exports.storeNetworkLog = async (req, res) => {
-    const { machine_uuid, target, latency_ms, packet_loss } = req.body;
+    const { machine_uuid } = req.body;
 
     if (!machine_uuid) {
         return res.status(400).json({ error: 'UUID faltando' });
     }
 
+    // Agentes atuais enviam o ciclo inteiro em { machine_uuid, diagnosis, probes: [...] }; os antigos, uma sonda por POST
+    const probes = Array.isArray(req.body.probes) ? req.body.probes : [req.body];
+
     try {
+        // Um evento por ciclo: o ponto do gráfico é a âncora pública, as demais sondas vão junto em "probes"
         try {
             const io = socketHandler.getIO();
-            if (io) io.emit('network_update', req.body);
+            const chartProbe = probes.find(p => p.target === NETWORK_CHART_TARGET) || probes.find(p => p.role === 'internet') || probes[0];
+            if (io && chartProbe) {
+                io.emit('network_update', { ...chartProbe, machine_uuid, diagnosis: req.body.diagnosis || chartProbe.diagnosis, probes });
+            }
         } catch (e) { console.error("Erro socket network:", e.message); }
 
-        await db.execute(
-            `INSERT INTO network_logs (machine_uuid, target, latency_ms, packet_loss) 
-             VALUES (?, ?, ?, ?)`,
-            [machine_uuid, target, latency_ms, packet_loss]
-        );
+        for (const { target, latency_ms, packet_loss } of probes) {
+            await db.execute(
+                `INSERT INTO network_logs (machine_uuid, target, latency_ms, packet_loss) 
+                 VALUES (?, ?, ?, ?)`,
+                [machine_uuid, target, latency_ms, packet_loss]
+            );
+        }
 
+        // Retenção por tempo: cada ciclo grava uma linha por alvo, então um limite de linhas encolheria com mais alvos
         await db.execute(`
             DELETE FROM network_logs 
             WHERE machine_uuid = ? 
-            AND id NOT IN (
-                SELECT id FROM (
-                    SELECT id 
-                    FROM network_logs 
-                    WHERE machine_uuid = ? 
-                    ORDER BY created_at DESC 
-                    LIMIT 20
-                ) AS keep_latest
-            )
-        `, [machine_uuid, machine_uuid]);
+            AND created_at < NOW() - INTERVAL ${NETWORK_LOG_RETENTION_MINUTES} MINUTE
+        `, [machine_uuid]);
 
         res.json({ status: 'saved_and_cleaned' });
     } catch (error) {
@@ -79,16 +88,18 @@ exports.storeNetworkLog = async (req, res) => {
     }
 };
 
+// Histórico de um alvo só (padrão: a âncora pública que o gráfico sempre mostrou); ?target= escolhe outro
 exports.getNetworkHistory = async (req, res) => {
     const { uuid } = req.params;
+    const target = req.query.target || NETWORK_CHART_TARGET;
     try {
         const [rows] = await db.execute(
             `SELECT created_at, latency_ms, packet_loss, target 
              FROM network_logs 
-             WHERE machine_uuid = ? 
+             WHERE machine_uuid = ? AND target = ? 
              ORDER BY created_at DESC 
              LIMIT 100`,
-            [uuid]
+            [uuid, target]
         );
         
         res.json(rows.reverse());
@@ -96,4 +107,96 @@ exports.getNetworkHistory = async (req, res) => {
         console.error("Erro ao buscar histórico:", error.message);
         res.status(500).json({ error: 'Erro ao buscar dados' });
     }
-};
\ No newline at end of file
+};
+
+exports.storeOutages = async (req, res) => {
+    const { machine_uuid, outages } = req.body;
+
+    if (!machine_uuid || !Array.isArray(outages)) {
+        return res.status(400).json({ error: 'UUID ou quedas faltando' });
+    }
+
+    try {
+        const stored = await agentReportService.storeOutages(machine_uuid, outages);
+        if (stored === null) return res.status(404).json({ error: 'Máquina não registrada' });
+
+        res.json({ status: 'saved', stored });
+    } catch (error) {
+        console.error("Erro ao salvar quedas:", error.message);
+        res.status(500).json({ error: 'Erro interno' });
+    }
+};
+
+exports.storeNetworkChange = async (req, res) => {
+    const event = req.body;
+
+    if (!event || !event.machine_uuid || !Array.isArray(event.changes)) {
+        return res.status(400).json({ error: 'UUID ou mudanças faltando' });
+    }
+
+    try {
+        const stored = await agentReportService.storeNetworkChange(event.machine_uuid, event);
+        if (stored === null) return res.status(404).json({ error: 'Máquina não registrada' });
+
+        try {
+            const io = socketHandler.getIO();
+            if (io) io.emit('network_change', { machine_uuid: event.machine_uuid, detected_at: event.detected_at, changes: event.changes });
+        } catch (e) { console.error("Erro socket mudança de rede:", e.message); }
+
+        res.json({ status: 'saved' });
+    } catch (error) {
+        console.error("Erro ao salvar mudança de rede:", error.message);
+        res.status(500).json({ error: 'Erro interno' });
+    }
+};
+
+exports.storeDiscovery = async (req, res) => {
+    const report = req.body;
+
+    if (!report || !report.machine_uuid || !report.subnet || !Array.isArray(report.devices)) {
+        return res.status(400).json({ error: 'UUID, sub-rede ou dispositivos faltando' });
+    }
+
+    try {
+        const stored = await agentReportService.storeDiscovery(report.machine_uuid, report);
+        if (stored === null) return res.status(404).json({ error: 'Máquina não registrada' });
+
+        try {
+            const io = socketHandler.getIO();
+            if (io) io.emit('discovery_update', { machine_uuid: report.machine_uuid, subnet: report.subnet, devices: report.devices.length });
+        } catch (e) { console.error("Erro socket descoberta:", e.message); }
+
+        res.json({ status: 'saved', stored });
+    } catch (error) {
+        console.error("Erro ao salvar descoberta de rede:", error.message);
+        res.status(500).json({ error: 'Erro interno' });
+    }
+};
+
+// Relatórios periódicos do agente: grava a versão mais recente e avisa o painel
+const storeLatestReport = (type) => async (req, res) => {
+    const report = req.body;
+
+    if (!report || !report.machine_uuid) {
+        return res.status(400).json({ error: 'UUID faltando' });
+    }
+
+    try {
+        const stored = await agentReportService.storeReport(report.machine_uuid, type, report);
+        if (stored === null) return res.status(404).json({ error: 'Máquina não registrada' });
+
+        try {
+            const io = socketHandler.getIO();
+            if (io) io.emit('machine_report', { machine_uuid: report.machine_uuid, type, report });
+        } catch (e) { console.error("Erro socket relatório:", e.message); }
+
+        res.json({ status: 'saved' });
+    } catch (error) {
+        console.error(`Erro ao salvar relatório ${type}:`, error.message);
+        res.status(500).json({ error: 'Erro interno' });
+    }
+};
+
+exports.storePatches = storeLatestReport('patches');
+exports.storeSecurityPosture = storeLatestReport('security_posture');
+exports.storeServiceChecks = storeLatestReport('service_checks');
@@ -9,6 +9,7 @@ const monitorController = require('../controllers/monitorController');
 router.post('/register', agentAuth, monitorController.registerMachine);
 router.post('/telemetry', agentAuth, monitorController.processTelemetry);
 router.post('/machines/:uuid/command-result', agentAuth, monitorController.receiveCommandResult);
+router.post('/machines/:uuid/command-state', agentAuth, monitorController.receiveCommandState);
 
 router.use(authMiddleware); 
 
@@ -16,7 +17,9 @@ router.put('/machines/:uuid/sector', monitorController.updateSector);
 router.get('/machines', monitorController.listMachines);
 router.get('/machines/:uuid', monitorController.getMachineDetails);
 router.get('/telemetry/:uuid/history', monitorController.getTelemetryHistory);
+router.get('/machines/:uuid/reports/:type', monitorController.getMachineReport);
 router.get('/topology', monitorController.getTopology);
+router.get('/discovered-devices', monitorController.listDiscoveredDevices);
 router.post('/machines/:uuid/command', monitorController.sendCommand);
 
 module.exports = router;
\ No newline at end of file
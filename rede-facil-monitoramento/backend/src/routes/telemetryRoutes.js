@@ -2,9 +2,16 @@ const express = require('express');
 const router = express.Router();
 
 const controller = require('../controllers/telemetryController'); 
+const agentAuth = require('../middleware/agentAuth');
 
 router.post('/', controller.receiveTelemetry);
 router.post('/network', controller.storeNetworkLog);
+router.post('/outages', agentAuth, controller.storeOutages);
+router.post('/network-change', agentAuth, controller.storeNetworkChange);
+router.post('/patches', agentAuth, controller.storePatches);
+router.post('/security-posture', agentAuth, controller.storeSecurityPosture);
+router.post('/service-checks', agentAuth, controller.storeServiceChecks);
+router.post('/discovery', agentAuth, controller.storeDiscovery);
 
 router.get('/network/:uuid', controller.getNetworkHistory);
 
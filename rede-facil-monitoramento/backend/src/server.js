@@ -39,7 +39,8 @@ try {
     process.exit(1); 
 }
 
-app.use(express.json());
+// Diário de quedas e descoberta de rede chegam em lotes maiores que o limite padrão de 100 KB
+app.use(express.json({ limit: '2mb' }));
 
 app.use(cors({
     origin: '*', 
@@ -0,0 +1,203 @@
+const { db, getMachineId } = require('../config/db');
+
+// Tabelas dos relatórios enviados pelo agente. São criadas na primeira gravação (como o asset_catalog),
+// assim bancos já em produção não dependem de rodar o init.sql de novo.
+const TABLES = {
+    backend_outages: `
+        CREATE TABLE IF NOT EXISTS backend_outages (
+            id BIGINT AUTO_INCREMENT PRIMARY KEY,
+            machine_id INT NOT NULL,
+            started_at DATETIME NOT NULL,
+            ended_at DATETIME NOT NULL,
+            duration_seconds INT,
+            failure_mode VARCHAR(10),
+            last_failure_mode VARCHAR(10),
+            last_error VARCHAR(255),
+            failures INT,
+            UNIQUE KEY uniq_machine_outage (machine_id, started_at),
+            FOREIGN KEY (machine_id) REFERENCES machines(id) ON DELETE CASCADE
+        )
+    `,
+    network_changes: `
+        CREATE TABLE IF NOT EXISTS network_changes (
+            id BIGINT AUTO_INCREMENT PRIMARY KEY,
+            machine_id INT NOT NULL,
+            detected_at DATETIME NOT NULL,
+            changes TEXT NOT NULL,
+            snapshot_before LONGTEXT,
+            snapshot_after LONGTEXT,
+            INDEX idx_network_changes_machine (machine_id, detected_at),
+            FOREIGN KEY (machine_id) REFERENCES machines(id) ON DELETE CASCADE
+        )
+    `,
+    discovered_devices: `
+        CREATE TABLE IF NOT EXISTS discovered_devices (
+            id BIGINT AUTO_INCREMENT PRIMARY KEY,
+            subnet VARCHAR(43) NOT NULL,
+            ip_address VARCHAR(45) NOT NULL,
+            mac_address VARCHAR(17),
+            vendor VARCHAR(100),
+            hostname VARCHAR(255),
+            responds_ping BOOLEAN DEFAULT FALSE,
+            open_ports TEXT,
+            reported_by INT,
+            first_seen TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            last_seen DATETIME NOT NULL,
+            UNIQUE KEY uniq_discovered_device (subnet, ip_address),
+            FOREIGN KEY (reported_by) REFERENCES machines(id) ON DELETE SET NULL
+        )
+    `,
+    machine_reports: `
+        CREATE TABLE IF NOT EXISTS machine_reports (
+            machine_id INT NOT NULL,
+            report_type VARCHAR(40) NOT NULL,
+            payload LONGTEXT NOT NULL,
+            collected_at DATETIME,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+            PRIMARY KEY (machine_id, report_type),
+            FOREIGN KEY (machine_id) REFERENCES machines(id) ON DELETE CASCADE
+        )
+    `,
+};
+
+const ensured = {};
+
+const ensureTable = (name) => {
+    if (!ensured[name]) {
+        ensured[name] = db.execute(TABLES[name]).catch(err => {
+            delete ensured[name];
+            throw err;
+        });
+    }
+    return ensured[name];
+};
+
+const toDate = (value) => {
+    const date = value ? new Date(value) : null;
+    return date && !isNaN(date.getTime()) ? date : null;
+};
+
+// Quedas do backend vistas pelo agente. O agente reenvia o diário se não receber a resposta,
+// então a mesma queda (máquina + início) só é gravada uma vez.
+exports.storeOutages = async (uuid, outages) => {
+    const machineId = await getMachineId(uuid);
+    if (!machineId) return null;
+    await ensureTable('backend_outages');
+
+    let stored = 0;
+    for (const o of outages) {
+        const startedAt = toDate(o.started_at);
+        const endedAt = toDate(o.ended_at);
+        if (!startedAt || !endedAt) continue;
+
+        await db.execute(`
+            INSERT INTO backend_outages
+                (machine_id, started_at, ended_at, duration_seconds, failure_mode, last_failure_mode, last_error, failures)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+            ON DUPLICATE KEY UPDATE ended_at = VALUES(ended_at), duration_seconds = VALUES(duration_seconds),
+                last_failure_mode = VALUES(last_failure_mode), last_error = VALUES(last_error), failures = VALUES(failures)
+        `, [
+            machineId, startedAt, endedAt,
+            parseInt(o.duration_seconds) || 0,
+            o.failure_mode || null,
+            o.last_failure_mode || null,
+            (o.last_error || '').substring(0, 255),
+            parseInt(o.failures) || 0
+        ]);
+        stored++;
+    }
+    return stored;
+};
+
+// Mudanças de rede detectadas pelo agente (IP, gateway, DNS, interfaces), com o estado antes e depois
+exports.storeNetworkChange = async (uuid, event) => {
+    const machineId = await getMachineId(uuid);
+    if (!machineId) return null;
+    await ensureTable('network_changes');
+
+    await db.execute(`
+        INSERT INTO network_changes (machine_id, detected_at, changes, snapshot_before, snapshot_after)
+        VALUES (?, ?, ?, ?, ?)
+    `, [
+        machineId,
+        toDate(event.detected_at) || new Date(),
+        JSON.stringify(event.changes || []),
+        JSON.stringify(event.before || null),
+        JSON.stringify(event.after || null)
+    ]);
+    return true;
+};
+
+// Dispositivos achados pela varredura de sub-rede (discover_subnet). Um registro por IP da sub-rede,
+// atualizado a cada varredura; last_seen mostra quando o dispositivo respondeu pela última vez.
+exports.storeDiscovery = async (uuid, report) => {
+    const machineId = await getMachineId(uuid);
+    if (!machineId) return null;
+    await ensureTable('discovered_devices');
+
+    const seenAt = new Date();
+    let stored = 0;
+    for (const d of report.devices || []) {
+        if (!d.ip_address) continue;
+
+        await db.execute(`
+            INSERT INTO discovered_devices
+                (subnet, ip_address, mac_address, vendor, hostname, responds_ping, open_ports, reported_by, last_seen)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+            ON DUPLICATE KEY UPDATE mac_address = COALESCE(VALUES(mac_address), mac_address),
+                vendor = COALESCE(VALUES(vendor), vendor), hostname = COALESCE(VALUES(hostname), hostname),
+                responds_ping = VALUES(responds_ping), open_ports = VALUES(open_ports),
+                reported_by = VALUES(reported_by), last_seen = VALUES(last_seen)
+        `, [
+            report.subnet,
+            d.ip_address,
+            d.mac_address || null,
+            d.vendor ? d.vendor.substring(0, 100) : null,
+            d.hostname ? d.hostname.substring(0, 255) : null,
+            !!d.responds_ping,
+            JSON.stringify(d.open_ports || []),
+            machineId,
+            seenAt
+        ]);
+        stored++;
+    }
+    return stored;
+};
+
+exports.listDiscoveredDevices = async (subnet) => {
+    await ensureTable('discovered_devices');
+
+    const [rows] = subnet
+        ? await db.execute('SELECT * FROM discovered_devices WHERE subnet = ? ORDER BY INET_ATON(ip_address)', [subnet])
+        : await db.execute('SELECT * FROM discovered_devices ORDER BY subnet, INET_ATON(ip_address)');
+    return rows.map(row => ({ ...row, open_ports: JSON.parse(row.open_ports || '[]') }));
+};
+
+// Relatórios em que só a coleta mais recente interessa (um por máquina e tipo)
+exports.REPORT_TYPES = ['patches', 'security_posture', 'service_checks'];
+
+exports.storeReport = async (uuid, type, report) => {
+    const machineId = await getMachineId(uuid);
+    if (!machineId) return null;
+    await ensureTable('machine_reports');
+
+    await db.execute(`
+        INSERT INTO machine_reports (machine_id, report_type, payload, collected_at)
+        VALUES (?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE payload = VALUES(payload), collected_at = VALUES(collected_at)
+    `, [machineId, type, JSON.stringify(report), toDate(report.collected_at) || new Date()]);
+    return true;
+};
+
+exports.getReport = async (uuid, type) => {
+    const machineId = await getMachineId(uuid);
+    if (!machineId) return null;
+    await ensureTable('machine_reports');
+
+    const [rows] = await db.execute(
+        'SELECT payload, collected_at, updated_at FROM machine_reports WHERE machine_id = ? AND report_type = ?',
+        [machineId, type]
+    );
+    if (rows.length === 0) return null;
+    return { ...JSON.parse(rows[0].payload), collected_at: rows[0].collected_at, received_at: rows[0].updated_at };
+};
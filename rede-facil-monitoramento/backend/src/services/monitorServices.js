@@ -202,14 +202,16 @@ exports.registerMachine = async (data) => {
 
 exports.processTelemetry = async (data) => {
     try {
-        const { machine_uuid, cpu_usage_percent, ram_usage_percent, disk_free_percent, temperature_celsius } = data;
+        const { machine_uuid, cpu_usage_percent, ram_usage_percent, disk_free_percent, temperature_celsius, temperature_status } = data;
 
         if (!machine_uuid) return;
 
         const cpu = parseFloat(cpu_usage_percent) || 0;
         const ram = parseFloat(ram_usage_percent) || 0;
         const diskFree = parseFloat(disk_free_percent) || 0;
-        const temp = parseFloat(temperature_celsius) || 0;
+        // Sem sensor legível o agente manda null com temperature_status "unavailable": grava NULL, não 0°C
+        const parsedTemp = parseFloat(temperature_celsius);
+        const temp = temperature_status === 'unavailable' || isNaN(parsedTemp) ? null : parsedTemp;
 
         await db.execute(`
             UPDATE machines SET 
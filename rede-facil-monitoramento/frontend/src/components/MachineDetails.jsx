@@ -16,6 +16,16 @@ import { API_URL } from '../config';
 import { changeWallpaper } from '../services/wallpaperService';
 import NetworkChart from './ui/NetworkChart';
 
+// Temperatura em °C inteiros, ou null quando o agente não conseguiu ler nenhum sensor
+const toTemperature = (value, status) => {
+    if (status === 'unavailable' || value === null || value === undefined || value === '') return null;
+    const parsed = Number(value);
+    return isNaN(parsed) ? null : Math.round(parsed);
+};
+
+// Âncora pública mostrada no gráfico de latência (o agente também sonda gateway, backend e DNS)
+const NETWORK_CHART_TARGET = '8.8.8.8';
+
 export default function MachineDetails({ machine: initialMachineData, onBack, socket }) {
   const [machine, setMachine] = useState(initialMachineData);
   const [loadingDetails, setLoadingDetails] = useState(true);
@@ -31,7 +41,7 @@ export default function MachineDetails({ machine: initialMachineData, onBack, so
 
   const [customScript, setCustomScript] = useState('');
   const [telemetryData, setTelemetryData] = useState([]);
-  const [currentTemp, setCurrentTemp] = useState(0);
+  const [currentTemp, setCurrentTemp] = useState(null);
   const [currentDiskFree, setCurrentDiskFree] = useState(0);
   const [currentSmartStatus, setCurrentSmartStatus] = useState('N/A');
   const [terminalOutput, setTerminalOutput] = useState([]);
@@ -61,7 +71,8 @@ export default function MachineDetails({ machine: initialMachineData, onBack, so
         try {
             const token = localStorage.getItem('token');
             const res = await axios.get(`${API_URL}/telemetry/network/${initialMachineData.uuid}`, {
-                headers: { Authorization: `Bearer ${token}` }
+                headers: { Authorization: `Bearer ${token}` },
+                params: { target: NETWORK_CHART_TARGET }
             });
             setNetworkHistory(res.data);
             setLoadingNetwork(false);
@@ -95,7 +106,7 @@ useEffect(() => {
                     time: new Date(log.created_at).toLocaleTimeString(), 
                     cpu: Math.round(Number(log.cpu_usage || 0)),
                     ram: Math.round(Number(log.ram_usage || 0)),
-                    temp: Math.round(Number(log.temperature || 0))
+                    temp: toTemperature(log.temperature)
                 }));
                 setTelemetryData(historyFormatted);
                 if (historyFormatted.length > 0) {
@@ -208,13 +219,13 @@ const handleStatusUpdate = (data) => {
     if (machine.last_telemetry) {
         setCurrentDiskFree(Number(machine.last_telemetry.disk_free_percent || 0));
         setCurrentSmartStatus(machine.last_telemetry.disk_smart_status || 'OK');
-        setCurrentTemp(Number(machine.last_telemetry.temperature_celsius || 0));
+        setCurrentTemp(toTemperature(machine.last_telemetry.temperature));
     }
 
     const handleNewTelemetry = (newData) => {
         if (newData.machine_uuid === machine.uuid) {
             const timeNow = new Date().toLocaleTimeString();
-            const tempValue = Math.round(Number(newData.temperature_celsius || 0));
+            const tempValue = toTemperature(newData.temperature_celsius, newData.temperature_status);
             const cpuValue = Math.round(Number(newData.cpu_usage_percent || 0));
             const ramValue = Math.round(Number(newData.ram_usage_percent || 0));
             const diskFreeValue = newData.disk_free_percent ? Number(newData.disk_free_percent) : 0;
@@ -246,10 +257,12 @@ const handleStatusUpdate = (data) => {
     };
 
     const handleNetworkUpdate = (data) => {
-        if (data.machine_uuid === machine.uuid) {
+        // O gráfico é uma série só: mesmo alvo que o histórico carregado de /telemetry/network
+        if (data.machine_uuid === machine.uuid && data.target === NETWORK_CHART_TARGET) {
             setNetworkHistory(prev => {
+                const { probes, ...point } = data;
                 const newPoint = {
-                    ...data,
+                    ...point,
                     created_at: data.created_at || new Date().toISOString()
                 };
                 return [...prev, newPoint].slice(-50);
@@ -462,7 +475,7 @@ const handleStatusUpdate = (data) => {
             </Card>
             <Card>
                 <CardHeader className="flex flex-row items-center justify-between space-y-0 pb-2"><CardTitle className="text-sm font-medium text-slate-600">Temperatura</CardTitle><Thermometer className="h-4 w-4 text-orange-500" /></CardHeader>
-                <CardContent><div className={`text-2xl font-bold ${currentTemp > 80 ? 'text-red-600' : 'text-emerald-600'}`}>{currentTemp}°C</div><p className="text-xs text-slate-500">Tempo real</p></CardContent>
+                <CardContent>{currentTemp === null ? <div className="text-2xl font-bold text-slate-400">indisponível</div> : <div className={`text-2xl font-bold ${currentTemp > 80 ? 'text-red-600' : 'text-emerald-600'}`}>{currentTemp}°C</div>}<p className="text-xs text-slate-500">Tempo real</p></CardContent>
             </Card>
             <Card className={`border-l-4 shadow-sm ${currentSmartStatus !== 'OK' || currentDiskFree < 10 ? 'border-l-red-600 bg-red-50' : 'border-l-emerald-500'}`}>
                 <CardHeader className="p-4 pb-2"><CardTitle className="text-xs font-bold text-slate-500 uppercase flex items-center gap-2"><Database className="h-3 w-3" /> Disco</CardTitle></CardHeader>